@@ -0,0 +1,237 @@
+package cli
+
+// cli.go - provides dbs command line tool
+//
+// The dbs tool is built on top of DBS client and DBS API parameters
+// definitions, i.e. every DBS API listed in static/parameters.json can be
+// queried with flags matching its parameters.
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dmwm/dbs2go/client"
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// output formats of dbs tool
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// Tool represents dbs command line tool
+type Tool struct {
+	Client *client.Client // DBS client
+	Format string         // output format: table, json or ndjson
+	Stdout io.Writer      // output writer
+}
+
+// usage of dbs tool
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: dbs [options] <command> [command options]")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  <api>        query DBS API, e.g. datasets, files, filelumis, with its parameters as flags")
+	fmt.Fprintln(w, "  apis         list DBS APIs which can be queried and their parameters")
+	fmt.Fprintln(w, "  inject       inject bulkblocks JSON file into DBS writer")
+	fmt.Fprintln(w, "  migrate      submit migration request and optionally watch its progress")
+	fmt.Fprintln(w, "  status       show status of migration requests")
+	fmt.Fprintln(w, "  invalidate   invalidate dataset or files")
+	fmt.Fprintln(w, "  compare      compare output of DBS API between two DBS instances")
+	fmt.Fprintln(w, "Options:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// Run executes dbs tool with given command line arguments
+func Run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dbs", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	rurl := fs.String("url", os.Getenv("DBS_URL"), "DBS server URL, by default DBS_URL env")
+	format := fs.String("format", FormatTable, "output format: table, json or ndjson")
+	token := fs.String("token", os.Getenv("DBS_TOKEN"), "bearer token, by default DBS_TOKEN env")
+	key := fs.String("key", "", "X509 user key, by default X509 proxy is used")
+	cert := fs.String("cert", "", "X509 user certificate")
+	timeout := fs.Int("timeout", 0, "HTTP timeout in seconds")
+	if err := fs.Parse(args); err != nil {
+		usage(stdout, fs)
+		return err
+	}
+	if fs.NArg() == 0 {
+		usage(stdout, fs)
+		return fmt.Errorf("no command is provided")
+	}
+	switch *format {
+	case FormatTable, FormatJSON, FormatNDJSON:
+	default:
+		return fmt.Errorf("unsupported output format %s", *format)
+	}
+	c := client.New(*rurl)
+	c.Token = *token
+	if *key != "" {
+		c.Key = *key
+	}
+	if *cert != "" {
+		c.Cert = *cert
+	}
+	if *timeout > 0 {
+		c.Timeout = *timeout
+	}
+	tool := &Tool{Client: c, Format: *format, Stdout: stdout}
+	cmd, cargs := fs.Arg(0), fs.Args()[1:]
+	if cmd == "apis" {
+		return tool.Apis()
+	}
+	if *rurl == "" {
+		return fmt.Errorf("DBS url is not provided, use -url option or DBS_URL env")
+	}
+	switch cmd {
+	case "inject":
+		return tool.Inject(cargs)
+	case "migrate":
+		return tool.Migrate(cargs)
+	case "status":
+		return tool.Status(cargs)
+	case "invalidate":
+		return tool.Invalidate(cargs)
+	case "compare":
+		return tool.Compare(cargs)
+	}
+	return tool.Query(cmd, cargs)
+}
+
+// apiParameters returns DBS API parameters map
+func apiParameters() (dbs.ApiParametersMap, error) {
+	if dbs.ApiParamMap != nil {
+		return dbs.ApiParamMap, nil
+	}
+	fname := dbs.ApiParametersFile
+	if fname == "" {
+		// DBS API parameters embedded into DBS static files
+		fname = "parameters.json"
+	}
+	pmap, err := dbs.LoadApiParameters(fname)
+	if err != nil {
+		return nil, err
+	}
+	dbs.ApiParamMap = pmap
+	return pmap, nil
+}
+
+// helper function to parse parameters of given DBS API, the flags of DBS
+// API are defined by its parameters
+func parseApiFlags(api string, args []string) (map[string][]string, error) {
+	pmap, err := apiParameters()
+	if err != nil {
+		return nil, err
+	}
+	params, ok := pmap[api]
+	if !ok {
+		return nil, fmt.Errorf("unknown DBS API or command '%s', see 'dbs apis'", api)
+	}
+	fs := flag.NewFlagSet(api, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	values := make(map[string]*string)
+	for _, p := range params {
+		values[p] = fs.String(p, "", fmt.Sprintf("%s parameter of %s API", p, api))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%s API: %v", api, err)
+	}
+	out := make(map[string][]string)
+	for p, v := range values {
+		if *v != "" {
+			out[p] = []string{*v}
+		}
+	}
+	return out, nil
+}
+
+// Write writes given records in tool output format
+func (t *Tool) Write(records []dbs.Record) error {
+	switch t.Format {
+	case FormatJSON:
+		if records == nil {
+			records = []dbs.Record{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(t.Stdout, string(data))
+	case FormatNDJSON:
+		for _, rec := range records {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(t.Stdout, string(data))
+		}
+	default:
+		return t.writeTable(records)
+	}
+	return nil
+}
+
+// helper function to write records as a table
+func (t *Tool) writeTable(records []dbs.Record) error {
+	keys := make(map[string]bool)
+	for _, rec := range records {
+		for k := range rec {
+			keys[k] = true
+		}
+	}
+	var columns []string
+	for k := range keys {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+	w := tabwriter.NewWriter(t.Stdout, 0, 4, 2, ' ', 0)
+	var header []string
+	for _, c := range columns {
+		header = append(header, strings.ToUpper(c))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, rec := range records {
+		var row []string
+		for _, c := range columns {
+			val := rec[c]
+			if val == nil {
+				row = append(row, "")
+				continue
+			}
+			switch v := val.(type) {
+			case string:
+				row = append(row, v)
+			case float64:
+				row = append(row, fmt.Sprintf("%v", int64(v)))
+				if v != float64(int64(v)) {
+					row[len(row)-1] = fmt.Sprintf("%v", v)
+				}
+			default:
+				data, _ := json.Marshal(v)
+				row = append(row, string(data))
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// helper function to convert arbitrary records into generic DBS records
+func toRecords(v interface{}) ([]dbs.Record, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var records []dbs.Record
+	err = json.Unmarshal(data, &records)
+	return records, err
+}
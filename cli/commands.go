@@ -0,0 +1,348 @@
+package cli
+
+// commands.go - provides commands of dbs command line tool
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmwm/dbs2go/client"
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// helper function to create flag set of given command
+func newFlagSet(cmd string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// Apis lists DBS APIs and their parameters
+func (t *Tool) Apis() error {
+	pmap, err := apiParameters()
+	if err != nil {
+		return err
+	}
+	var apis []string
+	for api := range pmap {
+		apis = append(apis, api)
+	}
+	sort.Strings(apis)
+	var records []dbs.Record
+	for _, api := range apis {
+		records = append(records, dbs.Record{
+			"api":        api,
+			"parameters": strings.Join(pmap[api], ","),
+		})
+	}
+	return t.Write(records)
+}
+
+// Query queries given DBS API with parameters provided as command flags
+func (t *Tool) Query(api string, args []string) error {
+	params, err := parseApiFlags(api, args)
+	if err != nil {
+		return err
+	}
+	if t.Format != FormatNDJSON {
+		var records []dbs.Record
+		if err := t.Client.Get(api, params, &records); err != nil {
+			return err
+		}
+		return t.Write(records)
+	}
+	// stream records as they arrive from DBS server
+	iter, err := t.Client.Stream(api, params)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for {
+		var rec dbs.Record
+		if !iter.Next(&rec) {
+			break
+		}
+		if err := t.Write([]dbs.Record{rec}); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Inject injects bulkblocks JSON file into DBS writer
+func (t *Tool) Inject(args []string) error {
+	fs := newFlagSet("inject")
+	fname := fs.String("file", "", "bulkblocks JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fname == "" {
+		return fmt.Errorf("inject: bulkblocks file is not provided")
+	}
+	data, err := os.ReadFile(*fname)
+	if err != nil {
+		return err
+	}
+	var rec dbs.BulkBlocks
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("inject: unable to decode %s, %v", *fname, err)
+	}
+	if err := t.Client.InsertBulkBlocks(rec); err != nil {
+		return err
+	}
+	return t.Write([]dbs.Record{{
+		"block_name": rec.Block.BlockName,
+		"files":      len(rec.Files),
+		"status":     "injected",
+	}})
+}
+
+// helper function to check if migration status is final, failed migration
+// requests are retried by migration server until they are terminally failed
+func finalStatus(status int64) bool {
+	switch status {
+	case dbs.COMPLETED, dbs.EXIST_IN_DB, dbs.TERM_FAILED:
+		return true
+	}
+	return false
+}
+
+// Migrate submits migration request and optionally watches its progress
+// until migration request reaches its final status
+func (t *Tool) Migrate(args []string) error {
+	fs := newFlagSet("migrate")
+	from := fs.String("from", "", "DBS url to migrate from")
+	input := fs.String("input", "", "dataset or block to migrate")
+	watch := fs.Bool("watch", false, "watch migration request until it is completed or terminally failed")
+	interval := fs.Duration("interval", 10*time.Second, "watch polling interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *input == "" {
+		return fmt.Errorf("migrate: both -from and -input options should be provided")
+	}
+	reports, err := t.Client.SubmitMigration(*from, *input)
+	if err != nil {
+		return err
+	}
+	records, err := toRecords(reports)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(records); err != nil {
+		return err
+	}
+	if !*watch {
+		return nil
+	}
+	for _, r := range reports {
+		if r.Error != nil {
+			return r.Error
+		}
+	}
+	var mids []int64
+	for _, r := range reports {
+		mid := r.MigrationRequest.MIGRATION_REQUEST_ID
+		if mid > 0 && !finalStatus(r.MigrationRequest.MIGRATION_STATUS) {
+			mids = append(mids, mid)
+		}
+	}
+	for _, mid := range mids {
+		if err := t.watchMigration(mid, *interval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper function to poll migration request until it reaches final status,
+// every status change is written to the output
+func (t *Tool) watchMigration(mid int64, interval time.Duration) error {
+	params := url.Values{"migration_request_id": []string{fmt.Sprintf("%d", mid)}}
+	last := int64(-1)
+	for {
+		requests, err := t.Client.MigrationStatus(params)
+		if err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return fmt.Errorf("migrate: migration request %d is not found", mid)
+		}
+		req := requests[0]
+		if req.MIGRATION_STATUS != last {
+			last = req.MIGRATION_STATUS
+			records, err := toRecords([]dbs.MigrationRequest{req})
+			if err != nil {
+				return err
+			}
+			if err := t.Write(records); err != nil {
+				return err
+			}
+		}
+		if finalStatus(req.MIGRATION_STATUS) {
+			if req.MIGRATION_STATUS == dbs.TERM_FAILED {
+				return fmt.Errorf("migrate: migration request %d failed", mid)
+			}
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Status shows status of migration requests
+func (t *Tool) Status(args []string) error {
+	fs := newFlagSet("status")
+	mid := fs.Int64("id", 0, "migration request id")
+	input := fs.String("input", "", "migration input, dataset or block")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	params := make(url.Values)
+	if *mid > 0 {
+		params.Set("migration_request_id", fmt.Sprintf("%d", *mid))
+	}
+	if *input != "" {
+		params.Set("migration_input", *input)
+	}
+	requests, err := t.Client.MigrationStatus(params)
+	if err != nil {
+		return err
+	}
+	records, err := toRecords(requests)
+	if err != nil {
+		return err
+	}
+	return t.Write(records)
+}
+
+// Invalidate invalidates dataset and all its files or given files
+func (t *Tool) Invalidate(args []string) error {
+	fs := newFlagSet("invalidate")
+	dataset := fs.String("dataset", "", "dataset to invalidate along with its files")
+	lfn := fs.String("lfn", "", "logical file name to invalidate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*dataset == "") == (*lfn == "") {
+		return fmt.Errorf("invalidate: either -dataset or -lfn option should be provided")
+	}
+	var records []dbs.Record
+	if *dataset != "" {
+		params := url.Values{
+			"dataset":             []string{*dataset},
+			"dataset_access_type": []string{"INVALID"},
+		}
+		if err := t.Client.UpdateDatasets(params); err != nil {
+			return err
+		}
+		params = url.Values{
+			"dataset":       []string{*dataset},
+			"is_file_valid": []string{"0"},
+		}
+		if err := t.Client.UpdateFiles(params); err != nil {
+			return err
+		}
+		records = append(records, dbs.Record{"dataset": *dataset, "status": "invalidated"})
+	} else {
+		params := url.Values{
+			"logical_file_name": []string{*lfn},
+			"is_file_valid":     []string{"0"},
+		}
+		if err := t.Client.UpdateFiles(params); err != nil {
+			return err
+		}
+		records = append(records, dbs.Record{"logical_file_name": *lfn, "status": "invalidated"})
+	}
+	return t.Write(records)
+}
+
+// Compare compares output of given DBS API between DBS instance of the tool
+// and another DBS instance. It writes records which are present only in one
+// of the instances.
+func (t *Tool) Compare(args []string) error {
+	fs := newFlagSet("compare")
+	other := fs.String("url2", "", "DBS url of instance to compare with")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *other == "" || fs.NArg() == 0 {
+		return fmt.Errorf("compare: usage compare -url2 <DBS url> <api> [api options]")
+	}
+	api := fs.Arg(0)
+	params, err := parseApiFlags(api, fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	c2 := client.New(*other)
+	c2.HTTPClient = t.Client.HTTPClient
+	c2.Token = t.Client.Token
+	c2.Key = t.Client.Key
+	c2.Cert = t.Client.Cert
+	c2.Timeout = t.Client.Timeout
+	var records1, records2 []dbs.Record
+	if err := t.Client.Get(api, params, &records1); err != nil {
+		return err
+	}
+	if err := c2.Get(api, params, &records2); err != nil {
+		return err
+	}
+	only1, only2, err := diffRecords(records1, records2)
+	if err != nil {
+		return err
+	}
+	var out []dbs.Record
+	for _, rec := range only1 {
+		out = append(out, dbs.Record{"instance": t.Client.URL, "record": rec})
+	}
+	for _, rec := range only2 {
+		out = append(out, dbs.Record{"instance": *other, "record": rec})
+	}
+	return t.Write(out)
+}
+
+// helper function to find records which are present only in one of the
+// given lists, records are compared by their JSON representation
+func diffRecords(records1, records2 []dbs.Record) ([]dbs.Record, []dbs.Record, error) {
+	keys := func(records []dbs.Record) (map[string]int, error) {
+		out := make(map[string]int)
+		for _, rec := range records {
+			// JSON encoding of maps has sorted keys
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return nil, err
+			}
+			out[string(data)]++
+		}
+		return out, nil
+	}
+	keys1, err := keys(records1)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys2, err := keys(records2)
+	if err != nil {
+		return nil, nil, err
+	}
+	// records which are not matched by records of another list
+	only := func(records []dbs.Record, other map[string]int) []dbs.Record {
+		var out []dbs.Record
+		for _, rec := range records {
+			data, _ := json.Marshal(rec)
+			key := string(data)
+			if other[key] > 0 {
+				other[key]--
+				continue
+			}
+			out = append(out, rec)
+		}
+		return out
+	}
+	return only(records1, keys2), only(records2, keys1), nil
+}
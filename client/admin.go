@@ -0,0 +1,50 @@
+package client
+
+// admin.go - provides DBS admin APIs of DBS client
+
+import (
+	"net/url"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// Delete deletes block or dataset, with dry_run it returns rows which would
+// be deleted without deleting them
+func (c *Client) Delete(rec dbs.DeleteRecord) (dbs.DeleteReport, error) {
+	var records []dbs.DeleteReport
+	if err := c.Post("delete", rec, &records); err != nil {
+		return dbs.DeleteReport{}, err
+	}
+	if len(records) == 0 {
+		return dbs.DeleteReport{}, nil
+	}
+	return records[0], nil
+}
+
+// Deletions returns deletion audit records for given parameters, e.g.
+// entity_name, entity_type or deleted_by
+func (c *Client) Deletions(params url.Values) ([]dbs.DeletionAudit, error) {
+	var records []dbs.DeletionAudit
+	err := c.Get("deletions", params, &records)
+	return records, err
+}
+
+// RenameDataset renames dataset and its blocks
+func (c *Client) RenameDataset(rec dbs.RenameRecord) (dbs.RenameReport, error) {
+	var records []dbs.RenameReport
+	if err := c.Post("renamedataset", rec, &records); err != nil {
+		return dbs.RenameReport{}, err
+	}
+	if len(records) == 0 {
+		return dbs.RenameReport{}, nil
+	}
+	return records[0], nil
+}
+
+// DatasetRenames returns old to new dataset name mappings for given
+// parameters, e.g. old_dataset or new_dataset
+func (c *Client) DatasetRenames(params url.Values) ([]dbs.DatasetRename, error) {
+	var records []dbs.DatasetRename
+	err := c.Get("datasetrenames", params, &records)
+	return records, err
+}
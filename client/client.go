@@ -0,0 +1,322 @@
+package client
+
+// client.go - provides Go client of DBS server APIs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// Client represents client of DBS server
+type Client struct {
+	URL        string        // DBS server URL, e.g. https://host/dbs/prod/global/DBSReader
+	HTTPClient *http.Client  // HTTP client, by default dbs.HttpClient with X509 certificates
+	Key        string        // X509 key used by default HTTP client
+	Cert       string        // X509 certificate used by default HTTP client
+	Timeout    int           // timeout in seconds of default HTTP client
+	Token      string        // bearer token used in Authorization HTTP header
+	Retries    int           // number of retries of failed requests
+	RetryDelay time.Duration // initial delay between retries, it is doubled on every retry
+	UserAgent  string        // User-Agent HTTP header
+}
+
+// New creates new DBS client for given DBS server URL. The client uses X509
+// proxy or user certificates (see dbs.HttpClient) unless its HTTPClient is set.
+func New(rurl string) *Client {
+	return &Client{
+		URL:        strings.TrimSuffix(rurl, "/"),
+		Key:        dbs.Ckey,
+		Cert:       dbs.Cert,
+		Timeout:    dbs.Timeout,
+		Retries:    3,
+		RetryDelay: time.Second,
+		UserAgent:  "dbs2go-client",
+	}
+}
+
+// Error represents error returned by DBS server
+type Error struct {
+	HTTPCode   int                    // HTTP status code
+	DBSError   *dbs.DBSError          // DBS error returned by the server
+	Violations []dbs.PayloadViolation // lexicon violations of POST/PUT payload
+	Message    string                 // error message or body of non DBS response
+}
+
+// Error function implements error interface
+func (e *Error) Error() string {
+	if e.DBSError != nil {
+		return fmt.Sprintf("HTTP %d: %s", e.HTTPCode, e.DBSError.Error())
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.HTTPCode, e.Message)
+}
+
+// Unwrap returns DBS error returned by the server
+func (e *Error) Unwrap() error {
+	if e.DBSError == nil {
+		return nil
+	}
+	return e.DBSError
+}
+
+// helper function to decode error response of DBS server
+func decodeError(code int, data []byte) error {
+	e := &Error{HTTPCode: code, Message: strings.TrimSpace(string(data))}
+	var records []struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(data, &records); err != nil || len(records) == 0 {
+		return e
+	}
+	e.Message = records[0].Message
+	if len(records[0].Error) > 0 {
+		var derr struct {
+			dbs.DBSError
+			Violations []dbs.PayloadViolation `json:"violations"`
+		}
+		if err := json.Unmarshal(records[0].Error, &derr); err == nil {
+			e.DBSError = &derr.DBSError
+			e.Violations = derr.Violations
+		}
+	}
+	return e
+}
+
+// helper function to check if request should be retried for given status
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// helper function to get HTTP client
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return dbs.HttpClient(c.Key, c.Cert, c.Timeout)
+}
+
+// Do performs HTTP request with given method, URL and body and returns HTTP
+// response with 200 status. The request is retried on network errors and
+// on HTTP statuses which indicate temporary server unavailability. The
+// error responses are decoded into Error type.
+func (c *Client) Do(method, rurl string, body []byte, accept string) (*http.Response, error) {
+	var err error
+	delay := c.RetryDelay
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, e := http.NewRequest(method, rurl, reader)
+		if e != nil {
+			return nil, dbs.Error(e, dbs.HttpRequestErrorCode, "", "client.Do")
+		}
+		req.Header.Set("Accept", accept)
+		if body != nil || method != "GET" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+		resp, e := c.httpClient().Do(req)
+		if e != nil {
+			err = dbs.Error(e, dbs.HttpRequestErrorCode, "", "client.Do")
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		err = decodeError(resp.StatusCode, data)
+		if !retryStatus(resp.StatusCode) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// Fetch performs HTTP GET request to given URL and returns its data
+func (c *Client) Fetch(rurl string) ([]byte, error) {
+	resp, err := c.Do("GET", rurl, nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, dbs.Error(err, dbs.ReaderErrorCode, "", "client.Fetch")
+	}
+	return data, nil
+}
+
+// helper function to construct URL of given API
+func (c *Client) apiURL(api string, params url.Values) string {
+	rurl := fmt.Sprintf("%s/%s", c.URL, api)
+	if len(params) > 0 {
+		rurl = fmt.Sprintf("%s?%s", rurl, params.Encode())
+	}
+	return rurl
+}
+
+// Get performs GET request to given DBS API and decodes its JSON output
+// into out argument
+func (c *Client) Get(api string, params url.Values, out interface{}) error {
+	data, err := c.Fetch(c.apiURL(api, params))
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		msg := fmt.Sprintf("unable to decode %s API output", api)
+		return dbs.Error(err, dbs.UnmarshalErrorCode, msg, "client.Get")
+	}
+	return nil
+}
+
+// helper function to send payload to given API and decode its output
+func (c *Client) send(method, api string, params url.Values, payload, out interface{}) error {
+	var body []byte
+	if payload != nil {
+		data, err := encodePayload(payload)
+		if err != nil {
+			return err
+		}
+		body = data
+	}
+	resp, err := c.Do(method, c.apiURL(api, params), body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return dbs.Error(err, dbs.ReaderErrorCode, "", "client.send")
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		msg := fmt.Sprintf("unable to decode %s API output", api)
+		return dbs.Error(err, dbs.UnmarshalErrorCode, msg, "client.send")
+	}
+	return nil
+}
+
+// Post performs POST request to given DBS API with given payload and
+// decodes its JSON output into out argument
+func (c *Client) Post(api string, payload, out interface{}) error {
+	return c.send("POST", api, nil, payload, out)
+}
+
+// Put performs PUT request to given DBS API with given parameters
+func (c *Client) Put(api string, params url.Values, payload interface{}) error {
+	if payload == nil {
+		// DBS server expects JSON payload in PUT requests
+		payload = dbs.Record{}
+	}
+	return c.send("PUT", api, params, payload, nil)
+}
+
+// helper function to encode payload of writer APIs. The DBS record
+// attributes which are assigned by DBS server, e.g. create_by, are removed
+// from the payload if they are not set.
+func encodePayload(payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, dbs.Error(err, dbs.MarshalErrorCode, "unable to encode payload", "client.encodePayload")
+	}
+	var rec interface{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, dbs.Error(err, dbs.UnmarshalErrorCode, "unable to decode payload", "client.encodePayload")
+	}
+	stripServerAttributes(rec)
+	data, err = json.Marshal(rec)
+	if err != nil {
+		return nil, dbs.Error(err, dbs.MarshalErrorCode, "unable to encode payload", "client.encodePayload")
+	}
+	return data, nil
+}
+
+// helper function to remove unset server attributes from decoded payload
+func stripServerAttributes(rec interface{}) {
+	switch v := rec.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			for _, attr := range dbs.ServerAttributes {
+				if key == attr && (val == nil || val == "" || val == float64(0)) {
+					delete(v, key)
+				}
+			}
+			stripServerAttributes(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			stripServerAttributes(val)
+		}
+	}
+}
+
+// Iterator iterates over records of DBS API streamed in ndjson data-format
+type Iterator struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	err     error
+}
+
+// Stream performs GET request to given DBS API and returns iterator over
+// its records streamed by DBS server in ndjson data-format
+func (c *Client) Stream(api string, params url.Values) (*Iterator, error) {
+	resp, err := c.Do("GET", c.apiURL(api, params), nil, "application/ndjson")
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{body: resp.Body, decoder: json.NewDecoder(resp.Body)}, nil
+}
+
+// Next decodes next record into given argument, it returns false when
+// there are no more records or decoding fails
+func (it *Iterator) Next(rec interface{}) bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.decoder.Decode(rec); err != nil {
+		if err != io.EOF {
+			it.err = dbs.Error(err, dbs.UnmarshalErrorCode, "unable to decode record", "client.Iterator.Next")
+		}
+		return false
+	}
+	return true
+}
+
+// Err returns error occurred during iteration
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close closes underlying HTTP response
+func (it *Iterator) Close() error {
+	return it.body.Close()
+}
@@ -0,0 +1,100 @@
+package client
+
+// migration.go - provides DBS migration APIs of DBS client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// DBS client is used by migration code to access remote DBS servers
+var _ dbs.RemoteDBS = (*Client)(nil)
+
+// migrationReport represents migration report in a form returned by
+// DBS server, i.e. with error encoded as JSON object
+type migrationReport struct {
+	MigrationRequest dbs.MigrationRequest `json:"migration_details"`
+	Report           string               `json:"migration_report"`
+	Status           string               `json:"status"`
+	Error            *dbs.DBSError        `json:"error"`
+}
+
+// helper function to convert migration reports returned by DBS server
+func migrationReports(records []migrationReport) []dbs.MigrationReport {
+	var reports []dbs.MigrationReport
+	for _, r := range records {
+		rec := dbs.MigrationReport{
+			MigrationRequest: r.MigrationRequest,
+			Report:           r.Report,
+			Status:           r.Status,
+		}
+		// errors which are not DBS errors are encoded as empty JSON object
+		if r.Error != nil && (r.Error.Code != 0 || r.Error.Reason != "" || r.Error.Message != "") {
+			rec.Error = r.Error
+		}
+		reports = append(reports, rec)
+	}
+	return reports
+}
+
+// helper function to post migration request and decode its reports
+func (c *Client) migrationRequest(api string, payload interface{}) ([]dbs.MigrationReport, error) {
+	var data json.RawMessage
+	if err := c.Post(api, payload, &data); err != nil {
+		return nil, err
+	}
+	var records []migrationReport
+	if err := json.Unmarshal(data, &records); err != nil {
+		msg := fmt.Sprintf("unable to decode %s API output", api)
+		return nil, dbs.Error(err, dbs.UnmarshalErrorCode, msg, "client.migrationRequest")
+	}
+	return migrationReports(records), nil
+}
+
+// SubmitMigration submits migration request of given input (dataset or
+// block) from remote DBS url
+func (c *Client) SubmitMigration(rurl, input string) ([]dbs.MigrationReport, error) {
+	rec := dbs.Record{"migration_url": rurl, "migration_input": input}
+	return c.migrationRequest("submit", rec)
+}
+
+// ProcessMigration processes migration request with given id
+func (c *Client) ProcessMigration(mid int64) ([]dbs.MigrationReport, error) {
+	rec := dbs.Record{"migration_request_id": mid}
+	return c.migrationRequest("process", rec)
+}
+
+// CancelMigration cancels migration request with given id
+func (c *Client) CancelMigration(mid int64) error {
+	return c.Post("cancel", dbs.MigrationRemoveRequest{MIGRATION_REQUEST_ID: mid}, nil)
+}
+
+// RemoveMigration removes migration request with given id
+func (c *Client) RemoveMigration(mid int64) error {
+	return c.Post("remove", dbs.MigrationRemoveRequest{MIGRATION_REQUEST_ID: mid}, nil)
+}
+
+// MigrationStatus returns migration requests for given parameters, e.g.
+// migration_request_id, migration_input or migration_status
+func (c *Client) MigrationStatus(params url.Values) ([]dbs.MigrationRequest, error) {
+	var records []dbs.MigrationRequest
+	err := c.Get("status", params, &records)
+	return records, err
+}
+
+// TotalMigration returns total number of migration requests
+func (c *Client) TotalMigration() ([]dbs.Record, error) {
+	return c.records("total", nil)
+}
+
+// PlanMigration returns migration plan of given input (dataset or block)
+// from remote DBS url
+func (c *Client) PlanMigration(rurl, input string) ([]dbs.MigrationPlan, error) {
+	var records []dbs.MigrationPlan
+	rec := dbs.Record{"migration_url": rurl, "migration_input": input}
+	err := c.Post("plan", rec, &records)
+	return records, err
+}
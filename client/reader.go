@@ -0,0 +1,294 @@
+package client
+
+// reader.go - provides DBS reader APIs of DBS client
+
+import (
+	"net/url"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// helper function to fetch generic records of given DBS API
+func (c *Client) records(api string, params url.Values) ([]dbs.Record, error) {
+	var records []dbs.Record
+	err := c.Get(api, params, &records)
+	return records, err
+}
+
+// Datasets returns list of datasets for given parameters
+func (c *Client) Datasets(params url.Values) ([]dbs.DatasetRecord, error) {
+	var records []dbs.DatasetRecord
+	err := c.Get("datasets", params, &records)
+	return records, err
+}
+
+// DatasetIterator returns iterator over datasets records of given parameters
+func (c *Client) DatasetIterator(params url.Values) (*Iterator, error) {
+	return c.Stream("datasets", params)
+}
+
+// Files returns list of files for given parameters
+func (c *Client) Files(params url.Values) ([]dbs.FileRecord, error) {
+	var records []dbs.FileRecord
+	err := c.Get("files", params, &records)
+	return records, err
+}
+
+// FileIterator returns iterator over file records of given parameters
+func (c *Client) FileIterator(params url.Values) (*Iterator, error) {
+	return c.Stream("files", params)
+}
+
+// BlockDump returns block dump of given block, the block dump record has
+// the same structure as bulkblocks API input
+func (c *Client) BlockDump(blk string) (dbs.BulkBlocks, error) {
+	var rec dbs.BulkBlocks
+	params := url.Values{"block_name": []string{blk}}
+	err := c.Get("blockdump", params, &rec)
+	return rec, err
+}
+
+// helper function to build blockdump parameters of given block
+func blockDumpParams(blk string, params url.Values) url.Values {
+	out := url.Values{"block_name": []string{blk}}
+	for k, v := range params {
+		if k != "block_name" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// PartialBlockDump returns block dump of given block restricted to sections
+// selected by include or exclude parameters, see dbs.BlockDumpSections
+func (c *Client) PartialBlockDump(blk string, params url.Values) (dbs.Record, error) {
+	var rec dbs.Record
+	err := c.Get("blockdump", blockDumpParams(blk, params), &rec)
+	return rec, err
+}
+
+// BlockDumpIterator returns block dump record of given block without its
+// files along with iterator over dbs.File records of the block, the files
+// are streamed by the server one by one
+func (c *Client) BlockDumpIterator(blk string, params url.Values) (dbs.Record, *Iterator, error) {
+	params = blockDumpParams(blk, params)
+	params.Set("stream", "files")
+	it, err := c.Stream("blockdump", params)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rec dbs.Record
+	if !it.Next(&rec) {
+		it.Close()
+		err := it.Err()
+		if err == nil {
+			err = dbs.Error(dbs.RecordErr, dbs.ReaderErrorCode, "empty block dump", "client.BlockDumpIterator")
+		}
+		return nil, nil, err
+	}
+	return rec, it, nil
+}
+
+// Blocks returns list of blocks for given parameters
+func (c *Client) Blocks(params url.Values) ([]dbs.Record, error) {
+	return c.records("blocks", params)
+}
+
+// BlockIterator returns iterator over block records of given parameters
+func (c *Client) BlockIterator(params url.Values) (*Iterator, error) {
+	return c.Stream("blocks", params)
+}
+
+// BlockTrio returns list of block trio records for given parameters
+func (c *Client) BlockTrio(params url.Values) ([]dbs.Record, error) {
+	return c.records("blockTrio", params)
+}
+
+// BlockChildren returns list of block children for given parameters
+func (c *Client) BlockChildren(params url.Values) ([]dbs.Record, error) {
+	return c.records("blockchildren", params)
+}
+
+// BlockParents returns list of block parents for given parameters
+func (c *Client) BlockParents(params url.Values) ([]dbs.Record, error) {
+	return c.records("blockparents", params)
+}
+
+// BlockOrigin returns list of block origin records for given parameters
+func (c *Client) BlockOrigin(params url.Values) ([]dbs.Record, error) {
+	return c.records("blockorigin", params)
+}
+
+// BlockSummaries returns list of block summaries for given parameters
+func (c *Client) BlockSummaries(params url.Values) ([]dbs.Record, error) {
+	return c.records("blocksummaries", params)
+}
+
+// FileLumis returns list of file lumis for given parameters
+func (c *Client) FileLumis(params url.Values) ([]dbs.Record, error) {
+	return c.records("filelumis", params)
+}
+
+// FileLumiIterator returns iterator over file lumi records of given parameters
+func (c *Client) FileLumiIterator(params url.Values) (*Iterator, error) {
+	return c.Stream("filelumis", params)
+}
+
+// FileChildren returns list of file children for given parameters
+func (c *Client) FileChildren(params url.Values) ([]dbs.Record, error) {
+	return c.records("filechildren", params)
+}
+
+// FileParents returns list of file parents for given parameters
+func (c *Client) FileParents(params url.Values) ([]dbs.Record, error) {
+	return c.records("fileparents", params)
+}
+
+// FileSummaries returns list of file summaries for given parameters
+func (c *Client) FileSummaries(params url.Values) ([]dbs.Record, error) {
+	return c.records("filesummaries", params)
+}
+
+// DatasetChildren returns list of dataset children for given parameters
+func (c *Client) DatasetChildren(params url.Values) ([]dbs.Record, error) {
+	return c.records("datasetchildren", params)
+}
+
+// DatasetParents returns list of dataset parents for given parameters
+func (c *Client) DatasetParents(params url.Values) ([]dbs.Record, error) {
+	return c.records("datasetparents", params)
+}
+
+// DatasetStats returns list of dataset statistics for given parameters
+func (c *Client) DatasetStats(params url.Values) ([]dbs.Record, error) {
+	return c.records("datasetstats", params)
+}
+
+// DatasetAccessTypes returns list of dataset access types
+func (c *Client) DatasetAccessTypes(params url.Values) ([]dbs.Record, error) {
+	return c.records("datasetaccesstypes", params)
+}
+
+// ParentDSTrio returns list of parent dataset trio records for given parameters
+func (c *Client) ParentDSTrio(params url.Values) ([]dbs.Record, error) {
+	return c.records("parentDSTrio", params)
+}
+
+// ParentageAudit returns parentage audit records for given parameters
+func (c *Client) ParentageAudit(params url.Values) ([]dbs.Record, error) {
+	return c.records("parentageaudit", params)
+}
+
+// StaleBlocks returns list of stale blocks for given parameters
+func (c *Client) StaleBlocks(params url.Values) ([]dbs.Record, error) {
+	return c.records("staleblocks", params)
+}
+
+// PrimaryDatasets returns list of primary datasets for given parameters
+func (c *Client) PrimaryDatasets(params url.Values) ([]dbs.Record, error) {
+	return c.records("primarydatasets", params)
+}
+
+// PrimaryDSTypes returns list of primary dataset types for given parameters
+func (c *Client) PrimaryDSTypes(params url.Values) ([]dbs.Record, error) {
+	return c.records("primarydstypes", params)
+}
+
+// DataTiers returns list of data tiers for given parameters
+func (c *Client) DataTiers(params url.Values) ([]dbs.Record, error) {
+	return c.records("datatiers", params)
+}
+
+// DataTypes returns list of data types for given parameters
+func (c *Client) DataTypes(params url.Values) ([]dbs.Record, error) {
+	return c.records("datatypes", params)
+}
+
+// AcquisitionEras returns list of acquisition eras for given parameters
+func (c *Client) AcquisitionEras(params url.Values) ([]dbs.Record, error) {
+	return c.records("acquisitioneras", params)
+}
+
+// AcquisitionErasCi returns list of acquisition eras matched case-insensitive
+func (c *Client) AcquisitionErasCi(params url.Values) ([]dbs.Record, error) {
+	return c.records("acquisitioneras_ci", params)
+}
+
+// ProcessingEras returns list of processing eras for given parameters
+func (c *Client) ProcessingEras(params url.Values) ([]dbs.Record, error) {
+	return c.records("processingeras", params)
+}
+
+// PhysicsGroups returns list of physics groups for given parameters
+func (c *Client) PhysicsGroups(params url.Values) ([]dbs.Record, error) {
+	return c.records("physicsgroups", params)
+}
+
+// ReleaseVersions returns list of release versions for given parameters
+func (c *Client) ReleaseVersions(params url.Values) ([]dbs.Record, error) {
+	return c.records("releaseversions", params)
+}
+
+// OutputConfigs returns list of output configs for given parameters
+func (c *Client) OutputConfigs(params url.Values) ([]dbs.Record, error) {
+	return c.records("outputconfigs", params)
+}
+
+// Runs returns list of runs for given parameters
+func (c *Client) Runs(params url.Values) ([]dbs.Record, error) {
+	return c.records("runs", params)
+}
+
+// RunSummaries returns list of run summaries for given parameters
+func (c *Client) RunSummaries(params url.Values) ([]dbs.Record, error) {
+	return c.records("runsummaries", params)
+}
+
+// Tags returns list of dataset or block tags for given parameters, e.g.
+// dataset, block_name or tag_key
+func (c *Client) Tags(params url.Values) ([]dbs.Record, error) {
+	return c.records("tags", params)
+}
+
+// DBStats returns database statistics of DBS server
+func (c *Client) DBStats() ([]dbs.Record, error) {
+	return c.records("dbstats", nil)
+}
+
+// FileArray returns list of files for given JSON record, see fileArray API
+func (c *Client) FileArray(rec dbs.Record) ([]dbs.FileRecord, error) {
+	var records []dbs.FileRecord
+	err := c.Post("fileArray", rec, &records)
+	return records, err
+}
+
+// DatasetList returns list of datasets for given JSON record, see
+// datasetlist API
+func (c *Client) DatasetList(rec dbs.Record) ([]dbs.DatasetRecord, error) {
+	var records []dbs.DatasetRecord
+	err := c.Post("datasetlist", rec, &records)
+	return records, err
+}
+
+// FileLumiList returns list of file lumis for given JSON record, see POST
+// filelumis API
+func (c *Client) FileLumiList(rec dbs.Record) ([]dbs.Record, error) {
+	var records []dbs.Record
+	err := c.Post("filelumis", rec, &records)
+	return records, err
+}
+
+// BlockParentList returns list of block parents for given JSON record, see
+// POST blockparents API
+func (c *Client) BlockParentList(rec dbs.Record) ([]dbs.Record, error) {
+	var records []dbs.Record
+	err := c.Post("blockparents", rec, &records)
+	return records, err
+}
+
+// FileParentsByLumi returns list of file parents for given JSON record
+func (c *Client) FileParentsByLumi(rec dbs.Record) ([]dbs.Record, error) {
+	var records []dbs.Record
+	err := c.Post("fileparentsbylumi", rec, &records)
+	return records, err
+}
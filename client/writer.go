@@ -0,0 +1,125 @@
+package client
+
+// writer.go - provides DBS writer APIs of DBS client
+
+import (
+	"net/url"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// InsertDataTiers inserts data tier record
+func (c *Client) InsertDataTiers(rec dbs.DataTiers) error {
+	return c.Post("datatiers", rec, nil)
+}
+
+// InsertDatasetAccessTypes inserts dataset access type record
+func (c *Client) InsertDatasetAccessTypes(rec dbs.DatasetAccessTypes) error {
+	return c.Post("datasetaccesstypes", rec, nil)
+}
+
+// InsertPhysicsGroups inserts physics group record
+func (c *Client) InsertPhysicsGroups(rec dbs.PhysicsGroups) error {
+	return c.Post("physicsgroups", rec, nil)
+}
+
+// InsertPrimaryDatasets inserts primary dataset record
+func (c *Client) InsertPrimaryDatasets(rec dbs.PrimaryDatasetRecord) error {
+	return c.Post("primarydatasets", rec, nil)
+}
+
+// InsertAcquisitionEras inserts acquisition era record
+func (c *Client) InsertAcquisitionEras(rec dbs.AcquisitionEras) error {
+	return c.Post("acquisitioneras", rec, nil)
+}
+
+// InsertProcessingEras inserts processing era record
+func (c *Client) InsertProcessingEras(rec dbs.ProcessingEras) error {
+	return c.Post("processingeras", rec, nil)
+}
+
+// InsertOutputConfigs inserts output config record
+func (c *Client) InsertOutputConfigs(rec dbs.OutputConfigRecord) error {
+	return c.Post("outputconfigs", rec, nil)
+}
+
+// InsertDatasets inserts dataset record
+func (c *Client) InsertDatasets(rec dbs.DatasetRecord) error {
+	return c.Post("datasets", rec, nil)
+}
+
+// InsertBlocks inserts block record
+func (c *Client) InsertBlocks(rec dbs.BlockRecord) error {
+	return c.Post("blocks", rec, nil)
+}
+
+// InsertBulkBlocks inserts block with all its files, lumis and parentage
+// information
+func (c *Client) InsertBulkBlocks(rec dbs.BulkBlocks) error {
+	return c.Post("bulkblocks", rec, nil)
+}
+
+// InsertFiles inserts file records
+func (c *Client) InsertFiles(records []dbs.FileRecord) error {
+	return c.Post("files", dbs.PyFileRecord{Records: records}, nil)
+}
+
+// InsertFileParents inserts file parents of given block
+func (c *Client) InsertFileParents(rec dbs.FileParentBlockRecord) error {
+	return c.Post("fileparents", rec, nil)
+}
+
+// InsertFileParentsByLumi derives file parentage of given block from its
+// parent dataset, with dry_run it returns proposed file parentage
+func (c *Client) InsertFileParentsByLumi(rec dbs.FileParentsByLumiRecord) ([]dbs.Record, error) {
+	var records []dbs.Record
+	err := c.Post("insertfileparentsbylumi", rec, &records)
+	return records, err
+}
+
+// UpdateDatasets updates dataset access type or physics group of dataset
+func (c *Client) UpdateDatasets(params url.Values) error {
+	return c.Put("datasets", params, nil)
+}
+
+// UpdateBlocks updates open_for_writing status or origin site of block
+func (c *Client) UpdateBlocks(params url.Values) error {
+	return c.Put("blocks", params, nil)
+}
+
+// UpdateFiles updates validity of file or all files of dataset
+func (c *Client) UpdateFiles(params url.Values) error {
+	return c.Put("files", params, nil)
+}
+
+// UpdateAcquisitionEras updates end date of acquisition era
+func (c *Client) UpdateAcquisitionEras(params url.Values) error {
+	return c.Put("acquisitioneras", params, nil)
+}
+
+// UpdateFileValidity updates validity of list of files, files of a block
+// or files of run/lumi selection within block or dataset, and returns
+// report of the update
+func (c *Client) UpdateFileValidity(rec dbs.Record) (dbs.FileValidityReport, error) {
+	var records []dbs.FileValidityReport
+	if err := c.send("PUT", "files", nil, rec, &records); err != nil {
+		return dbs.FileValidityReport{}, err
+	}
+	if len(records) == 0 {
+		return dbs.FileValidityReport{}, nil
+	}
+	return records[0], nil
+}
+
+// UpdateTags sets and removes tags of dataset or block and returns all
+// tags the dataset or block has after the update
+func (c *Client) UpdateTags(rec dbs.TagRecord) (dbs.TagRecord, error) {
+	var records []dbs.TagRecord
+	if err := c.Post("tags", rec, &records); err != nil {
+		return dbs.TagRecord{}, err
+	}
+	if len(records) == 0 {
+		return dbs.TagRecord{}, nil
+	}
+	return records[0], nil
+}
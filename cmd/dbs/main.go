@@ -0,0 +1,19 @@
+// dbs - DBS command line tool
+//
+// The tool queries and updates DBS servers using DBS Go client, see
+// docs/Client.md for details.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dmwm/dbs2go/cli"
+)
+
+func main() {
+	if err := cli.Run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "dbs:", err)
+		os.Exit(1)
+	}
+}
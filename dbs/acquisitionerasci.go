@@ -31,7 +31,7 @@ func (a *API) AcquisitionErasCi() error {
 		return Error(err, SessionErrorCode, "ORACLE session error", "dbs.acquisitionerasci.AcquisitionErasCi")
 	}
 
-	e := executeAll(a.Writer, a.Separator, stm, args...)
+	e := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err := executeSessions(tx, postSession); err != nil {
 		return Error(err, SessionErrorCode, "ORACLE session error", "dbs.acquisitionerasci.AcquisitionErasCi")
 	}
@@ -0,0 +1,198 @@
+package dbs
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// BlockCloseInterval defines block close server interval in seconds
+var BlockCloseInterval int
+
+// BlockIdleHours defines number of hours after which idle open block is closed
+var BlockIdleHours int
+
+// BlockMaxSize defines block size after which open block is closed, 0 disables the check
+var BlockMaxSize int64
+
+// BlockMaxFiles defines number of files after which open block is closed, 0 disables the check
+var BlockMaxFiles int64
+
+// StaleBlock represents open block selected by block lifecycle policy
+type StaleBlock struct {
+	BlockID   int64
+	BlockName string
+}
+
+// BlockCloseServer represents block close daemon which periodically closes
+// open blocks according to block lifecycle policy
+func BlockCloseServer(interval int, ch <-chan bool) {
+	log.Println("Start block close server")
+	api := API{Api: "CloseStaleBlocks", CreateBy: "DBS-workflow"}
+
+	lastCall := time.Now()
+	for {
+		select {
+		case v := <-ch:
+			if v == true {
+				log.Println("Received notification to stop block close server")
+				return
+			}
+		default:
+			time.Sleep(time.Duration(1) * time.Second)
+			if time.Since(lastCall).Seconds() < float64(interval) {
+				continue // we did not exceed our interval since last call
+			}
+			if utils.VERBOSE > 0 {
+				log.Println("call CloseStaleBlocks")
+			}
+			err := api.CloseStaleBlocks(BlockIdleHours, BlockMaxSize, BlockMaxFiles)
+			if err != nil {
+				log.Println("fail to close stale blocks", err)
+			}
+			lastCall = time.Now() // update last call time stamp
+		}
+	}
+}
+
+// helper function to load stale open blocks statement and its arguments
+func staleBlocksStatement(idleHours int, maxSize, maxFiles int64, dataset bool) (string, []interface{}, error) {
+	var args []interface{}
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+	tmpl["Dataset"] = dataset
+	tmpl["MaxSize"] = maxSize > 0
+	tmpl["MaxFiles"] = maxFiles > 0
+	stm, err := LoadTemplateSQL("stale_open_blocks", tmpl)
+	if err != nil {
+		return "", args, Error(err, LoadErrorCode, "unable to load stale_open_blocks template", "dbs.block_lifecycle.staleBlocksStatement")
+	}
+	// stale_open_blocks template contains two idle_date bindings
+	idleDate := time.Now().Unix() - int64(idleHours)*3600
+	args = append(args, idleDate)
+	args = append(args, idleDate)
+	if maxSize > 0 {
+		args = append(args, maxSize)
+	}
+	if maxFiles > 0 {
+		args = append(args, maxFiles)
+	}
+	return stm, args, nil
+}
+
+// StaleBlocks DBS API provides list of open blocks which were not modified
+// for given number of hours
+func (a *API) StaleBlocks() error {
+	idleHours := BlockIdleHours
+	if val, err := getSingleValue(a.Params, "idle_hours"); err == nil {
+		hours, err := strconv.Atoi(val)
+		if err != nil || hours < 0 {
+			msg := fmt.Sprintf("invalid idle_hours value '%s'", val)
+			return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.block_lifecycle.StaleBlocks")
+		}
+		idleHours = hours
+	}
+	var conds []string
+	_, e := getSingleValue(a.Params, "dataset")
+	stm, args, err := staleBlocksStatement(idleHours, 0, 0, e == nil)
+	if err != nil {
+		return err
+	}
+	conds, args = AddParam("dataset", "DS.DATASET", a.Params, conds, args)
+	conds, args = AddParam("block_name", "B.BLOCK_NAME", a.Params, conds, args)
+	for _, cond := range conds {
+		stm = fmt.Sprintf("%s AND %s", stm, cond)
+	}
+
+	// use generic query API to fetch the results from DB
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "unable to query stale blocks", "dbs.block_lifecycle.StaleBlocks")
+	}
+	return nil
+}
+
+// CloseStaleBlocks closes open blocks which were idle for given number of hours
+// or reached given size or number of files. Before closing, block statistics
+// are recomputed from its valid files.
+func (a *API) CloseStaleBlocks(idleHours int, maxSize, maxFiles int64) error {
+	stm, args, err := staleBlocksStatement(idleHours, maxSize, maxFiles, false)
+	if err != nil {
+		return err
+	}
+	stm = CleanStatement(stm)
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	rows, err := DB.Query(stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "unable to query stale blocks", "dbs.block_lifecycle.CloseStaleBlocks")
+	}
+	var blocks []StaleBlock
+	for rows.Next() {
+		var b StaleBlock
+		var bsize, fcount, cdate, mdate sql.NullInt64
+		var site, cby, mby sql.NullString
+		err = rows.Scan(&b.BlockID, &b.BlockName, &bsize, &fcount, &site, &cdate, &cby, &mdate, &mby)
+		if err != nil {
+			rows.Close()
+			return Error(err, RowsScanErrorCode, "unable to scan stale blocks", "dbs.block_lifecycle.CloseStaleBlocks")
+		}
+		blocks = append(blocks, b)
+	}
+	rows.Close()
+
+	// close each block in its own transaction such that failure of one block
+	// does not prevent closing others
+	for _, b := range blocks {
+		closed, err := a.closeBlock(b.BlockID, b.BlockName)
+		if err != nil {
+			log.Printf("unable to close block %s, error %v", b.BlockName, err)
+			continue
+		}
+		if closed {
+			log.Printf("closed block %s", b.BlockName)
+		}
+	}
+	return nil
+}
+
+// helper function to recompute statistics of given block and close it, the
+// block is left untouched if it was closed since it was selected. It
+// returns true if block is closed by this call.
+func (a *API) closeBlock(blockID int64, blockName string) (bool, error) {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	tmplData["Site"] = false
+	tmplData["OpenOnly"] = true
+	stm, err := LoadTemplateSQL("update_blocks", tmplData)
+	if err != nil {
+		return false, Error(err, LoadErrorCode, "unable to load update blocks template", "dbs.block_lifecycle.closeBlock")
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, Error(err, TransactionErrorCode, "unable to start transaction", "dbs.block_lifecycle.closeBlock")
+	}
+	defer tx.Rollback()
+	err = a.UpdateBlockStats(tx, blockID)
+	if err != nil {
+		return false, err
+	}
+	res, err := tx.Exec(stm, 0, a.CreateBy, time.Now().Unix(), blockName)
+	if err != nil {
+		return false, Error(err, UpdateBlockErrorCode, "unable to close block", "dbs.block_lifecycle.closeBlock")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// block is already closed, keep its frozen statistics
+		return false, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, Error(err, CommitErrorCode, "unable to commit block close transaction", "dbs.block_lifecycle.closeBlock")
+	}
+	return true, nil
+}
@@ -3,6 +3,7 @@ package dbs
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"strings"
@@ -176,8 +177,20 @@ func getAcquisitionEra(blk string, wg *sync.WaitGroup, acquisitionEra *Acquisiti
 type FileList []File
 
 // helper function to get file list information
-func getFileList(blk string, wg *sync.WaitGroup, files *FileList) {
+func getFileList(blk string, lumis bool, wg *sync.WaitGroup, files *FileList) {
 	defer wg.Done()
+	err := scanFiles(blk, lumis, func(file File) error {
+		*files = append(*files, file)
+		return nil
+	})
+	if err != nil {
+		log.Printf("unable to get files of block %s, error %v", blk, err)
+	}
+}
+
+// helper function to scan files of given block, every file along with its
+// lumis (if requested) is passed to given function as soon as it is read
+func scanFiles(blk string, lumis bool, fn func(File) error) error {
 	var args []interface{}
 	args = append(args, blk)
 	stm := getSQL("blockdump_files")
@@ -189,7 +202,7 @@ func getFileList(blk string, wg *sync.WaitGroup, files *FileList) {
 	rows, err := DB.Query(stm, args...)
 	if err != nil {
 		log.Printf("query='%s' args='%v' error=%v", stm, args, err)
-		return
+		return Error(err, QueryErrorCode, "unable to query files", "dbs.blockdump.scanFiles")
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -216,52 +229,61 @@ func getFileList(blk string, wg *sync.WaitGroup, files *FileList) {
 			file.AutoCrossSection = xt.Float64
 		}
 		if err != nil {
-			log.Println("unable to scan rows", err)
-			return
-		}
-
-		// get file lumis for given LFN
-		var fargs []interface{}
-		fargs = append(fargs, file.LogicalFileName)
-		fstm := getSQL("blockdump_filelumis")
-		fstm = CleanStatement(fstm)
-		if utils.VERBOSE > 1 {
-			utils.PrintSQL(fstm, fargs, "execute")
-		}
-		frows, err := DB.Query(fstm, fargs...)
-		if err != nil {
-			log.Printf("query='%s' args='%v' error=%v", fstm, fargs, err)
-			return
+			return Error(err, RowsScanErrorCode, "unable to scan files", "dbs.blockdump.scanFiles")
 		}
-		defer frows.Close()
-		// ensure that fileLumiList will be serialized as empty list [] and not as null
-		fileLumiList := make([]FileLumi, 0)
-		for frows.Next() {
-			fileLumi := FileLumi{}
-			var evt sql.NullInt64
-			err = frows.Scan(
-				&fileLumi.LumiSectionNumber,
-				&fileLumi.RunNumber,
-				&evt,
-			)
-			if evt.Valid {
-				fileLumi.EventCount = evt.Int64
-			}
+		if lumis {
+			file.FileLumiList, err = getFileLumiList(file.LogicalFileName)
 			if err != nil {
-				log.Println("unable to scan rows", err)
-				return
+				return err
 			}
-			fileLumiList = append(fileLumiList, fileLumi)
 		}
-		if err = frows.Err(); err != nil {
-			log.Printf("rows error %v", err)
+		if err := fn(file); err != nil {
+			return err
 		}
-		file.FileLumiList = fileLumiList
-		*files = append(*files, file)
 	}
 	if err = rows.Err(); err != nil {
-		log.Printf("rows error %v", err)
+		return Error(err, RowsScanErrorCode, "unable to scan files", "dbs.blockdump.scanFiles")
+	}
+	return nil
+}
+
+// helper function to get file lumis for given LFN
+func getFileLumiList(lfn string) ([]FileLumi, error) {
+	var args []interface{}
+	args = append(args, lfn)
+	stm := getSQL("blockdump_filelumis")
+	stm = CleanStatement(stm)
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	rows, err := DB.Query(stm, args...)
+	if err != nil {
+		log.Printf("query='%s' args='%v' error=%v", stm, args, err)
+		return nil, Error(err, QueryErrorCode, "unable to query file lumis", "dbs.blockdump.getFileLumiList")
+	}
+	defer rows.Close()
+	// ensure that fileLumiList will be serialized as empty list [] and not as null
+	fileLumiList := make([]FileLumi, 0)
+	for rows.Next() {
+		fileLumi := FileLumi{}
+		var evt sql.NullInt64
+		err = rows.Scan(
+			&fileLumi.LumiSectionNumber,
+			&fileLumi.RunNumber,
+			&evt,
+		)
+		if evt.Valid {
+			fileLumi.EventCount = evt.Int64
+		}
+		if err != nil {
+			return nil, Error(err, RowsScanErrorCode, "unable to scan file lumis", "dbs.blockdump.getFileLumiList")
+		}
+		fileLumiList = append(fileLumiList, fileLumi)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, Error(err, RowsScanErrorCode, "unable to scan file lumis", "dbs.blockdump.getFileLumiList")
 	}
+	return fileLumiList, nil
 }
 
 // BlockParentList represents BlockParent records
@@ -494,13 +516,112 @@ type BlockDumpRecord struct {
 	DATASET_CONF_LIST   string   `json:"dataset_conf_list"`
 }
 
+// BlockDumpSections lists sections of block dump record which can be
+// selected via include and exclude parameters of BlockDump API, the names
+// match keys of block dump record and file_lumi_list refers to lumis of files
+var BlockDumpSections = []string{
+	"block",
+	"dataset",
+	"primds",
+	"processing_era",
+	"acquisition_era",
+	"files",
+	"file_lumi_list",
+	"block_parent_list",
+	"dataset_parent_list",
+	"file_conf_list",
+	"file_parent_list",
+	"dataset_conf_list",
+}
+
+// helper function to get values of include or exclude parameter, values
+// can be given either as comma separated list or as repeated parameter
+func sectionValues(params Record, key string) []string {
+	var out []string
+	for _, val := range getValues(params, key) {
+		for _, v := range strings.Split(val, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// helper function to get block dump sections selected by include and
+// exclude parameters, all sections are selected by default
+func blockDumpSections(params Record) (map[string]bool, error) {
+	include := sectionValues(params, "include")
+	exclude := sectionValues(params, "exclude")
+	if len(include) > 0 && len(exclude) > 0 {
+		msg := "BlockDump API accepts either include or exclude parameter"
+		return nil, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.blockdump.blockDumpSections")
+	}
+	for _, s := range append(include, exclude...) {
+		if !utils.InList(s, BlockDumpSections) {
+			msg := fmt.Sprintf("unknown block dump section '%s', allowed sections %v", s, BlockDumpSections)
+			return nil, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.blockdump.blockDumpSections")
+		}
+	}
+	sections := make(map[string]bool)
+	if len(include) > 0 {
+		for _, s := range include {
+			sections[s] = true
+		}
+		// file lumis are part of file records
+		if sections["file_lumi_list"] {
+			sections["files"] = true
+		}
+		return sections, nil
+	}
+	for _, s := range BlockDumpSections {
+		if !utils.InList(s, exclude) {
+			sections[s] = true
+		}
+	}
+	return sections, nil
+}
+
+// helper function to check if block dump files should be streamed, the
+// streaming is requested by stream=files parameter and requires ndjson output
+func blockDumpStream(params Record, sep string) (bool, error) {
+	val, err := getSingleValue(params, "stream")
+	if err != nil || val == "" {
+		return false, nil
+	}
+	if val != "files" {
+		msg := fmt.Sprintf("unsupported stream value '%s', only files can be streamed", val)
+		return false, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.blockdump.blockDumpStream")
+	}
+	if sep != "" {
+		msg := "stream=files requires application/ndjson output"
+		return false, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.blockdump.blockDumpStream")
+	}
+	return true, nil
+}
+
 // BlockDump DBS API
+//
+// The include or exclude parameters restrict block dump to given sections.
+// The stream=files parameter along with ndjson output writes all selected
+// sections except files in the first line and single file in every
+// following line, i.e. files are written as soon as they are read from DB.
+//
+//gocyclo:ignore
 func (a *API) BlockDump() error {
 
 	blk, err := getSingleValue(a.Params, "block_name")
 	if err != nil {
 		return Error(err, InvalidParameterErrorCode, "unable to get block_name value", "dbs.blockdump.BlockDump")
 	}
+	sections, err := blockDumpSections(a.Params)
+	if err != nil {
+		return err
+	}
+	stream, err := blockDumpStream(a.Params, a.Separator)
+	if err != nil {
+		return err
+	}
 
 	// fill out BulkBlock record via async calls
 	var datasetConfigList DatasetConfigList
@@ -518,20 +639,53 @@ func (a *API) BlockDump() error {
 	blockParentList := make(BlockParentList, 0)
 	datasetParentList := make(DatasetParentList, 0)
 
-	// get concurrently all necessary information required for block dump
+	// get concurrently all information of selected sections, files are
+	// fetched here only if they are not streamed
 	var wg sync.WaitGroup
-	wg.Add(11) // wait for 11 goroutines below
-	go getBlock(blk, &wg, &block)
-	go getDataset(blk, &wg, &dataset)
-	go getPrimaryDataset(blk, &wg, &primaryDataset)
-	go getProcessingEra(blk, &wg, &processingEra)
-	go getAcquisitionEra(blk, &wg, &acquisitionEra)
-	go getFileList(blk, &wg, &files)
-	go getBlockParentList(blk, &wg, &blockParentList)
-	go getDatasetParentList(blk, &wg, &datasetParentList)
-	go getFileConfigList(blk, &wg, &fileConfigList)
-	go getFileParentList(blk, &wg, &fileParentList)
-	go getDatasetConfigList(blk, &wg, &datasetConfigList)
+	if sections["block"] {
+		wg.Add(1)
+		go getBlock(blk, &wg, &block)
+	}
+	if sections["dataset"] {
+		wg.Add(1)
+		go getDataset(blk, &wg, &dataset)
+	}
+	if sections["primds"] {
+		wg.Add(1)
+		go getPrimaryDataset(blk, &wg, &primaryDataset)
+	}
+	if sections["processing_era"] {
+		wg.Add(1)
+		go getProcessingEra(blk, &wg, &processingEra)
+	}
+	if sections["acquisition_era"] {
+		wg.Add(1)
+		go getAcquisitionEra(blk, &wg, &acquisitionEra)
+	}
+	if sections["files"] && !stream {
+		wg.Add(1)
+		go getFileList(blk, sections["file_lumi_list"], &wg, &files)
+	}
+	if sections["block_parent_list"] {
+		wg.Add(1)
+		go getBlockParentList(blk, &wg, &blockParentList)
+	}
+	if sections["dataset_parent_list"] {
+		wg.Add(1)
+		go getDatasetParentList(blk, &wg, &datasetParentList)
+	}
+	if sections["file_conf_list"] {
+		wg.Add(1)
+		go getFileConfigList(blk, &wg, &fileConfigList)
+	}
+	if sections["file_parent_list"] {
+		wg.Add(1)
+		go getFileParentList(blk, &wg, &fileParentList)
+	}
+	if sections["dataset_conf_list"] {
+		wg.Add(1)
+		go getDatasetConfigList(blk, &wg, &datasetConfigList)
+	}
 	wg.Wait()
 
 	if utils.VERBOSE > 1 {
@@ -543,29 +697,80 @@ func (a *API) BlockDump() error {
 		dsParentList = append(dsParentList, DatasetParent{ParentDataset: d})
 	}
 
-	// initialize BulkBlocks record
-	rec := BulkBlocks{
-		AcquisitionEra:    acquisitionEra,
-		ProcessingEra:     processingEra,
-		Block:             block,
-		Dataset:           dataset,
-		PrimaryDataset:    primaryDataset,
-		Files:             files,
-		BlockParentList:   blockParentList,
-		DatasetParentList: datasetParentList, // used by bulkblocks API
-		DsParentList:      dsParentList,      // provided by blockdump API
-		FileConfigList:    fileConfigList,
-		FileParentList:    fileParentList,
-		DatasetConfigList: datasetConfigList,
-	}
-
-	// write BulkBlocks record
-	data, err := json.Marshal(rec)
-	if err == nil {
-		a.Writer.Write(data)
+	// full block dump keeps BulkBlocks layout
+	if len(sections) == len(BlockDumpSections) && !stream {
+		// initialize BulkBlocks record
+		rec := BulkBlocks{
+			AcquisitionEra:    acquisitionEra,
+			ProcessingEra:     processingEra,
+			Block:             block,
+			Dataset:           dataset,
+			PrimaryDataset:    primaryDataset,
+			Files:             files,
+			BlockParentList:   blockParentList,
+			DatasetParentList: datasetParentList, // used by bulkblocks API
+			DsParentList:      dsParentList,      // provided by blockdump API
+			FileConfigList:    fileConfigList,
+			FileParentList:    fileParentList,
+			DatasetConfigList: datasetConfigList,
+		}
+
+		// write BulkBlocks record
+		data, err := json.Marshal(rec)
+		if err == nil {
+			a.Writer.Write(data)
+			return nil
+		}
+		return Error(err, MarshalErrorCode, "unable to encode bulk blocks record", "dbs.blockdump.BlockDump")
+	}
+
+	// otherwise write record with selected sections only
+	values := Record{
+		"block":               block,
+		"dataset":             dataset,
+		"primds":              primaryDataset,
+		"processing_era":      processingEra,
+		"acquisition_era":     acquisitionEra,
+		"files":               files,
+		"block_parent_list":   blockParentList,
+		"dataset_parent_list": datasetParentList,
+		"file_conf_list":      fileConfigList,
+		"file_parent_list":    fileParentList,
+		"dataset_conf_list":   datasetConfigList,
+	}
+	rec := make(Record)
+	for key, val := range values {
+		if sections[key] && !(key == "files" && stream) {
+			rec[key] = val
+		}
+	}
+	if sections["dataset_parent_list"] {
+		rec["ds_parent_list"] = dsParentList
+	}
+	if !stream {
+		data, err := json.Marshal(rec)
+		if err == nil {
+			a.Writer.Write(data)
+			return nil
+		}
+		return Error(err, MarshalErrorCode, "unable to encode block dump record", "dbs.blockdump.BlockDump")
+	}
+
+	// stream block dump record followed by its files
+	enc := json.NewEncoder(a.Writer)
+	if err := enc.Encode(rec); err != nil {
+		return Error(err, MarshalErrorCode, "unable to encode block dump record", "dbs.blockdump.BlockDump")
+	}
+	if !sections["files"] {
 		return nil
 	}
-	return Error(err, MarshalErrorCode, "unable to encode bulk blocks record", "dbs.blockdump.BlockDump")
+	err = scanFiles(blk, sections["file_lumi_list"], func(file File) error {
+		if err := enc.Encode(file); err != nil {
+			return Error(err, MarshalErrorCode, "unable to encode file record", "dbs.blockdump.BlockDump")
+		}
+		return nil
+	})
+	return err
 }
 
 // InsertBlockDump insert block dump record into DBS
@@ -31,6 +31,7 @@ func (a *API) Blocks() error {
 		"block_name",
 		"data_tier_name",
 		"logical_file_name",
+		"tag",
 	}
 	pattern := strings.Join(required, "|")
 	re := regexp.MustCompile(pattern)
@@ -75,6 +76,10 @@ func (a *API) Blocks() error {
 	conds, args = AddParam("dataset", "DS.DATASET", a.Params, conds, args)
 	conds, args = AddParam("origin_site_name", "B.ORIGIN_SITE_NAME", a.Params, conds, args)
 	conds, args = AddParam("cdate", "B.CREATION_DATE", a.Params, conds, args)
+	conds, args, err = blockTags.addConditions("B.BLOCK_ID", a.Params, conds, args)
+	if err != nil {
+		return err
+	}
 
 	minDate := getValues(a.Params, "min_cdate")
 	maxDate := getValues(a.Params, "max_cdate")
@@ -124,14 +129,55 @@ func (a *API) Blocks() error {
 			args = append(args, maxval)
 		}
 	}
+	// project output on requested fields and skip datasets join
+	// if it is not required by either requested columns or conditions
+	fields := a.fields()
+	if len(fields) > 0 {
+		var sfields []string
+		for _, f := range fields {
+			if f == "tags" {
+				// tags are keyed by block id
+				if !utils.InList("block_id", fields) {
+					sfields = append(sfields, "block_id")
+				}
+				continue
+			}
+			if f != "run_num" {
+				sfields = append(sfields, f)
+			}
+		}
+		if utils.InList("run_num", fields) && len(runs) == 0 {
+			msg := "run_num field requires run_num parameter"
+			return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.blocks.Blocks")
+		}
+		if len(sfields) == 0 {
+			sfields = []string{"block_name"}
+		}
+		columns, _, _, aliases, err := selectColumns(sfields, blocksColumns)
+		if err != nil {
+			return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.blocks.Blocks")
+		}
+		tmpl["Columns"] = columns
+		skipJoins(tmpl, map[string]string{"SkipDataset": "DS"}, aliases, conds)
+	}
+
 	stm, err := LoadTemplateSQL("blocks", tmpl)
 	if err != nil {
 		return Error(err, LoadErrorCode, "unable to load blocks query template", "dbs.blocks.Blocks")
 	}
 	stm = WhereClause(stm, conds)
 
+	// detailed output contains tags of blocks
+	var ext *recordExtension
+	if (len(fields) == 0 && tmpl["Detail"].(bool)) || utils.InList("tags", fields) {
+		ext, err = blockTags.extension("block_id", stm, args)
+		if err != nil {
+			return err
+		}
+	}
+
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAllWith(a.Writer, a.Separator, a.fields(), ext, stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query blocks table", "dbs.blocks.Blocks")
 	}
@@ -436,6 +482,17 @@ func (a *API) UpdateBlocks() error {
 	}
 	defer tx.Rollback()
 
+	// closing of the block should recompute and freeze its statistics
+	if !site && openForWriting == 0 {
+		bid, err := GetID(tx, "BLOCKS", "block_id", "block_name", blockName)
+		if err != nil {
+			return Error(err, GetBlockIDErrorCode, "unable to find block id", "dbs.blocks.UpdateBlocks")
+		}
+		err = a.UpdateBlockStats(tx, bid)
+		if err != nil {
+			return Error(err, UpdateBlockErrorCode, "unable to update block stats", "dbs.blocks.UpdateBlocks")
+		}
+	}
 	if site {
 		_, err = tx.Exec(stm, origSiteName, createBy, date, blockName)
 	} else {
@@ -474,7 +531,10 @@ func (a *API) UpdateBlockStats(tx *sql.Tx, blockID int64) error {
 	var fileCount, bid int64
 	var blkSize float64
 	err = tx.QueryRow(stm, blockID).Scan(&fileCount, &blkSize, &bid)
-	if err != nil {
+	if err == sql.ErrNoRows {
+		// block without valid files
+		fileCount, blkSize = 0, 0
+	} else if err != nil {
 		if utils.VERBOSE > 0 {
 			log.Println("unable to load block_stats template", err)
 		}
@@ -85,7 +85,7 @@ func (a *API) BlockSummaries() error {
 		}
 	}
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, genSQL+stm, args...)
+	err = executeAll(a.Writer, a.Separator, a.fields(), genSQL+stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "fail to query block summaries", "dbs.blocksummaries.BlockSummaries")
 	}
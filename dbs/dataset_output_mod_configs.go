@@ -17,7 +17,7 @@ func (a *API) DatasetOutputModConfigs() error {
 	stm := getSQL("dataset_output_mod_configs")
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query dataset output mod configs", "dbs.dataset_output_configs.DatasetOutputModConfigs")
 	}
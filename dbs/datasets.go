@@ -182,6 +182,10 @@ func (a *API) Datasets() error {
 	conds, args = AddParam("create_by", "D.CREATE_BY", a.Params, conds, args)
 	conds, args = AddParam("last_modified_by", "D.LAST_MODIFIED_BY", a.Params, conds, args)
 	conds, args = AddParam("prep_id", "D.PREP_ID", a.Params, conds, args)
+	conds, args, err = datasetTags.addConditions("D.DATASET_ID", a.Params, conds, args)
+	if err != nil {
+		return err
+	}
 
 	dids := getValues(a.Params, "dataset_id")
 	if len(dids) == 1 {
@@ -206,6 +210,61 @@ func (a *API) Datasets() error {
 		}
 	}
 
+	// project output on requested fields and skip joins which are not
+	// required by either requested columns or where clause conditions
+	fields := a.fields()
+	var fcols []string
+	var fvals []interface{}
+	if len(fields) > 0 {
+		var sfields []string
+		for _, f := range fields {
+			if f == "tags" {
+				// tags are keyed by dataset id
+				if !utils.InList("dataset_id", fields) {
+					sfields = append(sfields, "dataset_id")
+				}
+				continue
+			}
+			if f == "parent_dataset" {
+				if !tmpl["ParentDataset"].(bool) {
+					msg := "parent_dataset field requires parent_dataset parameter"
+					return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.datasets.Datasets")
+				}
+				continue
+			}
+			sfields = append(sfields, f)
+		}
+		if len(sfields) == 0 {
+			sfields = []string{"dataset"}
+		}
+		columns, cols, vals, aliases, err := selectColumns(sfields, datasetsColumns)
+		if err != nil {
+			return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.datasets.Datasets")
+		}
+		tmpl["Columns"] = columns
+		fcols = cols
+		fvals = vals
+		for _, alias := range []string{"OMC", "RV", "PSH", "AEX"} {
+			if utils.InList(alias, aliases) {
+				tmpl["Version"] = true
+			}
+		}
+		// primary dataset types are joined via primary datasets table
+		if utils.InList("PDT", aliases) || aliasUsed("PDT", conds) {
+			aliases = append(aliases, "P")
+		}
+		skip := map[string]string{
+			"SkipPrimary":        "P",
+			"SkipPrimaryType":    "PDT",
+			"SkipProcessed":      "PD",
+			"SkipTier":           "DT",
+			"SkipAcquisitionEra": "AE",
+			"SkipProcessingEra":  "PE",
+			"SkipPhysicsGroup":   "PH",
+		}
+		skipJoins(tmpl, skip, aliases, conds)
+	}
+
 	// get SQL statement from static area
 	stm, err := LoadTemplateSQL("datasets", tmpl)
 	if err != nil {
@@ -261,7 +320,10 @@ func (a *API) Datasets() error {
 			new(sql.NullString),
 			new(sql.NullString))
 	}
-	if strings.ToLower(detail) != "true" {
+	if len(fields) > 0 {
+		cols = fcols
+		vals = fvals
+	} else if strings.ToLower(detail) != "true" {
 		//         stm = getSQL("datasets_short")
 		cols = []string{"dataset"}
 		vals = []interface{}{new(sql.NullString)}
@@ -272,8 +334,17 @@ func (a *API) Datasets() error {
 	}
 	stm = WhereClause(stm, conds)
 
+	// detailed output contains tags of datasets
+	var ext *recordExtension
+	if (len(fields) == 0 && strings.ToLower(detail) == "true") || utils.InList("tags", fields) {
+		ext, err = datasetTags.extension("dataset_id", stm, args)
+		if err != nil {
+			return err
+		}
+	}
+
 	// use generic query API to fetch the results from DB
-	err = execute(a.Writer, a.Separator, stm, cols, vals, args...)
+	err = executeWith(a.Writer, a.Separator, fields, ext, stm, cols, vals, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query DATASETs table", "dbs.datasets.Datasets")
 	}
@@ -0,0 +1,94 @@
+package dbs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// datasetStatsGroups defines allowed group_by values of datasetstats API
+// along with template flags which enable corresponding SQL joins
+var datasetStatsGroups = map[string]string{
+	"data_tier_name":       "Tier",
+	"acquisition_era_name": "AcquisitionEra",
+	"physics_group_name":   "PhysicsGroup",
+	"primary_ds_type":      "PrimaryType",
+	"creation_month":       "CreationMonth",
+	"dataset_access_type":  "AccessType",
+}
+
+// DatasetStats API provides aggregated number of datasets, blocks, files,
+// events and total size of datasets grouped by given attributes
+func (a *API) DatasetStats() error {
+	var args []interface{}
+	var conds []string
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+
+	// parse group_by argument
+	var groups []string
+	for _, val := range getValues(a.Params, "group_by") {
+		for _, g := range strings.Split(val, ",") {
+			g = strings.ToLower(strings.Trim(g, " "))
+			if g == "" || utils.InList(g, groups) {
+				continue
+			}
+			key, ok := datasetStatsGroups[g]
+			if !ok {
+				var allowed []string
+				for k := range datasetStatsGroups {
+					allowed = append(allowed, k)
+				}
+				sort.Strings(allowed)
+				msg := fmt.Sprintf(
+					"unsupported group_by value '%s', allowed values: %s",
+					g, strings.Join(allowed, ","))
+				return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.datasetstats.DatasetStats")
+			}
+			tmpl[key] = true
+			groups = append(groups, strings.ToUpper(g))
+		}
+	}
+	var groupBy []string
+	for _, g := range groups {
+		groupBy = append(groupBy, fmt.Sprintf("DS.%s", g))
+	}
+	tmpl["Groups"] = groups
+	tmpl["GroupBy"] = strings.Join(groupBy, ", ")
+
+	// parse dataset argument
+	datasets := getValues(a.Params, "dataset")
+	if len(datasets) > 1 {
+		msg := "Unsupported list of dataset"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.datasetstats.DatasetStats")
+	}
+	conds, args = AddParam("dataset", "D.DATASET", a.Params, conds, args)
+
+	// parse dataset_access_type argument
+	datasetAccessType, _ := getSingleValue(a.Params, "dataset_access_type")
+	oper := "="
+	if datasetAccessType == "" {
+		datasetAccessType = "VALID"
+	} else if datasetAccessType == "*" {
+		datasetAccessType = "%"
+		oper = "like"
+	}
+	cond := fmt.Sprintf("DP.DATASET_ACCESS_TYPE %s %s", oper, placeholder("dataset_access_type"))
+	conds = append(conds, cond)
+	args = append(args, datasetAccessType)
+	tmpl["Where"] = strings.Join(conds, " AND ")
+
+	stm, err := LoadTemplateSQL("datasetstats", tmpl)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load datasetstats template", "dbs.datasetstats.DatasetStats")
+	}
+
+	// use generic query API to fetch the results from DB
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "fail to query dataset statistics", "dbs.datasetstats.DatasetStats")
+	}
+	return nil
+}
@@ -161,14 +161,13 @@ func insertRecord(rec DBRecord, r io.Reader) error {
 
 // LoadTemplateSQL function loads DBS SQL templated statements
 func LoadTemplateSQL(tmpl string, tmplData Record) (string, error) {
-	sdir := fmt.Sprintf("%s/sql", utils.STATICDIR)
 	if !strings.HasSuffix(tmpl, ".sql") {
 		tmpl += ".sql"
 	}
 	if utils.VERBOSE > 1 {
 		log.Println("load template", tmpl)
 	}
-	stm, err := utils.ParseTmpl(sdir, tmpl, tmplData)
+	stm, err := utils.ParseStaticTmpl("sql", tmpl, tmplData)
 	if err != nil {
 		return "", Error(err, LoadErrorCode, "", "dbs.LoadTemplateSQL")
 	}
@@ -185,14 +184,16 @@ func LoadTemplateSQL(tmpl string, tmplData Record) (string, error) {
 func LoadSQL(owner string) Record {
 	tmplData := make(Record)
 	tmplData["Owner"] = owner
-	sdir := fmt.Sprintf("%s/sql", utils.STATICDIR)
-	if utils.VERBOSE > 1 {
-		log.Println("sql area", sdir)
+	if utils.VERBOSE > 1 && utils.StaticOverride != "" {
+		log.Println("sql override area", utils.StaticOverride)
 	}
 	dbsql := make(Record)
-	for _, f := range utils.ListFiles(sdir) {
+	for _, f := range utils.StaticFiles("sql") {
+		if !strings.HasSuffix(f, ".sql") {
+			continue
+		}
 		k := strings.Split(f, ".")[0]
-		stm, err := utils.ParseTmpl(sdir, f, tmplData)
+		stm, err := utils.ParseStaticTmpl("sql", f, tmplData)
 		if err != nil {
 			log.Fatal("unable to parse template", err)
 		}
@@ -350,10 +351,24 @@ func CleanStatement(stm string) string {
 // http://stackoverflow.com/questions/17845619/how-to-call-the-scan-variadic-function-in-golang-using-reflection
 // here we use http response writer in order to make encoder
 // then we literally stream data with our encoder (i.e. write records
-// to writer)
+// to writer). The optional list of fields defines projection of
+// output records on a subset of columns returned by the statement.
+func executeAll(w io.Writer, sep string, fields []string, stm string, args ...interface{}) error {
+	return executeAllWith(w, sep, fields, nil, stm, args...)
+}
+
+// recordExtension represents set of additional fields which are computed
+// for every record returned by executeAllWith
+type recordExtension struct {
+	Fields []string     // names of additional fields
+	Extend func(Record) // function to set additional fields of a record
+}
+
+// similar to executeAll function but it allows to extend every output
+// record with additional fields which can not be obtained from DB statement
 //
 //gocyclo:ignore
-func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
+func executeAllWith(w io.Writer, sep string, fields []string, ext *recordExtension, stm string, args ...interface{}) error {
 	stm = CleanStatement(stm)
 	if DRYRUN {
 		utils.PrintSQL(stm, args, "")
@@ -383,6 +398,18 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 
 	// extract columns from Rows object and create values & valuesPtrs to retrieve results
 	columns, _ := rows.Columns()
+	if len(fields) > 0 {
+		var names []string
+		for _, col := range columns {
+			names = append(names, strings.ToLower(col))
+		}
+		if ext != nil {
+			names = append(names, ext.Fields...)
+		}
+		if err := checkFields(fields, names); err != nil {
+			return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.executeAll")
+		}
+	}
 	var cols []string
 	count := len(columns)
 	values := make([]interface{}, count)
@@ -436,6 +463,10 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 				rec[cols[i]] = val
 			}
 		}
+		if ext != nil {
+			ext.Extend(rec)
+		}
+		rec = projectRecord(rec, fields)
 		if w != nil {
 			if rowCount == 0 {
 				if sep != "" {
@@ -462,14 +493,36 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 }
 
 // similar to executeAll function but it takes explicit set of columns and values
+func execute(
+	w io.Writer,
+	sep string,
+	fields []string,
+	stm string,
+	cols []string,
+	vals []interface{}, args ...interface{}) error {
+	return executeWith(w, sep, fields, nil, stm, cols, vals, args...)
+}
+
+// similar to execute function but it allows to extend every output
+// record with additional fields which can not be obtained from DB statement
 //
 //gocyclo:ignore
-func execute(
+func executeWith(
 	w io.Writer,
-	sep, stm string,
+	sep string,
+	fields []string,
+	ext *recordExtension,
+	stm string,
 	cols []string,
 	vals []interface{}, args ...interface{}) error {
 
+	names := cols
+	if ext != nil {
+		names = append(append([]string{}, cols...), ext.Fields...)
+	}
+	if err := checkFields(fields, names); err != nil {
+		return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.execute")
+	}
 	stm = CleanStatement(stm)
 	if DRYRUN {
 		utils.PrintSQL(stm, args, "")
@@ -539,6 +592,10 @@ func execute(
 				rec[cols[i]] = val
 			}
 		}
+		if ext != nil {
+			ext.Extend(rec)
+		}
+		rec = projectRecord(rec, fields)
 		if w != nil {
 			if rowCount == 0 {
 				if sep != "" {
@@ -0,0 +1,444 @@
+package dbs
+
+// delete.go - provides admin APIs to delete blocks and datasets
+//
+// The soft deletion invalidates files of a block or dataset (IS_FILE_VALID=-1)
+// and marks dataset as DELETED, while hard deletion removes the block or
+// dataset along with all its dependent rows. Both modes refuse to delete
+// entities which have children outside of them and record every deletion in
+// DELETION_AUDIT table.
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// deletion modes
+const (
+	SoftDelete = "soft"
+	HardDelete = "hard"
+)
+
+// DeleteRecord represents input record of Delete API
+// Dataset: name of dataset to delete
+// BlockName: name of block to delete
+// Mode: deletion mode, soft (default) or hard
+// DryRun: if set, API reports affected rows without deleting them
+// Reason: reason of deletion recorded in deletion audit
+type DeleteRecord struct {
+	Dataset   string `json:"dataset,omitempty"`
+	BlockName string `json:"block_name,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// DeleteReport represents report of Delete API
+type DeleteReport struct {
+	Dataset   string           `json:"dataset,omitempty"`
+	BlockName string           `json:"block_name,omitempty"`
+	Mode      string           `json:"mode"`
+	DryRun    bool             `json:"dry_run"`
+	Rows      map[string]int64 `json:"rows"`
+}
+
+// DeletionAudit represents DELETION_AUDIT DBS DB table
+type DeletionAudit struct {
+	DELETION_ID   int64  `json:"deletion_id"`
+	ENTITY_TYPE   string `json:"entity_type"`
+	ENTITY_NAME   string `json:"entity_name"`
+	DELETION_MODE string `json:"deletion_mode"`
+	ROW_COUNTS    string `json:"row_counts"`
+	REASON        string `json:"reason"`
+	DELETED_BY    string `json:"deleted_by"`
+	DELETION_DATE int64  `json:"deletion_date"`
+}
+
+// deleteStep represents deletion of rows of single table which belong to
+// deleted entity, the rows are selected by table column which refers either
+// to deleted entity itself or to its files or blocks
+type deleteStep struct {
+	Table  string
+	Column string
+	Files  bool
+	Blocks bool
+}
+
+// deleteScope represents block or dataset which is deleted
+type deleteScope struct {
+	Entity string // entity type: block or dataset
+	Name   string // entity name
+	Column string // BLOCK_ID or DATASET_ID column of FILES and BLOCKS tables
+	ID     int64  // entity id
+}
+
+// steps returns deletion steps of hard deletion in foreign key order
+func (s deleteScope) steps() []deleteStep {
+	steps := []deleteStep{
+		{Table: "FILE_LUMIS", Column: "FILE_ID", Files: true},
+		{Table: "FILE_PARENTS", Column: "THIS_FILE_ID", Files: true},
+		{Table: "ASSOCIATED_FILES", Column: "THIS_FILE_ID", Files: true},
+		{Table: "FILE_OUTPUT_MOD_CONFIGS", Column: "FILE_ID", Files: true},
+		{Table: "FILES", Column: s.Column},
+		{Table: "BLOCK_PARENTS", Column: "THIS_BLOCK_ID", Blocks: true},
+		{Table: "BLOCK_TAGS", Column: "BLOCK_ID", Blocks: true},
+		{Table: "BLOCKS", Column: s.Column},
+	}
+	if s.Entity == "dataset" {
+		steps = append(steps,
+			deleteStep{Table: "DATASET_OUTPUT_MOD_CONFIGS", Column: "DATASET_ID"},
+			deleteStep{Table: "DATASET_PARENTS", Column: "THIS_DATASET_ID"},
+			deleteStep{Table: "DATASET_RUNS", Column: "DATASET_ID"},
+			deleteStep{Table: "DATASET_TAGS", Column: "DATASET_ID"},
+			deleteStep{Table: "DATASET_RENAMES", Column: "DATASET_ID"},
+			deleteStep{Table: "DATASETS", Column: "DATASET_ID"},
+		)
+	}
+	return steps
+}
+
+// children returns template flags of statements which count children of
+// deleted entity which do not belong to it
+func (s deleteScope) children() map[string]string {
+	out := map[string]string{
+		"file_children":  "FileChildren",
+		"block_children": "BlockChildren",
+	}
+	if s.Entity == "dataset" {
+		out["dataset_children"] = "DatasetChildren"
+	}
+	return out
+}
+
+// helper function to load SQL template of deletion statement
+func deleteSQL(name string, tmpl Record) (string, error) {
+	tmpl["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL(name, tmpl)
+	if err != nil {
+		msg := fmt.Sprintf("unable to load %s template", name)
+		return "", Error(err, LoadErrorCode, msg, "dbs.delete.deleteSQL")
+	}
+	return stm, nil
+}
+
+// Delete DBS API deletes block or dataset, see DeleteRecord for its options
+//
+//gocyclo:ignore
+func (a *API) Delete() error {
+	// read given input
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		log.Println("fail to read data", err)
+		return Error(err, ReaderErrorCode, "unable to read delete record", "dbs.delete.Delete")
+	}
+	var rec DeleteRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		log.Println("fail to decode data as DeleteRecord", err)
+		return Error(err, UnmarshalErrorCode, "unable to decode delete record", "dbs.delete.Delete")
+	}
+	if utils.VERBOSE > 0 {
+		log.Printf("Delete record %+v", rec)
+	}
+	if (rec.Dataset == "") == (rec.BlockName == "") {
+		msg := "Delete API requires either dataset or block_name"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.delete.Delete")
+	}
+	if rec.Mode == "" {
+		rec.Mode = SoftDelete
+	}
+	if rec.Mode != SoftDelete && rec.Mode != HardDelete {
+		msg := fmt.Sprintf("unsupported deletion mode '%s', should be %s or %s", rec.Mode, SoftDelete, HardDelete)
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.delete.Delete")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "transaction error", "dbs.delete.Delete")
+	}
+	defer tx.Rollback()
+
+	scope := deleteScope{Entity: "block", Name: rec.BlockName, Column: "BLOCK_ID"}
+	if rec.Dataset != "" {
+		scope = deleteScope{Entity: "dataset", Name: rec.Dataset, Column: "DATASET_ID"}
+		scope.ID, err = GetID(tx, "DATASETS", "dataset_id", "dataset", rec.Dataset)
+	} else {
+		scope.ID, err = GetID(tx, "BLOCKS", "block_id", "block_name", rec.BlockName)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("unable to find %s %s", scope.Entity, scope.Name)
+		return Error(err, GetIDErrorCode, msg, "dbs.delete.Delete")
+	}
+
+	// entities which have children can not be deleted
+	if err := checkChildren(tx, scope); err != nil {
+		return err
+	}
+
+	report := DeleteReport{
+		Dataset:   rec.Dataset,
+		BlockName: rec.BlockName,
+		Mode:      rec.Mode,
+		DryRun:    rec.DryRun,
+	}
+	if rec.Mode == HardDelete {
+		report.Rows, err = hardDelete(tx, scope, rec.DryRun)
+	} else {
+		report.Rows, err = a.softDelete(tx, scope, rec.DryRun)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !rec.DryRun {
+		counts, err := json.Marshal(report.Rows)
+		if err != nil {
+			return Error(err, MarshalErrorCode, "unable to marshal deleted rows", "dbs.delete.Delete")
+		}
+		audit := DeletionAudit{
+			ENTITY_TYPE:   scope.Entity,
+			ENTITY_NAME:   scope.Name,
+			DELETION_MODE: rec.Mode,
+			ROW_COUNTS:    string(counts),
+			REASON:        rec.Reason,
+			DELETED_BY:    a.CreateBy,
+			DELETION_DATE: time.Now().Unix(),
+		}
+		if err := audit.Insert(tx); err != nil {
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			log.Println("fail to commit transaction", err)
+			return Error(err, CommitErrorCode, "unable to commit deletion", "dbs.delete.Delete")
+		}
+		log.Printf("%s deletion of %s %s by %s, rows %s", rec.Mode, scope.Entity, scope.Name, a.CreateBy, counts)
+		if rec.Mode == HardDelete && scope.Entity == "dataset" {
+			// old names of deleted dataset should no longer be resolved
+			resetDatasetRenames()
+		}
+	}
+
+	data, err = json.Marshal([]DeleteReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to marshal delete report", "dbs.delete.Delete")
+	}
+	if a.Writer != nil {
+		a.Writer.Write(data)
+	}
+	return nil
+}
+
+// helper function to check that entity does not have children
+func checkChildren(tx *sql.Tx, scope deleteScope) error {
+	var found []string
+	stms := scope.children()
+	var names []string
+	for name := range stms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stm, err := deleteSQL("delete_children", Record{stms[name]: true, "Column": scope.Column})
+		if err != nil {
+			return err
+		}
+		if utils.VERBOSE > 1 {
+			utils.PrintSQL(stm, []interface{}{scope.ID, scope.ID}, "execute")
+		}
+		var count int64
+		err = tx.QueryRow(stm, scope.ID, scope.ID).Scan(&count)
+		if err != nil {
+			return Error(err, QueryErrorCode, "unable to count children", "dbs.delete.checkChildren")
+		}
+		if count > 0 {
+			found = append(found, fmt.Sprintf("%s=%d", name, count))
+		}
+	}
+	if len(found) > 0 {
+		msg := fmt.Sprintf("%s %s has children: %s", scope.Entity, scope.Name, strings.Join(found, ", "))
+		return Error(errors.New(msg), DeleteChildrenErrorCode, msg, "dbs.delete.checkChildren")
+	}
+	return nil
+}
+
+// helper function to delete entity along with its dependent rows, in dry-run
+// mode it only counts rows which would be deleted
+func hardDelete(tx *sql.Tx, scope deleteScope, dryRun bool) (map[string]int64, error) {
+	rows := make(map[string]int64)
+	for _, step := range scope.steps() {
+		tmpl := Record{
+			"DryRun": dryRun,
+			"Table":  step.Table,
+			"Column": step.Column,
+			"Files":  step.Files,
+			"Blocks": step.Blocks,
+			"Scope":  scope.Column,
+		}
+		stm, err := deleteSQL("delete_rows", tmpl)
+		if err != nil {
+			return nil, err
+		}
+		if utils.VERBOSE > 1 {
+			utils.PrintSQL(stm, []interface{}{scope.ID}, "execute")
+		}
+		var count int64
+		if dryRun {
+			if err := tx.QueryRow(stm, scope.ID).Scan(&count); err != nil {
+				msg := fmt.Sprintf("unable to count rows of %s", step.Table)
+				return nil, Error(err, QueryErrorCode, msg, "dbs.delete.hardDelete")
+			}
+		} else {
+			res, err := tx.Exec(stm, scope.ID)
+			if err != nil {
+				msg := fmt.Sprintf("unable to delete rows of %s", step.Table)
+				return nil, Error(err, DeleteErrorCode, msg, "dbs.delete.hardDelete")
+			}
+			count, _ = res.RowsAffected()
+		}
+		rows[step.Table] = count
+	}
+	return rows, nil
+}
+
+// helper function to invalidate files of entity and mark dataset as
+// DELETED, in dry-run mode it only counts rows which would be updated
+func (a *API) softDelete(tx *sql.Tx, scope deleteScope, dryRun bool) (map[string]int64, error) {
+	rows := make(map[string]int64)
+	var accessTypeID int64
+	var err error
+	if scope.Entity == "dataset" {
+		accessTypeID, err = GetID(tx, "DATASET_ACCESS_TYPES", "dataset_access_type_id", "dataset_access_type", "DELETED")
+		if err != nil {
+			msg := "DELETED dataset access type is not found"
+			return nil, Error(err, GetDatasetAccessTypeIDErrorCode, msg, "dbs.delete.softDelete")
+		}
+		rows["DATASETS"] = 1
+	}
+	tmpl := Record{"DryRun": dryRun, "Column": scope.Column}
+	stm, err := deleteSQL("softdelete_files", tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		var count int64
+		if err := tx.QueryRow(stm, scope.ID).Scan(&count); err != nil {
+			return nil, Error(err, QueryErrorCode, "unable to count files", "dbs.delete.softDelete")
+		}
+		rows["FILES"] = count
+		return rows, nil
+	}
+
+	// find blocks of the entity to update their statistics
+	var bids []int64
+	bstm, err := deleteSQL("softdelete_blocks", Record{"Column": scope.Column})
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.Query(bstm, scope.ID)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "unable to query blocks", "dbs.delete.softDelete")
+	}
+	for res.Next() {
+		var bid int64
+		if err := res.Scan(&bid); err != nil {
+			res.Close()
+			return nil, Error(err, RowsScanErrorCode, "unable to scan block id", "dbs.delete.softDelete")
+		}
+		bids = append(bids, bid)
+	}
+	res.Close()
+
+	tstamp := time.Now().Unix()
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, []interface{}{a.CreateBy, tstamp, scope.ID}, "execute")
+	}
+	result, err := tx.Exec(stm, a.CreateBy, tstamp, scope.ID)
+	if err != nil {
+		return nil, Error(err, UpdateFileErrorCode, "unable to invalidate files", "dbs.delete.softDelete")
+	}
+	rows["FILES"], _ = result.RowsAffected()
+	for _, bid := range bids {
+		if err := a.UpdateBlockStats(tx, bid); err != nil {
+			return nil, err
+		}
+	}
+	if scope.Entity == "dataset" {
+		stm = getSQL("softdelete_dataset")
+		if _, err := tx.Exec(stm, accessTypeID, a.CreateBy, tstamp, scope.ID); err != nil {
+			return nil, Error(err, UpdateDatasetErrorCode, "unable to mark dataset as deleted", "dbs.delete.softDelete")
+		}
+	}
+	return rows, nil
+}
+
+// Insert implementation of DeletionAudit
+func (r *DeletionAudit) Insert(tx *sql.Tx) error {
+	var tid int64
+	var err error
+	if r.DELETION_ID == 0 {
+		if DBOWNER == "sqlite" {
+			tid, err = LastInsertID(tx, "DELETION_AUDIT", "deletion_id")
+			r.DELETION_ID = tid + 1
+		} else {
+			tid, err = IncrementSequence(tx, "SEQ_DLA")
+			r.DELETION_ID = tid
+		}
+		if err != nil {
+			return Error(err, LastInsertErrorCode, "unable to increment deletion audit sequence number", "dbs.delete.Insert")
+		}
+	}
+	// get SQL statement from static area
+	stm := getSQL("insert_deletion_audit")
+	if utils.VERBOSE > 0 {
+		log.Printf("Insert DeletionAudit\n%s\n%+v", stm, r)
+	}
+	_, err = tx.Exec(stm,
+		r.DELETION_ID,
+		r.ENTITY_TYPE,
+		r.ENTITY_NAME,
+		r.DELETION_MODE,
+		r.ROW_COUNTS,
+		r.REASON,
+		r.DELETED_BY,
+		r.DELETION_DATE)
+	if err != nil {
+		if utils.VERBOSE > 0 {
+			log.Println("unable to insert DeletionAudit", err)
+		}
+		return Error(err, InsertErrorCode, "unable to insert deletion audit record", "dbs.delete.Insert")
+	}
+	return nil
+}
+
+// Deletions DBS API lists deletion audit records
+func (a *API) Deletions() error {
+	var args []interface{}
+	var conds []string
+
+	conds, args = AddParam("entity_name", "DA.ENTITY_NAME", a.Params, conds, args)
+	conds, args = AddParam("entity_type", "DA.ENTITY_TYPE", a.Params, conds, args)
+	conds, args = AddParam("deleted_by", "DA.DELETED_BY", a.Params, conds, args)
+
+	// get SQL statement from static area
+	stm := getSQL("deletions")
+	stm = WhereClause(stm, conds)
+	stm += " ORDER BY DA.DELETION_ID"
+
+	// use generic query API to fetch the results from DB
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "unable to query deletion audit", "dbs.delete.Deletions")
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package dbs
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// DrainTimeout defines time in seconds server waits for in-flight writes and
+// migrations to finish during shutdown
+var DrainTimeout int
+
+// drain keeps track of drain mode and in-flight work of the server
+var drain = struct {
+	sync.Mutex
+	draining   bool
+	inFlight   int64
+	wg         sync.WaitGroup
+	migrations map[int64]MigrationRequest
+}{migrations: make(map[int64]MigrationRequest)}
+
+// TrackWork registers new unit of in-flight work, e.g. bulkblocks injection
+// or migration request. It returns function which should be called when
+// work is finished, or DrainErr if server is draining and does not accept
+// new work.
+func TrackWork() (func(), error) {
+	drain.Lock()
+	defer drain.Unlock()
+	if drain.draining {
+		return nil, Error(DrainErr, DrainErrorCode, "", "dbs.drain.TrackWork")
+	}
+	drain.inFlight++
+	drain.wg.Add(1)
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			drain.Lock()
+			drain.inFlight--
+			drain.Unlock()
+			drain.wg.Done()
+		})
+	}
+	return done, nil
+}
+
+// TrackMigration registers in-flight migration request. It returns function
+// which should be called when migration request processing is finished, or
+// DrainErr if server is draining.
+func TrackMigration(mrec MigrationRequest) (func(), error) {
+	done, err := TrackWork()
+	if err != nil {
+		return nil, err
+	}
+	mid := mrec.MIGRATION_REQUEST_ID
+	drain.Lock()
+	drain.migrations[mid] = mrec
+	drain.Unlock()
+	return func() {
+		drain.Lock()
+		delete(drain.migrations, mid)
+		drain.Unlock()
+		done()
+	}, nil
+}
+
+// Draining reports if server is in drain mode
+func Draining() bool {
+	drain.Lock()
+	defer drain.Unlock()
+	return drain.draining
+}
+
+// InFlight returns number of in-flight writes and migrations
+func InFlight() int64 {
+	drain.Lock()
+	defer drain.Unlock()
+	return drain.inFlight
+}
+
+// StartDrain switches server into drain mode where new writes and
+// migrations are rejected
+func StartDrain() {
+	drain.Lock()
+	defer drain.Unlock()
+	if !drain.draining {
+		log.Printf("start drain mode with %d in-flight requests", drain.inFlight)
+	}
+	drain.draining = true
+}
+
+// StopDrain switches server back to normal mode
+func StopDrain() {
+	drain.Lock()
+	defer drain.Unlock()
+	drain.draining = false
+}
+
+// WaitForDrain waits for in-flight work to finish within given timeout.
+// It returns false if timeout is reached before all work is finished.
+func WaitForDrain(timeout time.Duration) bool {
+	ch := make(chan bool)
+	go func() {
+		drain.wg.Wait()
+		close(ch)
+	}()
+	select {
+	case <-ch:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// InterruptMigrationRequests resets in-flight migration requests processed
+// by this server back to PENDING status, releases their leases and marks
+// their migration blocks as interrupted, such that they will be cleanly
+// retried by another (or restarted) migration server. The retry count of
+// interrupted requests is not changed.
+func InterruptMigrationRequests() error {
+	drain.Lock()
+	var records []MigrationRequest
+	for _, mrec := range drain.migrations {
+		records = append(records, mrec)
+	}
+	drain.Unlock()
+	for _, mrec := range records {
+		if err := interruptMigrationRequest(mrec); err != nil {
+			return err
+		}
+		log.Printf("migration request %d is interrupted and will be retried", mrec.MIGRATION_REQUEST_ID)
+	}
+	return nil
+}
+
+// helper function to interrupt given migration request
+func interruptMigrationRequest(mrec MigrationRequest) error {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("interrupt_migration_requests", tmplData)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load interrupt_migration_requests template", "dbs.drain.interruptMigrationRequest")
+	}
+	bstm, err := LoadTemplateSQL("interrupt_migration_blocks", tmplData)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load interrupt_migration_blocks template", "dbs.drain.interruptMigrationRequest")
+	}
+	mid := mrec.MIGRATION_REQUEST_ID
+	date := time.Now().Unix()
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.drain.interruptMigrationRequest")
+	}
+	defer tx.Rollback()
+	args := []interface{}{date, mid, MigrationOwner()}
+	if utils.VERBOSE > 0 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	res, err := tx.Exec(stm, args...)
+	if err != nil {
+		msg := fmt.Sprintf("unable to interrupt migration request %d", mid)
+		return Error(err, UpdateMigrationErrorCode, msg, "dbs.drain.interruptMigrationRequest")
+	}
+	if nrows, err := res.RowsAffected(); err == nil && nrows == 0 {
+		// migration request is not in progress or it is processed by
+		// another migration server
+		return nil
+	}
+	bargs := []interface{}{"interrupted by server shutdown", date, mid}
+	if _, err := tx.Exec(bstm, bargs...); err != nil {
+		msg := fmt.Sprintf("unable to interrupt migration blocks of request %d", mid)
+		return Error(err, UpdateMigrationErrorCode, msg, "dbs.drain.interruptMigrationRequest")
+	}
+	if err := tx.Commit(); err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.drain.interruptMigrationRequest")
+	}
+	notifyMigrationEvent(mrec, IN_PROGRESS, PENDING, mrec.RETRY_COUNT)
+	return nil
+}
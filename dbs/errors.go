@@ -33,6 +33,9 @@ var NotImplementedApiErr = errors.New("not implemented api error")
 // InvalidRequestErr represents generic invalid request error
 var InvalidRequestErr = errors.New("invalid request error")
 
+// DrainErr represents error of request rejected by draining server
+var DrainErr = errors.New("server is draining")
+
 // DBS Error codes provides static representation of DBS errors, they cover 1xx range
 const (
 	// generic errors
@@ -62,6 +65,7 @@ const (
 	MarshalErrorCode          = 123 // JSON marshal (serialization) error
 	HttpRequestErrorCode      = 124 // HTTP request error
 	X509ProxyErrorCode        = 127 // X509 proxy error code
+	DrainErrorCode            = 128 // server is draining error
 
 	// logical errors
 	BlockAlreadyExists             = 200 // block xxx already exists in DBS
@@ -133,6 +137,7 @@ const (
 	UpdateBlockErrorCode          = 501 // update block error
 	UpdateDatasetErrorCode        = 502 // update dataset error
 	UpdateFileErrorCode           = 503 // update file error
+	UpdateTagsErrorCode           = 504 // update tags error
 
 	// migration errors
 	UpdateMigrationErrorCode  = 600 // update migration error
@@ -142,6 +147,11 @@ const (
 	MigrationErrorCode        = 604 // Migration error
 	RemoveErrorCode           = 605 // remove error
 
+	// admin API errors
+	DeleteErrorCode         = 700 // delete error
+	DeleteChildrenErrorCode = 701 // delete of entity with children
+	RenameDatasetErrorCode  = 702 // rename dataset error
+
 	LastAvailableErrorCode = 900 // last available DBS error code
 )
 
@@ -223,6 +233,8 @@ func (e *DBSError) Explain() string {
 		return "invalid HTTP request"
 	case X509ProxyErrorCode:
 		return "X509 proxy error, e.g. expired certificate"
+	case DrainErrorCode:
+		return "DBS server is draining and does not accept new writes or migrations"
 
 	case BlockAlreadyExists:
 		return "block already exists"
@@ -352,6 +364,8 @@ func (e *DBSError) Explain() string {
 		return "fail to update dataset table"
 	case UpdateFileErrorCode:
 		return "fail to update file table"
+	case UpdateTagsErrorCode:
+		return "fail to update dataset or block tags"
 
 	// migration errors
 	case MigrationErrorCode:
@@ -367,6 +381,14 @@ func (e *DBSError) Explain() string {
 	case CleanupMigrationErrorCode:
 		return "cleanup migration error"
 
+	// admin API errors
+	case DeleteErrorCode:
+		return "fail to delete block or dataset"
+	case DeleteChildrenErrorCode:
+		return "block or dataset has children and can not be deleted"
+	case RenameDatasetErrorCode:
+		return "fail to rename dataset"
+
 	default:
 		return "Not defined"
 	}
@@ -0,0 +1,193 @@
+package dbs
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// FieldColumn represents single output column of DBS API along with
+// its SQL expression, table alias used in SQL template and its data type
+type FieldColumn struct {
+	Name  string // name of output column
+	Expr  string // SQL expression of the column
+	Alias string // table alias used by SQL expression
+	Type  string // data type of the column: str, int or float
+}
+
+// helper function to create new value pointer for given column type
+func (c FieldColumn) value() interface{} {
+	if c.Type == "int" {
+		return new(sql.NullInt64)
+	} else if c.Type == "float" {
+		return new(sql.NullFloat64)
+	}
+	return new(sql.NullString)
+}
+
+// datasetsColumns defines known output columns of datasets API
+var datasetsColumns = []FieldColumn{
+	{"dataset_id", "D.DATASET_ID", "D", "int"},
+	{"dataset", "D.DATASET", "D", "str"},
+	{"prep_id", "D.PREP_ID", "D", "str"},
+	{"xtcrosssection", "D.XTCROSSSECTION", "D", "float"},
+	{"creation_date", "D.CREATION_DATE", "D", "int"},
+	{"create_by", "D.CREATE_BY", "D", "str"},
+	{"last_modification_date", "D.LAST_MODIFICATION_DATE", "D", "int"},
+	{"last_modified_by", "D.LAST_MODIFIED_BY", "D", "str"},
+	{"primary_ds_name", "P.PRIMARY_DS_NAME", "P", "str"},
+	{"primary_ds_type", "PDT.PRIMARY_DS_TYPE", "PDT", "str"},
+	{"processed_ds_name", "PD.PROCESSED_DS_NAME", "PD", "str"},
+	{"data_tier_name", "DT.DATA_TIER_NAME", "DT", "str"},
+	{"dataset_access_type", "DP.DATASET_ACCESS_TYPE", "DP", "str"},
+	{"acquisition_era_name", "AE.ACQUISITION_ERA_NAME", "AE", "str"},
+	{"processing_version", "PE.PROCESSING_VERSION", "PE", "int"},
+	{"physics_group_name", "PH.PHYSICS_GROUP_NAME", "PH", "str"},
+	{"output_module_label", "OMC.OUTPUT_MODULE_LABEL", "OMC", "str"},
+	{"global_tag", "OMC.GLOBAL_TAG", "OMC", "str"},
+	{"release_version", "RV.RELEASE_VERSION", "RV", "str"},
+	{"pset_hash", "PSH.PSET_HASH", "PSH", "str"},
+	{"app_name", "AEX.APP_NAME", "AEX", "str"},
+}
+
+// filesColumns defines known output columns of files API
+var filesColumns = []FieldColumn{
+	{"file_id", "F.FILE_ID", "F", "int"},
+	{"logical_file_name", "F.LOGICAL_FILE_NAME", "F", "str"},
+	{"is_file_valid", "F.IS_FILE_VALID", "F", "int"},
+	{"dataset_id", "F.DATASET_ID", "F", "int"},
+	{"dataset", "D.DATASET", "D", "str"},
+	{"block_id", "F.BLOCK_ID", "F", "int"},
+	{"block_name", "B.BLOCK_NAME", "B", "str"},
+	{"file_type_id", "F.FILE_TYPE_ID", "F", "int"},
+	{"file_type", "FT.FILE_TYPE", "FT", "str"},
+	{"check_sum", "F.CHECK_SUM", "F", "str"},
+	{"event_count", "F.EVENT_COUNT", "F", "int"},
+	{"file_size", "F.FILE_SIZE", "F", "int"},
+	{"branch_hash_id", "F.BRANCH_HASH_ID", "F", "int"},
+	{"adler32", "F.ADLER32", "F", "str"},
+	{"md5", "F.MD5", "F", "str"},
+	{"auto_cross_section", "F.AUTO_CROSS_SECTION", "F", "float"},
+	{"creation_date", "F.CREATION_DATE", "F", "int"},
+	{"create_by", "F.CREATE_BY", "F", "str"},
+	{"last_modification_date", "F.LAST_MODIFICATION_DATE", "F", "int"},
+	{"last_modified_by", "F.LAST_MODIFIED_BY", "F", "str"},
+}
+
+// blocksColumns defines known output columns of blocks API
+var blocksColumns = []FieldColumn{
+	{"block_id", "B.BLOCK_ID", "B", "int"},
+	{"block_name", "B.BLOCK_NAME", "B", "str"},
+	{"open_for_writing", "B.OPEN_FOR_WRITING", "B", "int"},
+	{"block_size", "B.BLOCK_SIZE", "B", "int"},
+	{"file_count", "B.FILE_COUNT", "B", "int"},
+	{"dataset_id", "B.DATASET_ID", "B", "int"},
+	{"dataset", "DS.DATASET", "DS", "str"},
+	{"origin_site_name", "B.ORIGIN_SITE_NAME", "B", "str"},
+	{"creation_date", "B.CREATION_DATE", "B", "int"},
+	{"create_by", "B.CREATE_BY", "B", "str"},
+	{"last_modification_date", "B.LAST_MODIFICATION_DATE", "B", "int"},
+	{"last_modified_by", "B.LAST_MODIFIED_BY", "B", "str"},
+}
+
+// helper function to get list of requested fields from API parameters,
+// the fields parameter can be supplied either as a list or as comma
+// separated string, e.g. fields=logical_file_name,event_count
+func (a *API) fields() []string {
+	var out []string
+	for _, val := range getValues(a.Params, "fields") {
+		for _, f := range strings.Split(val, ",") {
+			f = strings.ToLower(strings.Trim(f, " "))
+			if f != "" && !utils.InList(f, out) {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// helper function to check that all requested fields belong to given columns
+func checkFields(fields, columns []string) error {
+	for _, f := range fields {
+		if !utils.InList(f, columns) {
+			msg := fmt.Sprintf(
+				"unknown field '%s', allowed fields: %s", f, strings.Join(columns, ","))
+			return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.fields.checkFields")
+		}
+	}
+	return nil
+}
+
+// helper function to get names of given field columns
+func columnNames(columns []FieldColumn) []string {
+	var out []string
+	for _, c := range columns {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+// helper function to select field columns for given list of fields
+// it returns SQL select statement of columns, list of their names,
+// value pointers to scan and list of table aliases used by columns
+func selectColumns(
+	fields []string,
+	columns []FieldColumn) (string, []string, []interface{}, []string, error) {
+	var exprs, cols, aliases []string
+	var vals []interface{}
+	if err := checkFields(fields, columnNames(columns)); err != nil {
+		return "", cols, vals, aliases, err
+	}
+	for _, f := range fields {
+		for _, c := range columns {
+			if c.Name == f {
+				exprs = append(exprs, c.Expr)
+				cols = append(cols, c.Name)
+				vals = append(vals, c.value())
+				if !utils.InList(c.Alias, aliases) {
+					aliases = append(aliases, c.Alias)
+				}
+			}
+		}
+	}
+	return strings.Join(exprs, ", "), cols, vals, aliases, nil
+}
+
+// helper function to check if given table alias is used by any of SQL conditions
+func aliasUsed(alias string, conds []string) bool {
+	pat := regexp.MustCompile(fmt.Sprintf(`\b%s\.`, alias))
+	for _, cond := range conds {
+		if pat.MatchString(cond) {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to set template flags to skip table joins which
+// are not required by either requested columns or SQL conditions.
+// The skip flags map template key to table alias used in SQL template.
+func skipJoins(tmpl Record, skip map[string]string, aliases, conds []string) {
+	for key, alias := range skip {
+		if !utils.InList(alias, aliases) && !aliasUsed(alias, conds) {
+			tmpl[key] = true
+		}
+	}
+}
+
+// helper function to project given record on a set of fields
+func projectRecord(rec Record, fields []string) Record {
+	if len(fields) == 0 {
+		return rec
+	}
+	out := make(Record)
+	for _, f := range fields {
+		if v, ok := rec[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
@@ -18,7 +18,7 @@ func (a *API) FileOutputModConfigs() error {
 	stm := getSQL("file_output_mod_configs")
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query file output mod config", "dbs.file_output_mod_configs.FileOutputModConfigs")
 	}
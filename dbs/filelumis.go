@@ -113,7 +113,7 @@ func (a *API) FileLumis() error {
 	}
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query filelumis", "dbs.filelumis.FileLumis")
 	}
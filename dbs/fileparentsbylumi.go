@@ -1,8 +1,14 @@
 package dbs
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"sort"
 	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
 )
 
 // FileParentsByLumi DBS API
@@ -52,14 +58,127 @@ func (a *API) FileParentsByLumi() error {
 	}
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query file parents by lumi", "dbs.fileparentsbylumi.FileParentsByLumi")
 	}
 	return nil
 }
 
-// InsertFileParentsByLumi DBS API
+// FileParentsByLumiRecord represents input record for InsertFileParentsByLumi API
+// BlockName: name of the child block
+// ParentDataset: name of the parent dataset
+// DryRun: if set, API returns proposed child/parent pairs without inserting them
+type FileParentsByLumiRecord struct {
+	BlockName     string `json:"block_name"`
+	ParentDataset string `json:"parent_dataset"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+// InsertFileParentsByLumi DBS API derives file parentage of given child block
+// from files of given parent dataset which have overlapping run/lumi pairs
+//
+//gocyclo:ignore
 func (a *API) InsertFileParentsByLumi() error {
+	// read given input
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		log.Println("fail to read data", err)
+		return Error(err, ReaderErrorCode, "unable to read file parents by lumi record", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	var rec FileParentsByLumiRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		log.Println("fail to decode data as FileParentsByLumiRecord", err)
+		return Error(err, UnmarshalErrorCode, "unable to decode file parents by lumi record", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	if utils.VERBOSE > 1 {
+		log.Printf("Insert FileParentsByLumi record %+v", rec)
+	}
+	if rec.BlockName == "" || rec.ParentDataset == "" {
+		msg := "InsertFileParentsByLumi API requires block_name and parent_dataset"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+	tmpl["ChildLfnList"] = false
+	tmpl["ParentDataset"] = true
+	stm, err := LoadTemplateSQL("fileparentsbylumi", tmpl)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load fileparentsbylumi sql template", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	stm = CleanStatement(stm)
+	args := []interface{}{rec.ParentDataset, rec.BlockName}
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "transaction error", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	defer tx.Rollback()
+
+	// find child and parent file ids with overlapping run/lumi pairs
+	rows, err := tx.Query(stm, args...)
+	if err != nil {
+		msg := fmt.Sprintf("unable to query statement:\n%v\nerror=%v", stm, err)
+		log.Println(msg)
+		return Error(err, QueryErrorCode, "unable to query file parents by lumi", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	var records []FileParents
+	for rows.Next() {
+		var r FileParents
+		if err := rows.Scan(&r.THIS_FILE_ID, &r.PARENT_FILE_ID); err != nil {
+			rows.Close()
+			log.Println("fail to get row.Scan, error", err)
+			return Error(err, RowsScanErrorCode, "unable to scan file parents by lumi", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+		}
+		records = append(records, r)
+	}
+	rows.Close()
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].THIS_FILE_ID == records[j].THIS_FILE_ID {
+			return records[i].PARENT_FILE_ID < records[j].PARENT_FILE_ID
+		}
+		return records[i].THIS_FILE_ID < records[j].THIS_FILE_ID
+	})
+
+	// in dry-run mode we only return proposed child/parent pairs
+	if rec.DryRun {
+		if records == nil {
+			records = []FileParents{}
+		}
+		data, err := json.Marshal(records)
+		if err != nil {
+			return Error(err, MarshalErrorCode, "unable to marshal file parents", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+		}
+		if a.Writer != nil {
+			a.Writer.Write(data)
+		}
+		return nil
+	}
+
+	for _, r := range records {
+		err = r.Insert(tx)
+		if err != nil {
+			if utils.VERBOSE > 1 {
+				log.Println("unable to insert FileParents record, error", err)
+			}
+			return Error(err, InsertFileParentErrorCode, "unable to insert file parent record", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+		}
+	}
+
+	// commit transaction
+	err = tx.Commit()
+	if err != nil {
+		log.Println("fail to commit transaction", err)
+		return Error(err, CommitErrorCode, "unable to commit insert of file parents by lumi", "dbs.fileparentsbylumi.InsertFileParentsByLumi")
+	}
+	if a.Writer != nil {
+		a.Writer.Write([]byte(`[]`))
+	}
 	return nil
 }
@@ -106,6 +106,49 @@ func (a *API) Files() error {
 	}
 	conds, args = AddParam("origin_site_name", "B.ORIGIN_SITE_NAME", a.Params, conds, args)
 
+	// project output on requested fields and skip joins which are not
+	// required by either requested columns or where clause conditions,
+	// the sumOverLumi query requires full set of columns of files query
+	fields := a.fields()
+	if len(fields) > 0 {
+		known := columnNames(filesColumns)
+		if tmpl["RunNumber"].(bool) {
+			known = append(known, "run_num")
+		}
+		if err := checkFields(fields, known); err != nil {
+			return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.files.Files")
+		}
+		if sumOverLumi == "1" {
+			tmpl["Detail"] = true
+		} else {
+			var sfields []string
+			for _, f := range fields {
+				if f != "run_num" {
+					sfields = append(sfields, f)
+				}
+			}
+			if len(sfields) == 0 {
+				sfields = []string{"logical_file_name"}
+			}
+			columns, _, _, aliases, err := selectColumns(sfields, filesColumns)
+			if err != nil {
+				return Error(err, InvalidParameterErrorCode, "invalid fields parameter", "dbs.files.Files")
+			}
+			tmpl["Columns"] = columns
+			// dataset access types are joined via datasets table
+			if aliasUsed("DT", conds) {
+				aliases = append(aliases, "D")
+			}
+			skip := map[string]string{
+				"SkipFileType":   "FT",
+				"SkipDataset":    "D",
+				"SkipBlock":      "B",
+				"SkipAccessType": "DT",
+			}
+			skipJoins(tmpl, skip, aliases, conds)
+		}
+	}
+
 	// load our SQL statement
 	stm, err := LoadTemplateSQL("files", tmpl)
 	if err != nil {
@@ -222,7 +265,7 @@ func (a *API) Files() error {
 	}
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "query error", "dbs.files.Files")
 	}
@@ -641,6 +684,15 @@ func (a *API) UpdateFiles() error {
 			createBy = t[0]
 		}
 	}
+
+	// list of files, block or run/lumi selection is updated in chunks
+	lfns := getValues(a.Params, "logical_file_name")
+	_, blockSelection := a.Params["block_name"]
+	_, runSelection := a.Params["run_num"]
+	if len(lfns) > 1 || blockSelection || runSelection {
+		return a.updateFileList(lfns, createBy, isFileValid)
+	}
+
 	tstamp := time.Now().Unix()
 	// keep that order since it is present in sql statement
 	args = append(args, createBy)
@@ -648,7 +700,6 @@ func (a *API) UpdateFiles() error {
 	args = append(args, isFileValid)
 
 	// additional where clause parameters
-	lfns := getValues(a.Params, "logical_file_name")
 	if len(lfns) == 1 {
 		tmpl["Lfns"] = true
 		if strings.ToLower(DBOWNER) == "sqlite" {
@@ -704,3 +755,224 @@ func (a *API) UpdateFiles() error {
 	}
 	return nil
 }
+
+// FileUpdateChunkSize defines number of files updated by single SQL
+// statement of bulk file validity update
+var FileUpdateChunkSize = 1000
+
+// FileValidityReport represents report of bulk file validity update
+type FileValidityReport struct {
+	Matched  int64    `json:"matched"`
+	Changed  int64    `json:"changed"`
+	NotFound []string `json:"not_found"`
+}
+
+// helper function to update validity of list of files, files of a block
+// or files of a run/lumi selection within block or dataset. The files are
+// updated in chunks within single transaction and the report of update
+// is written to API writer.
+//
+//gocyclo:ignore
+func (a *API) updateFileList(lfns []string, createBy string, isFileValid int) error {
+	block, _ := getSingleValue(a.Params, "block_name")
+	dataset, _ := getSingleValue(a.Params, "dataset")
+	runs := getValues(a.Params, "run_num")
+	lumis, err := FlatLumis(getValues(a.Params, "lumi_list"))
+	if err != nil {
+		return Error(err, InvalidParameterErrorCode, "invalid lumi_list parameter", "dbs.files.updateFileList")
+	}
+	if len(lfns) > 0 && (block != "" || dataset != "" || len(runs) > 0) {
+		msg := "list of files cannot be combined with block, dataset or run selection"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.files.updateFileList")
+	}
+	if len(lfns) == 0 && block == "" && dataset == "" {
+		msg := "run selection requires either block_name or dataset"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.files.updateFileList")
+	}
+	if len(lumis) > 0 && (len(runs) != 1 || strings.Contains(runs[0], "-")) {
+		msg := "lumi_list requires single run_num value"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.files.updateFileList")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println("unable to get DB transaction", err)
+		return Error(err, TransactionErrorCode, "unable to start transaction", "dbs.files.updateFileList")
+	}
+	defer tx.Rollback()
+
+	// find files to update
+	report := FileValidityReport{NotFound: []string{}}
+	var files []string
+	if len(lfns) > 0 {
+		for _, chunk := range fileChunks(lfns, FileUpdateChunkSize) {
+			found, err := selectFiles(tx, Record{"logical_file_name": chunk})
+			if err != nil {
+				return err
+			}
+			fmap := make(map[string]bool)
+			for _, lfn := range found {
+				fmap[lfn] = true
+			}
+			for _, lfn := range chunk {
+				if !fmap[lfn] {
+					report.NotFound = append(report.NotFound, lfn)
+				}
+			}
+			files = append(files, found...)
+		}
+	} else {
+		params := Record{"block_name": block, "dataset": dataset, "run_num": runs, "lumi_list": lumis}
+		files, err = selectFiles(tx, params)
+		if err != nil {
+			return err
+		}
+	}
+	report.Matched = int64(len(files))
+
+	// update files in chunks, files which already have requested validity
+	// are not updated
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+	tmpl["SQLite"] = DBOWNER == "sqlite"
+	tstamp := time.Now().Unix()
+	for _, chunk := range fileChunks(files, FileUpdateChunkSize) {
+		token, binds := TokenGenerator(chunk, 30, "lfn_token")
+		tmpl["TokenGenerator"] = token
+		stm, err := LoadTemplateSQL("update_files_list", tmpl)
+		if err != nil {
+			return Error(err, LoadErrorCode, "fail to load update_files_list sql template", "dbs.files.updateFileList")
+		}
+		stm = CleanStatement(stm)
+		args := []interface{}{createBy, tstamp, isFileValid, isFileValid}
+		for _, v := range binds {
+			args = append(args, v)
+		}
+		if utils.VERBOSE > 1 {
+			utils.PrintSQL(stm, args, "execute")
+		}
+		res, err := tx.Exec(stm, args...)
+		if err != nil {
+			return Error(err, UpdateFileErrorCode, "unable to update file records", "dbs.files.updateFileList")
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			report.Changed += n
+		}
+	}
+
+	// commit transaction
+	err = tx.Commit()
+	if err != nil {
+		log.Println("unable to commit transaction", err)
+		return Error(err, UpdateFileErrorCode, "unable to commit update file records", "dbs.files.updateFileList")
+	}
+	if a.Writer != nil {
+		data, err := json.Marshal([]FileValidityReport{report})
+		if err != nil {
+			return Error(err, MarshalErrorCode, "unable to encode file validity report", "dbs.files.updateFileList")
+		}
+		a.Writer.Write(data)
+	}
+	return nil
+}
+
+// helper function to split list of files into chunks of given size
+func fileChunks(files []string, size int) [][]string {
+	var chunks [][]string
+	for len(files) > size {
+		chunks = append(chunks, files[:size])
+		files = files[size:]
+	}
+	if len(files) > 0 {
+		chunks = append(chunks, files)
+	}
+	return chunks
+}
+
+// helper function to select logical file names of given list of files,
+// block or dataset with optional run/lumi selection
+func selectFiles(tx *sql.Tx, params Record) ([]string, error) {
+	var args []interface{}
+	var conds []string
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+	tmpl["TokenGenerator"] = ""
+	tmpl["Block"] = false
+	tmpl["Dataset"] = false
+	tmpl["Lumis"] = false
+
+	lfns := getValues(params, "logical_file_name")
+	runs := getValues(params, "run_num")
+	lumis := getValues(params, "lumi_list")
+	if len(lfns) > 0 {
+		token, binds := TokenGenerator(lfns, 30, "lfn_token")
+		tmpl["TokenGenerator"] = token
+		conds = append(conds, fmt.Sprintf("F.LOGICAL_FILE_NAME in %s", TokenCondition()))
+		for _, v := range binds {
+			args = append(args, v)
+		}
+	}
+	if block, _ := getSingleValue(params, "block_name"); block != "" {
+		tmpl["Block"] = true
+		conds = append(conds, "B.BLOCK_NAME = :block_name")
+		args = append(args, block)
+	}
+	if dataset, _ := getSingleValue(params, "dataset"); dataset != "" {
+		tmpl["Dataset"] = true
+		conds = append(conds, "D.DATASET = :dataset")
+		args = append(args, dataset)
+	}
+	if len(runs) > 0 {
+		tmpl["Lumis"] = true
+		token, rconds, rargs, err := RunsConditions(runs, "FL")
+		if err != nil {
+			return nil, Error(err, InvalidParameterErrorCode, "invalid run_num parameter", "dbs.files.selectFiles")
+		}
+		if token != "" {
+			// token comes first in SQL statement, therefore its binds too
+			tmpl["TokenGenerator"] = token
+			args = append(rargs, args...)
+		} else {
+			args = append(args, rargs...)
+		}
+		conds = append(conds, rconds...)
+	}
+	if len(lumis) > 0 {
+		// lumis are allowed for single run only, i.e. without run token
+		token, binds := TokenGenerator(lumis, 4000, "lumis_token")
+		tmpl["TokenGenerator"] = token
+		conds = append(conds, fmt.Sprintf("FL.LUMI_SECTION_NUM in %s", TokenCondition()))
+		var newArgs []interface{}
+		for _, v := range binds {
+			newArgs = append(newArgs, v)
+		}
+		args = append(newArgs, args...)
+	}
+
+	stm, err := LoadTemplateSQL("update_files_select", tmpl)
+	if err != nil {
+		return nil, Error(err, LoadErrorCode, "fail to load update_files_select sql template", "dbs.files.selectFiles")
+	}
+	stm = CleanStatement(WhereClause(stm, conds))
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	rows, err := tx.Query(stm, args...)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "unable to select files", "dbs.files.selectFiles")
+	}
+	defer rows.Close()
+	var files []string
+	for rows.Next() {
+		var lfn string
+		if err := rows.Scan(&lfn); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "unable to scan file record", "dbs.files.selectFiles")
+		}
+		files = append(files, lfn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error(err, RowsScanErrorCode, "unable to read file records", "dbs.files.selectFiles")
+	}
+	return files, nil
+}
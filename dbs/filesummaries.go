@@ -78,7 +78,7 @@ func (a *API) FileSummaries() error {
 	stm = strings.Replace(stm, "wheresql_isFileValid", wheresqlIsFileValid, -1)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "fail to query file summaries", "dbs.filesummaries.FileSummaries")
 	}
@@ -0,0 +1,184 @@
+package dbs
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheckTimeout defines timeout in seconds of health check DB queries
+var HealthCheckTimeout int
+
+// migrationHeartbeat holds unix time of last migration daemon loop iteration
+var migrationHeartbeat int64
+
+// HealthCheck represents status of single server dependency
+type HealthCheck struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Latency float64                `json:"latency_ms,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// health check status values
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+	HealthSkip = "skip"
+)
+
+// MigrationHeartbeat updates heartbeat of migration daemon
+func MigrationHeartbeat() {
+	atomic.StoreInt64(&migrationHeartbeat, time.Now().Unix())
+}
+
+// LastMigrationHeartbeat returns time of last migration daemon heartbeat
+func LastMigrationHeartbeat() time.Time {
+	return time.Unix(atomic.LoadInt64(&migrationHeartbeat), 0)
+}
+
+// helper function to get health check context
+func healthContext() (context.Context, context.CancelFunc) {
+	timeout := HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = 5 // by default DB should respond within 5 seconds
+	}
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// CheckDB checks connectivity and latency of given database
+func CheckDB(name string, db *sql.DB) HealthCheck {
+	check := HealthCheck{Name: name, Status: HealthOK}
+	if db == nil {
+		check.Status = HealthFail
+		check.Error = "database access is closed"
+		return check
+	}
+	ctx, cancel := healthContext()
+	defer cancel()
+	time0 := time.Now()
+	err := db.PingContext(ctx)
+	if err == nil {
+		stm := "SELECT 1 FROM DUAL"
+		if DBTYPE == "sqlite3" {
+			// sqlite does not have DUAL table
+			stm = "SELECT 1"
+		}
+		var value int
+		err = db.QueryRowContext(ctx, stm).Scan(&value)
+	}
+	check.Latency = float64(time.Since(time0).Microseconds()) / 1000
+	if err != nil {
+		check.Status = HealthFail
+		check.Error = err.Error()
+	}
+	stats := db.Stats()
+	check.Details = map[string]interface{}{
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+	}
+	return check
+}
+
+// CheckTemplates checks that SQL templates and lexicon patterns are loaded
+func CheckTemplates() HealthCheck {
+	check := HealthCheck{Name: "templates", Status: HealthOK}
+	check.Details = map[string]interface{}{
+		"sql_templates":    len(DBSQL),
+		"lexicon_patterns": len(LexiconPatterns),
+	}
+	if len(DBSQL) == 0 {
+		check.Status = HealthFail
+		check.Error = "SQL templates are not loaded"
+	} else if len(LexiconPatterns) == 0 {
+		check.Status = HealthFail
+		check.Error = "lexicon patterns are not loaded"
+	}
+	return check
+}
+
+// CheckTLSProxy checks freshness of X509 proxy used to access remote DBS
+// servers. The check is skipped if server did not load X509 proxy yet.
+func CheckTLSProxy() HealthCheck {
+	check := HealthCheck{Name: "tls_proxy", Status: HealthOK}
+	certs, loaded := tlsManager.Loaded()
+	if len(certs) == 0 {
+		// certificates are loaded lazily at first remote call and they are
+		// not used at all if X509 proxy or user certificates are not present
+		check.Status = HealthSkip
+		check.Details = map[string]interface{}{"loaded": false}
+		return check
+	}
+	age := time.Since(loaded).Seconds()
+	check.Details = map[string]interface{}{
+		"loaded":           true,
+		"age":              int64(age),
+		"refresh_interval": TlsRefreshInterval,
+	}
+	cert := certs[0]
+	if len(cert.Certificate) > 0 {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			check.Details["expires"] = leaf.NotAfter.Unix()
+			if time.Now().After(leaf.NotAfter) {
+				check.Status = HealthFail
+				check.Error = fmt.Sprintf("X509 proxy expired at %v", leaf.NotAfter)
+				return check
+			}
+		}
+	}
+	if TlsRefreshInterval > 0 && age > float64(2*TlsRefreshInterval) {
+		check.Status = HealthFail
+		check.Error = fmt.Sprintf("X509 proxy is not refreshed for %d seconds", int64(age))
+	}
+	return check
+}
+
+// CheckMigrationDaemon checks heartbeat of migration daemon. The daemon
+// is considered stuck if it did not report its heartbeat within its
+// interval and process timeout.
+func CheckMigrationDaemon() HealthCheck {
+	check := HealthCheck{Name: "migration_daemon", Status: HealthOK}
+	last := LastMigrationHeartbeat()
+	threshold := time.Duration(MigrationServerInterval+MigrationProcessTimeout+60) * time.Second
+	check.Details = map[string]interface{}{
+		"last_heartbeat": last.Unix(),
+		"threshold":      int64(threshold.Seconds()),
+	}
+	if atomic.LoadInt64(&migrationHeartbeat) == 0 {
+		check.Status = HealthFail
+		check.Error = "migration daemon is not started"
+	} else if time.Since(last) > threshold {
+		check.Status = HealthFail
+		check.Error = fmt.Sprintf("no migration daemon heartbeat since %v", last)
+	}
+	return check
+}
+
+// CheckDrain checks if server is draining
+func CheckDrain() HealthCheck {
+	check := HealthCheck{Name: "drain", Status: HealthOK}
+	check.Details = map[string]interface{}{
+		"draining":  Draining(),
+		"in_flight": InFlight(),
+	}
+	if Draining() {
+		check.Status = HealthFail
+		check.Error = DrainErr.Error()
+	}
+	return check
+}
+
+// HealthStatus returns overall status of given health checks
+func HealthStatus(checks []HealthCheck) error {
+	for _, c := range checks {
+		if c.Status == HealthFail {
+			return fmt.Errorf("%s check failed: %s", c.Name, c.Error)
+		}
+	}
+	return nil
+}
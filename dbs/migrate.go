@@ -23,8 +23,6 @@ import (
 	"io"
 	"log"
 	"net/url"
-	"os"
-	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
@@ -61,7 +59,10 @@ DBS migration status codes (in sync with old python migration server):
 		IN PROGRESS -> EXIST_IN_DB (1 -> 4)
         IN PROGRESS -> (Terminally FAILED) (1 -> 9)
         are only allowed changes for working through migration.
-        FAILED -> IN PROGRESS (3 -> 1) is allowed for retrying and retry count +1.
+        FAILED -> IN PROGRESS (3 -> 1) is allowed for retrying and retry count +1,
+        once NEXT_RETRY_DATE (exponential backoff) is reached.
+        Permanent failures (validation, lexicon, missing parents) go directly
+        to Terminally FAILED (1 -> 9).
 */
 
 // TotalPending represents total number pending migration requests
@@ -113,29 +114,30 @@ type MigrationReport struct {
 // GetBlocks returns list of blocks for a given url and block/dataset input
 func GetBlocks(rurl, val string) ([]string, error) {
 	var out []string
-	open := "&open_for_writing=0"
+	params := url.Values{"open_for_writing": []string{"0"}}
 	if strings.Contains(val, "#") {
-		rurl = fmt.Sprintf("%s/blocks?block_name=%s%s", rurl, url.QueryEscape(val), open)
+		params.Set("block_name", val)
 	} else {
-		rurl = fmt.Sprintf("%s/blocks?dataset=%s%s", rurl, val, open)
+		params.Set("dataset", val)
 	}
-	data, err := getData(rurl)
+	remote, err := remoteDBS(rurl)
+	if err != nil {
+		return out, err
+	}
+	records, err := remote.Blocks(params)
 	if utils.VERBOSE > 0 {
-		log.Println("GetBlocks", rurl, string(data))
+		log.Println("GetBlocks", rurl, params, records)
 	}
 	if err != nil {
 		if utils.VERBOSE > 0 {
-			log.Printf("unable to get data for %s, error %v", rurl, err)
+			log.Printf("unable to get blocks from %s for %s, error %v", rurl, val, err)
 		}
 		return out, Error(err, HttpRequestErrorCode, "", "dbs.migrate.GetBlocks")
 	}
-	var rec []Blocks
-	err = json.Unmarshal(data, &rec)
-	if err != nil {
-		return out, Error(err, UnmarshalErrorCode, "", "dbs.migrate.GetBlocks")
-	}
-	for _, v := range rec {
-		out = append(out, v.BLOCK_NAME)
+	for _, v := range records {
+		if blk, ok := v["block_name"].(string); ok {
+			out = append(out, blk)
+		}
 	}
 	return out, nil
 }
@@ -143,29 +145,23 @@ func GetBlocks(rurl, val string) ([]string, error) {
 // GetParents returns list of parents for given block or dataset
 func GetParents(rurl, val string) ([]string, error) {
 	var out []string
+	remote, err := remoteDBS(rurl)
+	if err != nil {
+		return out, err
+	}
+	var records []Record
+	key := "parent_dataset"
 	if strings.Contains(val, "#") {
-		rurl = fmt.Sprintf("%s/blockparents?block_name=%s", rurl, url.QueryEscape(val))
+		key = "parent_block_name"
+		records, err = remote.BlockParents(url.Values{"block_name": []string{val}})
 	} else {
-		rurl = fmt.Sprintf("%s/datasetparents?dataset=%s", rurl, val)
+		records, err = remote.DatasetParents(url.Values{"dataset": []string{val}})
 	}
-	data, err := getData(rurl)
 	if err != nil {
 		return out, Error(err, HttpRequestErrorCode, "", "dbs.migrate.GetParents")
 	}
-	var rec []map[string]interface{}
-	err = json.Unmarshal(data, &rec)
-	if err != nil {
-		log.Printf("unable to unmarshal data url=%s data=%s error=%v", rurl, string(data), err)
-		return out, Error(err, UnmarshalErrorCode, "", "dbs.migrate.GetParents")
-	}
-	for _, v := range rec {
-		if strings.Contains(val, "#") {
-			block := fmt.Sprintf("%v", v["parent_block_name"])
-			out = append(out, block)
-		} else {
-			dataset := fmt.Sprintf("%v", v["parent_dataset"])
-			out = append(out, dataset)
-		}
+	for _, v := range records {
+		out = append(out, fmt.Sprintf("%v", v[key]))
 	}
 	return out, nil
 }
@@ -243,7 +239,9 @@ func prepareMigrationList(rurl, input string) []string {
 	return pblocks
 }
 
-// helper function to check blocks in local DB
+// helper function to check blocks in local DB, it returns blocks which
+// exist in local DB. GetID fails for missing blocks, therefore existing
+// blocks are the ones with non-zero id.
 func blocksInDB(blocks []string) ([]string, error) {
 	if len(blocks) == 0 {
 		return blocks, nil
@@ -256,7 +254,7 @@ func blocksInDB(blocks []string) ([]string, error) {
 	}
 	defer tx.Rollback()
 	for _, blk := range blocks {
-		if rid, err := GetID(tx, "BLOCKS", "block_id", "block_name", blk); err == nil && rid == 0 {
+		if rid, err := GetID(tx, "BLOCKS", "block_id", "block_name", blk); err == nil && rid > 0 {
 			srcBlocks = append(srcBlocks, blk)
 		}
 	}
@@ -597,28 +595,31 @@ type DatasetShortRecord struct {
 func validInput(rurl, input string) error {
 	arr := strings.Split(input, "#")
 	dataset := arr[0]
-	rurl = fmt.Sprintf("%s/datasets?dataset=%s&detail=true&dataset_access_type=*", rurl, dataset)
-	data, err := getData(rurl)
+	remote, err := remoteDBS(rurl)
+	if err != nil {
+		return err
+	}
+	params := url.Values{
+		"dataset":             []string{dataset},
+		"detail":              []string{"true"},
+		"dataset_access_type": []string{"*"},
+	}
+	records, err := remote.Datasets(params)
 	if utils.VERBOSE > 0 {
-		log.Println("validInput", rurl, string(data))
+		log.Println("validInput", rurl, params, records)
 	}
 	if err != nil {
-		msg := fmt.Sprintf("unable to get data for %s", rurl)
+		msg := fmt.Sprintf("unable to get dataset %s from %s", dataset, rurl)
 		if utils.VERBOSE > 0 {
 			log.Printf("%s, error %v", msg, err)
 		}
 		return Error(err, HttpRequestErrorCode, msg, "dbs.migrate.validInput")
 	}
-	var records []Dataset
-	err = json.Unmarshal(data, &records)
-	if err != nil {
-		return Error(err, UnmarshalErrorCode, "unable to decode dataset records", "dbs.migrate.validInput")
-	}
 	if len(records) != 1 {
 		return Error(err, GenericErrorCode, "number of dataset records is not equal to 1", "dbs.migrate.validInput")
 	}
 	rec := records[0]
-	dtype := rec.DatasetAccessType
+	dtype := rec.DATASET_ACCESS_TYPE
 	if dtype == "VALID" {
 		return nil
 	}
@@ -671,6 +672,14 @@ func (a *API) SubmitMigration() error {
 		return Error(err, UnmarshalErrorCode, "unable to decode migration record", "dbs.migrate.SubmitMigration")
 	}
 	log.Println("submit migration request ", string(data))
+	if rec.PRIORITY < 0 || rec.PRIORITY > MaxMigrationPriority {
+		msg := fmt.Sprintf("invalid migration priority %d, allowed values are 0-%d", rec.PRIORITY, MaxMigrationPriority)
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migrate.SubmitMigration")
+	}
+	if rec.CALLBACK_URL != "" && !allowedCallbackURL(rec.CALLBACK_URL) {
+		msg := fmt.Sprintf("invalid callback url %s, it should match one of allowed callback urls", rec.CALLBACK_URL)
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migrate.SubmitMigration")
+	}
 	// check if migration input is already queued
 	input := rec.MIGRATION_INPUT
 	mid := rec.MIGRATION_REQUEST_ID
@@ -709,6 +718,11 @@ func (a *API) SubmitMigration() error {
 		}
 	}
 
+	// notify subscribers about new migration request
+	if err == nil {
+		notifyMigrationEvent(rec, -1, QUEUED, 0)
+	}
+
 	// start migration request
 	go StartMigrationRequest(rec)
 
@@ -728,6 +742,22 @@ func (a *API) SubmitMigration() error {
 // the code is based on the following example:
 // https://medium.com/geekculture/timeout-context-in-go-e88af0abd08d
 func StartMigrationRequest(rec MigrationRequest) {
+	// keep track of migration request such that server drain waits for it
+	done, err := TrackMigration(rec)
+	if err != nil {
+		log.Printf("skip migration request %d, error %v", rec.MIGRATION_REQUEST_ID, err)
+		return
+	}
+	// acquire lease of migration request such that only one migration
+	// server will process it
+	lease, err := ClaimMigrationRequest(rec.MIGRATION_REQUEST_ID)
+	if err != nil {
+		done()
+		log.Printf("skip migration request %d, error %v", rec.MIGRATION_REQUEST_ID, err)
+		return
+	}
+	lease.Heartbeat()
+
 	// setup context with timeout
 	ctx, cancel := context.WithTimeout(
 		context.Background(),
@@ -735,6 +765,9 @@ func StartMigrationRequest(rec MigrationRequest) {
 	defer cancel()
 	ch := make(chan string, 1)
 	go func(ctx context.Context, ch chan string) {
+		// the lease is kept until migration request is processed
+		defer done()
+		defer lease.Release()
 		reports, err := startMigrationRequest(rec)
 		if err != nil {
 			ch <- fmt.Sprintf("fail to start migration request %v, error %v", rec, err)
@@ -754,23 +787,11 @@ func StartMigrationRequest(rec MigrationRequest) {
 	}
 }
 
-// helper function to start migration request and return list of migration ids
-//
-//gocyclo:ignore
-func startMigrationRequest(req MigrationRequest) ([]MigrationReport, error) {
-	var err error
-	status := int64(PENDING)
-	msg := "Migration request is started"
-	var reports []MigrationReport
-
-	input := req.MIGRATION_INPUT
-	mstr := fmt.Sprintf("Migration request for %+v", input)
-	if utils.VERBOSE > 0 {
-		log.Printf("%s %+v", mstr, req)
-	}
-
+// helper function to get list of blocks required for migration of given input,
+// i.e. blocks (parents first) which exist at remote DBS and missing in local
+// one. It also returns list of blocks which are already present locally.
+func migrationBlockList(rurl, input string) ([]string, []string, error) {
 	var dstParentBlocks, srcParentBlocks []string
-	rurl := req.MIGRATION_URL
 	localhost := fmt.Sprintf("%s%s", utils.Localhost, utils.BASE)
 	// get parent blocks at destination DBS instance for given input
 	time0 := time.Now()
@@ -806,10 +827,7 @@ func startMigrationRequest(req MigrationRequest) ([]MigrationReport, error) {
 	if !strings.Contains(input, "#") {
 		blocks, err := GetBlocks(rurl, input)
 		if err != nil {
-			msg = fmt.Sprintf("unable to get blocks for dataset %s", input)
-			log.Println(msg)
-			return []MigrationReport{migrationReport(req, msg, status, err)},
-				Error(err, DatabaseErrorCode, msg, "dbs.migrate.startMigrationRequest")
+			return migBlocks, srcParentBlocks, err
 		}
 		for _, blk := range blocks {
 			if !utils.InList(blk, srcParentBlocks) && !utils.InList(blk, migBlocks) {
@@ -822,6 +840,33 @@ func startMigrationRequest(req MigrationRequest) ([]MigrationReport, error) {
 		}
 	}
 
+	return migBlocks, srcParentBlocks, nil
+}
+
+// helper function to start migration request and return list of migration ids
+//
+//gocyclo:ignore
+func startMigrationRequest(req MigrationRequest) ([]MigrationReport, error) {
+	var err error
+	status := int64(PENDING)
+	msg := "Migration request is started"
+	var reports []MigrationReport
+
+	input := req.MIGRATION_INPUT
+	mstr := fmt.Sprintf("Migration request for %+v", input)
+	if utils.VERBOSE > 0 {
+		log.Printf("%s %+v", mstr, req)
+	}
+
+	rurl := req.MIGRATION_URL
+	migBlocks, _, err := migrationBlockList(rurl, input)
+	if err != nil {
+		msg = fmt.Sprintf("unable to get blocks for dataset %s", input)
+		log.Println(msg)
+		return []MigrationReport{migrationReport(req, msg, status, err)},
+			Error(err, DatabaseErrorCode, msg, "dbs.migrate.startMigrationRequest")
+	}
+
 	// if no migration blocks found to process return immediately
 	if len(migBlocks) == 0 {
 		status = int64(EXIST_IN_DB)
@@ -907,7 +952,8 @@ func startMigrationRequest(req MigrationRequest) ([]MigrationReport, error) {
 			CREATE_BY:              rec.CREATE_BY,
 			CREATION_DATE:          rec.CREATION_DATE,
 			LAST_MODIFICATION_DATE: rec.LAST_MODIFICATION_DATE,
-			LAST_MODIFIED_BY:       rec.LAST_MODIFIED_BY}
+			LAST_MODIFIED_BY:       rec.LAST_MODIFIED_BY,
+			ORIGIN_REQUEST_ID:      req.MIGRATION_REQUEST_ID}
 		if utils.VERBOSE > 0 {
 			log.Printf("%s insert MigrationBlocks record %+v", mstr, mrec)
 		}
@@ -1005,6 +1051,23 @@ func (a *API) ProcessMigration() {
 	}
 	mrec := records[0]
 
+	// keep track of migration request such that server drain waits for it
+	done, err := TrackMigration(mrec)
+	if err != nil {
+		log.Printf("skip migration request %d, error %v", mid, err)
+		return
+	}
+	defer done()
+
+	// acquire lease of migration request and renew it while we process it
+	lease, err := ClaimMigrationRequest(mid)
+	if err != nil {
+		log.Printf("skip migration request %d, error %v", mid, err)
+		return
+	}
+	lease.Heartbeat()
+	defer lease.Release()
+
 	// update migration status
 	updateMigrationStatus(mrec, IN_PROGRESS)
 
@@ -1025,6 +1088,20 @@ func (a *API) ProcessMigration() {
 		log.Printf("query='%s' args='%v' error=%v", stm, args, err)
 		return
 	}
+
+	// keep track of migration block processing, its outcome and failure
+	// reason are persisted in MIGRATION_BLOCKS table, while failed migration
+	// request is either retried with backoff or terminated
+	var migErr error
+	var fileCount, blockSize int64
+	startMigrationBlock(mid)
+	defer func() {
+		if status == FAILED {
+			status, migErr = failMigrationRequest(mrec, migErr)
+		}
+		endMigrationBlock(mid, status, fileCount, blockSize, migErr)
+	}()
+
 	// if status of migration block is completed then we update status of migration request
 	if bid != 0 && bStatus == int64(COMPLETED) && migInput == mrec.MIGRATION_INPUT {
 		log.Printf("migration request %+v has block id=%d, status=%d input=%s is marked as completed", mrec, bid, bStatus, migInput)
@@ -1046,13 +1123,14 @@ func (a *API) ProcessMigration() {
 					return
 				}
 			}
+			migErr = fmt.Errorf("no blocks of dataset %s found in %s", migInput, localhost)
 		} else {
 			if utils.VERBOSE > 0 {
 				log.Printf("unable to get blocks from %s for migration input %s, error %v", localhost, migInput, err)
 			}
+			migErr = fmt.Errorf("unable to get blocks from %s, error %v", localhost, err)
 		}
 		status = FAILED
-		updateMigrationStatus(mrec, FAILED)
 		return
 	}
 
@@ -1060,80 +1138,33 @@ func (a *API) ProcessMigration() {
 	block := migInput
 
 	// obtain block details from destination DBS
-	rurl := fmt.Sprintf("%s/blockdump?block_name=%s", mrec.MIGRATION_URL, url.QueryEscape(block))
-	data, err := getData(rurl)
+	data, err := remoteBlockDump(mrec.MIGRATION_URL, block)
 	if utils.VERBOSE > 1 {
-		log.Println("place call", rurl)
+		log.Println("place blockdump call", mrec.MIGRATION_URL, block)
 		if utils.VERBOSE > 3 {
 			log.Println("receive data", string(data))
 		}
 	}
 	if err != nil {
 		if utils.VERBOSE > 1 {
-			log.Printf("unable to query %s/blockdump, error %v", rurl, err)
+			log.Printf("unable to query %s/blockdump, error %v", mrec.MIGRATION_URL, err)
 		}
+		status = FAILED
+		migErr = fmt.Errorf("unable to query %s/blockdump, error %v", mrec.MIGRATION_URL, err)
 		return
 	}
 	// NOTE: /blockdump API returns BulkBlocks record used in /bulkblocks API
-	//     var rec BlockDumpRecord
 	var brec BulkBlocks
-	err = json.Unmarshal(data, &brec)
-	if err != nil {
-		if utils.VERBOSE > 2 {
-			log.Println("blockdump data", string(data))
-		}
-		log.Printf("unable to unmarshal BulkBlocks, error %v", err)
-		return
-	}
-	cby := a.CreateBy
-	if brec.Dataset.CreateBy != "" {
-		cby = brec.Dataset.CreateBy
-	}
-	var rec Record
-	err = json.Unmarshal(data, &rec)
-	if err != nil {
-		if utils.VERBOSE > 2 {
-			log.Println("blockdump data", string(data))
-		}
-		log.Printf("unable to unmarshal Record, error %v", err)
-		return
-	}
-	reader := bytes.NewReader(data)
-	writer := utils.StdoutWriter("")
-
-	// insert block dump record into source DBS
-	//     err = rec.InsertBlockDump()
-	api := &API{
-		Params:    rec,
-		Api:       "bulkblocks",
-		Writer:    writer,
-		Reader:    reader,
-		CreateBy:  cby,
-		Separator: a.Separator,
-	}
-	if utils.VERBOSE > 2 {
-		log.Printf("Insert bulkblocks %+v, data %+v", api, string(data))
-	}
-	if ConcurrentBulkBlocks {
-		err = api.InsertBulkBlocksConcurrently()
-	} else {
-		err = api.InsertBulkBlocks()
-	}
+	status, brec, err = migrateBlockDump(data, a.CreateBy, a.Separator)
 	log.Printf("insert bulkblocks for mid %v error %v", mid, err)
-	if err != nil {
-		if utils.VERBOSE > 0 {
-			log.Println("insert block dump record failed with", err)
-		}
-		serr := fmt.Sprintf("%v", err)
-		if strings.Contains(serr, "Data already exist in DBS") {
-			status = EXIST_IN_DB
-		} else {
-			status = FAILED
-		}
-	} else {
-		status = COMPLETED
+	if status == FAILED {
+		migErr = err
+	} else if status == COMPLETED {
+		fileCount, blockSize = blockDumpCounts(brec)
+	}
+	if status != FAILED {
+		updateMigrationStatus(mrec, status)
 	}
-	updateMigrationStatus(mrec, status)
 	log.Printf("updated migration request %v with status %v", mid, status)
 }
 
@@ -1196,8 +1227,25 @@ func (a *API) ProcessMigrationCtx(timeout int) error {
 	}
 	mrec := records[0]
 
+	// keep track of migration request such that server drain waits for it
+	done, err := TrackMigration(mrec)
+	if err != nil {
+		return err
+	}
+
+	// acquire lease of migration request, it will be released by
+	// processMigration when it finishes
+	lease, err := ClaimMigrationRequest(mid)
+	if err != nil {
+		done()
+		return err
+	}
+
 	// execute slow operation in background
-	go a.processMigration(ch, &status, mrec)
+	go func() {
+		defer done()
+		a.processMigration(ch, &status, mrec, lease)
+	}()
 
 	// the slow operation will either finish or timeout
 	select {
@@ -1227,12 +1275,15 @@ func (a *API) ProcessMigrationCtx(timeout int) error {
 }
 
 // processMigration will process given migration report
-// and inject data to source DBS
-func (a *API) processMigration(ch chan<- bool, status *int64, mrec MigrationRequest) {
+// and inject data to source DBS. The migration request lease is renewed
+// while we process the request and released at the end.
+func (a *API) processMigration(ch chan<- bool, status *int64, mrec MigrationRequest, lease *MigrationLease) {
 	// report on channel that we are done with this workflow
 	defer func() {
 		ch <- true
 	}()
+	lease.Heartbeat()
+	defer lease.Release()
 
 	mid := mrec.MIGRATION_REQUEST_ID
 
@@ -1257,25 +1308,80 @@ func (a *API) processMigration(ch chan<- bool, status *int64, mrec MigrationRequ
 		return
 	}
 
+	// keep track of migration block processing, its outcome and failure
+	// reason are persisted in MIGRATION_BLOCKS table, while failed migration
+	// request is either retried with backoff or terminated
+	var migErr error
+	var fileCount, blockSize int64
+	startMigrationBlock(mid)
+	defer func() {
+		if *status == FAILED {
+			*status, migErr = failMigrationRequest(mrec, migErr)
+		}
+		endMigrationBlock(mid, *status, fileCount, blockSize, migErr)
+	}()
+
 	// obtain block details from destination DBS
-	rurl := fmt.Sprintf("%s/blockdump?block_name=%s", mrec.MIGRATION_URL, url.QueryEscape(block))
-	data, err := getData(rurl)
+	data, err := remoteBlockDump(mrec.MIGRATION_URL, block)
 	if err != nil {
 		if utils.VERBOSE > 1 {
-			log.Printf("unable to query %s/blockdump, error %v", rurl, err)
+			log.Printf("unable to query %s/blockdump, error %v", mrec.MIGRATION_URL, err)
 		}
+		migErr = fmt.Errorf("unable to query %s/blockdump, error %v", mrec.MIGRATION_URL, err)
+	}
+	if migErr != nil {
+		*status = FAILED
+		return
 	}
 	// NOTE: /blockdump API returns BulkBlocks record used in /bulkblocks API
 	var brec BulkBlocks
-	err = json.Unmarshal(data, &brec)
+	*status, brec, err = migrateBlockDump(data, a.CreateBy, a.Separator)
+	log.Printf("insert bulk blocks for mid %v error %v", mid, err)
+	if *status == FAILED {
+		migErr = err
+	} else {
+		if *status == COMPLETED {
+			fileCount, blockSize = blockDumpCounts(brec)
+		}
+		updateMigrationStatus(mrec, *status)
+	}
+	log.Printf("updated migration request %v with status %v", mid, *status)
+}
+
+// helper function to obtain block dump record of given block from remote
+// DBS, the record is returned in JSON form of /bulkblocks API input
+func remoteBlockDump(rurl, block string) ([]byte, error) {
+	remote, err := remoteDBS(rurl)
+	if err != nil {
+		return nil, err
+	}
+	brec, err := remote.BlockDump(block)
+	if err != nil {
+		msg := fmt.Sprintf("unable to get block dump of %s", block)
+		return nil, Error(err, HttpRequestErrorCode, msg, "dbs.migrate.remoteBlockDump")
+	}
+	data, err := json.Marshal(brec)
+	if err != nil {
+		msg := fmt.Sprintf("unable to encode block dump of %s", block)
+		return nil, Error(err, MarshalErrorCode, msg, "dbs.migrate.remoteBlockDump")
+	}
+	return data, nil
+}
+
+// helper function to insert block dump record obtained from remote DBS
+// into local DBS. It returns migration status of the block, i.e. COMPLETED,
+// EXIST_IN_DB or FAILED, along with its block dump record.
+func migrateBlockDump(data []byte, createBy, separator string) (int64, BulkBlocks, error) {
+	var brec BulkBlocks
+	err := json.Unmarshal(data, &brec)
 	if err != nil {
 		if utils.VERBOSE > 2 {
 			log.Println("blockdump data", string(data))
 		}
 		log.Printf("unable to unmarshal BulkBlocks, error %v", err)
-		return
+		return FAILED, brec, fmt.Errorf("unable to unmarshal blockdump data, error %v", err)
 	}
-	cby := a.CreateBy
+	cby := createBy
 	if brec.Dataset.CreateBy != "" {
 		cby = brec.Dataset.CreateBy
 	}
@@ -1286,20 +1392,19 @@ func (a *API) processMigration(ch chan<- bool, status *int64, mrec MigrationRequ
 			log.Println("blockdump data", string(data))
 		}
 		log.Printf("unable to unmarshal Record, error %v", err)
-		return
+		return FAILED, brec, fmt.Errorf("unable to unmarshal blockdump data, error %v", err)
 	}
 	reader := bytes.NewReader(data)
 	writer := utils.StdoutWriter("")
 
 	// insert block dump record into source DBS
-	//     err = rec.InsertBlockDump()
 	api := &API{
 		Params:    rec,
 		Api:       "bulkblocks",
 		Writer:    writer,
 		Reader:    reader,
 		CreateBy:  cby,
-		Separator: a.Separator,
+		Separator: separator,
 	}
 	if utils.VERBOSE > 2 {
 		log.Printf("Insert bulkblocks %+v, data %+v", api, string(data))
@@ -1309,54 +1414,16 @@ func (a *API) processMigration(ch chan<- bool, status *int64, mrec MigrationRequ
 	} else {
 		err = api.InsertBulkBlocks()
 	}
-	log.Printf("insert bulk blocks for mid %v error %v", mid, err)
 	if err != nil {
 		if utils.VERBOSE > 0 {
 			log.Println("insert block dump record failed with", err)
 		}
-		*status = FAILED
-		updateMigrationStatus(mrec, FAILED)
-	} else {
-		*status = COMPLETED
-		updateMigrationStatus(mrec, COMPLETED)
-	}
-	log.Printf("updated migration request %v with status %v", mid, *status)
-}
-
-// helper function to check host of migation request
-func migrationHost(mid int64) (string, error) {
-	// check if migration server is empty when migration status is IN_PROGRESS
-	// we use sql.NullString as migration server info may not be present in DB
-	// https://medium.com/aubergine-solutions/how-i-handled-null-possible-values-from-database-rows-in-golang-521fb0ee267
-	var msrv sql.NullString
-	stm := getSQL("check_migration_server")
-	err := DB.QueryRow(stm, mid).Scan(&msrv)
-	if err != nil {
-		msg := fmt.Sprintf("unable to query statement:\n%v\nerror=%v", stm, err)
-		log.Println(msg)
-		return "", Error(err, QueryErrorCode, "", "dbs.migrate.migrationHost")
-	}
-	migServer := msrv.String
-	hostname, err := os.Hostname()
-	if err != nil {
-		return "", Error(err, GenericErrorCode, "", "dbs.migrate.migrationHost")
-	}
-
-	// on k8s if migServer differ from hostname we need to check if such pod exists
-	out, err := exec.Command("kubectl", "get", "pods", "-n", "dbs").Output()
-	// we migration server among the pods
-	if err == nil && strings.Contains(string(out), migServer) {
-		// compare migration server to hostname
-		if migServer != "" && migServer != hostname {
-			msg := fmt.Sprintf("migration request %d is already taken by %s", mid, migServer)
-			log.Println(msg)
-			return "", Error(ConcurrencyErr, MigrationErrorCode, msg, "dbs.migrate.migrationHost")
-
+		if strings.Contains(err.Error(), "Data already exist in DBS") {
+			return EXIST_IN_DB, brec, nil
 		}
+		return FAILED, brec, err
 	}
-	// otherwise we do not have a pod with name of migration server
-	// or we do not run migration server in multi-node environment
-	return hostname, nil
+	return COMPLETED, brec, nil
 }
 
 // updateMigrationStatusMetrics updates metrics about migration statuses
@@ -1379,11 +1446,18 @@ func updateMigrationStatusMetrics(mrec MigrationRequest, status int64) {
 }
 
 // updateMigrationStatus updates migration status and increment retry count of
-// migration record.
+// migration record. The update is only allowed if migration request is not
+// leased by another migration server.
 func updateMigrationStatus(mrec MigrationRequest, status int64) error {
+	return setMigrationStatus(mrec, status, true)
+}
+
+// helper function to update migration status with or without lease check
+func setMigrationStatus(mrec MigrationRequest, status int64, checkLease bool) error {
 	log.Printf("update migration request %d to status %d", mrec.MIGRATION_REQUEST_ID, status)
 	tmplData := make(Record)
 	tmplData["Owner"] = DBOWNER
+	tmplData["Lease"] = checkLease
 	stm, err := LoadTemplateSQL("update_migration_status", tmplData)
 	if err != nil {
 		log.Println("unable to load update_migration_status template", err)
@@ -1394,11 +1468,7 @@ func updateMigrationStatus(mrec MigrationRequest, status int64) error {
 	mid := mrec.MIGRATION_REQUEST_ID
 	retryCount := mrec.RETRY_COUNT
 
-	// get migration host or fail
-	hostname, err := migrationHost(mid)
-	if err != nil {
-		return err
-	}
+	owner := MigrationOwner()
 
 	// start transaction
 	tx, err := DB.Begin()
@@ -1408,34 +1478,52 @@ func updateMigrationStatus(mrec MigrationRequest, status int64) error {
 	}
 	defer tx.Rollback()
 
+	// obtain current status of migration request to report its change
+	prevStatus := int64(-1)
+	if err := tx.QueryRow(CleanStatement(getSQL("migration_request_status")), mid).Scan(&prevStatus); err != nil {
+		log.Printf("unable to get status of migration request %d, error %v", mid, err)
+	}
+
 	// if our status is FAILED we check for retry count
-	// if retry count is less then threshold we increment retry count and set status to IN PROGRESS
-	// this will allow migration service to pick up failed migration request
+	// if retry count is less then threshold we increment retry count and set
+	// next retry date using exponential backoff, this will allow migration
+	// service to pick up failed migration request once its retry date is reached
 	// otherwise we permanently terminate the migration request and set its status to TERM_FAILED
+	var retryDate interface{}
 	if status == FAILED {
-		updateMigrationStatusMetrics(mrec, FAILED)
 		if retryCount <= MigrationRetries {
+			retryDate = nextRetryDate(retryCount)
 			retryCount += 1
-			status = IN_PROGRESS
 		} else {
+			updateMigrationStatusMetrics(mrec, FAILED)
 			status = TERM_FAILED
 		}
 	}
-	updateMigrationStatusMetrics(mrec, status)
+	var args []interface{}
+	args = append(args, status)
+	args = append(args, retryCount)
+	args = append(args, owner)
+	args = append(args, retryDate)
+	args = append(args, mid)
+	if checkLease {
+		args = append(args, owner)
+		args = append(args, time.Now().Unix())
+	}
 	if utils.VERBOSE > 0 {
-		var args []interface{}
-		args = append(args, status)
-		args = append(args, retryCount)
-		args = append(args, hostname)
-		args = append(args, mid)
 		utils.PrintSQL(stm, args, "execute update migration status query")
 	}
 
-	_, err = tx.Exec(stm, status, retryCount, hostname, mid)
+	res, err := tx.Exec(stm, args...)
 	if err != nil {
 		log.Printf("unable to execute %s, error %v", stm, err)
 		return Error(err, UpdateMigrationErrorCode, "unable to update migration status metrics", "dbs.migrate.updateMigrationStatus")
 	}
+	if nrows, err := res.RowsAffected(); err == nil && nrows == 0 {
+		msg := fmt.Sprintf("migration request %d is leased by another migration server", mid)
+		log.Println(msg)
+		return Error(ConcurrencyErr, MigrationErrorCode, msg, "dbs.migrate.updateMigrationStatus")
+	}
+	updateMigrationStatusMetrics(mrec, status)
 
 	// commit transaction
 	err = tx.Commit()
@@ -1443,6 +1531,9 @@ func updateMigrationStatus(mrec MigrationRequest, status int64) error {
 		log.Println("unable to commit transaction", err)
 		return Error(err, UpdateMigrationErrorCode, "unable to commit update of migration status metrics", "dbs.migrate.updateMigrationStatus")
 	}
+	if prevStatus != status {
+		notifyMigrationEvent(mrec, prevStatus, status, retryCount)
+	}
 	return nil
 }
 
@@ -1581,8 +1672,40 @@ func (a *API) StatusMigration() error {
 		stm += "ORDER BY MR.creation_date"
 	}
 
+	// queue position of requests waiting for processing
+	positions, err := MigrationQueuePositions()
+	if err != nil {
+		log.Println("unable to obtain migration queue positions", err)
+	}
+	// with detail flag we provide per-block progress of migration requests
+	detail, _ := getSingleValue(a.Params, "detail")
+	ext := &recordExtension{
+		Fields: []string{"queue_position", "migration_blocks", "progress"},
+		Extend: func(rec Record) {
+			// DB drivers may return different types of numeric values
+			val := fmt.Sprintf("%v", rec["migration_request_id"])
+			mid, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return
+			}
+			if pos, ok := positions[mid]; ok {
+				rec["queue_position"] = pos
+			}
+			if detail != "true" {
+				return
+			}
+			blocks, err := MigrationBlockReports(mid)
+			if err != nil {
+				log.Printf("unable to obtain migration blocks of request %d, error %v", mid, err)
+				return
+			}
+			rec["migration_blocks"] = blocks
+			rec["progress"] = MigrationProgressSummary(blocks)
+		},
+	}
+
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = executeAllWith(a.Writer, a.Separator, a.fields(), ext, stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "fail to query migration requests", "dbs.migrate.StatusMigration")
 	}
@@ -1596,7 +1719,7 @@ func (a *API) TotalMigration() error {
 	stm := getSQL("migration_total_count")
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "fail to query migration total count", "dbs.migrate.TotalMigration")
 	}
@@ -1644,7 +1767,8 @@ func (a *API) CancelMigration() error {
 	}
 	mrec := records[0]
 	log.Printf("CancelMigration request %+v, status %v (TERM_FAILED)", mrec, TERM_FAILED)
-	updateMigrationStatus(mrec, TERM_FAILED)
+	// cancellation is allowed regardless of migration request lease
+	setMigrationStatus(mrec, TERM_FAILED, false)
 	return nil
 }
 
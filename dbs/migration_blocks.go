@@ -21,6 +21,7 @@ type MigrationBlocks struct {
 	CREATION_DATE          int64  `json:"creation_date" validate:"required,number,gt=0"`
 	LAST_MODIFIED_BY       string `json:"last_modified_by" validate:"required"`
 	LAST_MODIFICATION_DATE int64  `json:"last_modification_date" validate:"required,number,gt=0"`
+	ORIGIN_REQUEST_ID      int64  `json:"origin_request_id"`
 }
 
 // Insert implementation of MigrationBlocks
@@ -60,6 +61,7 @@ func (r *MigrationBlocks) Insert(tx *sql.Tx) error {
 		args = append(args, r.CREATE_BY)
 		args = append(args, r.LAST_MODIFICATION_DATE)
 		args = append(args, r.LAST_MODIFIED_BY)
+		args = append(args, r.ORIGIN_REQUEST_ID)
 		utils.PrintSQL(stm, args, "execute")
 	}
 	_, err = tx.Exec(stm,
@@ -71,7 +73,8 @@ func (r *MigrationBlocks) Insert(tx *sql.Tx) error {
 		r.CREATION_DATE,
 		r.CREATE_BY,
 		r.LAST_MODIFICATION_DATE,
-		r.LAST_MODIFIED_BY)
+		r.LAST_MODIFIED_BY,
+		r.ORIGIN_REQUEST_ID)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "unique") {
 			// if we try to insert the same migration input we'll continue
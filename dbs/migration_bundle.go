@@ -0,0 +1,199 @@
+package dbs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MigrationBundleVersion defines version of migration bundle format
+const MigrationBundleVersion = 1
+
+// MigrationBundleHeader represents first record of migration bundle.
+// The migration bundle is gzip compressed stream of new line delimited JSON
+// records, where header record is followed by /blockdump records of all
+// blocks listed in the header, in order of their migration (parents first).
+type MigrationBundleHeader struct {
+	Version        int      `json:"bundle_version"`
+	MigrationURL   string   `json:"migration_url"`
+	MigrationInput string   `json:"migration_input"`
+	Blocks         []string `json:"blocks"`
+	CreationDate   int64    `json:"creation_date"`
+}
+
+// MigrationBundleReport represents import report of single bundle block
+type MigrationBundleReport struct {
+	Block  string `json:"block_name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MigrationBundleBlocks returns ordered list of blocks (parents first) of
+// given dataset or block at remote DBS, i.e. the same list of blocks live
+// migration would process without checking their presence in local DBS
+func MigrationBundleBlocks(rurl, input string) ([]string, error) {
+	blocks := prepareMigrationList(rurl, input)
+	if !strings.Contains(input, "#") {
+		dblocks, err := GetBlocks(rurl, input)
+		if err != nil {
+			msg := fmt.Sprintf("unable to get blocks for dataset %s", input)
+			return blocks, Error(err, HttpRequestErrorCode, msg, "dbs.migration_bundle.MigrationBundleBlocks")
+		}
+		blocks = append(blocks, dblocks...)
+	} else {
+		blocks = append(blocks, input)
+	}
+	blocks = utils.Set(blocks)
+	if len(blocks) == 0 {
+		msg := fmt.Sprintf("no blocks found for %s at %s", input, rurl)
+		return blocks, Error(GenericErr, MigrationErrorCode, msg, "dbs.migration_bundle.MigrationBundleBlocks")
+	}
+	return blocks, nil
+}
+
+// ExportMigrationBundle writes migration bundle of given dataset or block
+// obtained from remote DBS into provided writer
+func ExportMigrationBundle(rurl, input string, w io.Writer) (MigrationBundleHeader, error) {
+	header := MigrationBundleHeader{
+		Version:        MigrationBundleVersion,
+		MigrationURL:   rurl,
+		MigrationInput: input,
+		CreationDate:   time.Now().Unix(),
+	}
+	blocks, err := MigrationBundleBlocks(rurl, input)
+	if err != nil {
+		return header, err
+	}
+	header.Blocks = blocks
+
+	gw := gzip.NewWriter(w)
+	defer gw.Close()
+	data, err := json.Marshal(header)
+	if err != nil {
+		return header, Error(err, MarshalErrorCode, "unable to encode bundle header", "dbs.migration_bundle.ExportMigrationBundle")
+	}
+	if _, err := gw.Write(append(data, '\n')); err != nil {
+		return header, Error(err, WriterErrorCode, "unable to write bundle", "dbs.migration_bundle.ExportMigrationBundle")
+	}
+	for _, blk := range blocks {
+		// block dump is stored as single line
+		data, err := remoteBlockDump(header.MigrationURL, blk)
+		if err != nil {
+			msg := fmt.Sprintf("unable to query %s/blockdump", header.MigrationURL)
+			return header, Error(err, HttpRequestErrorCode, msg, "dbs.migration_bundle.ExportMigrationBundle")
+		}
+		if _, err := gw.Write(append(data, '\n')); err != nil {
+			return header, Error(err, WriterErrorCode, "unable to write bundle", "dbs.migration_bundle.ExportMigrationBundle")
+		}
+		if utils.VERBOSE > 0 {
+			log.Printf("export block %s into migration bundle", blk)
+		}
+	}
+	if err := gw.Close(); err != nil {
+		return header, Error(err, WriterErrorCode, "unable to write bundle", "dbs.migration_bundle.ExportMigrationBundle")
+	}
+	return header, nil
+}
+
+// ImportMigrationBundle API imports migration bundle provided by API reader.
+// The bundle blocks are inserted in their order via bulkblocks API, blocks
+// which already exist in DBS are skipped and import stops at first failure
+// since remaining blocks may depend on the failed one.
+func (a *API) ImportMigrationBundle() error {
+	reports, err := a.importMigrationBundle()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(reports)
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to encode bundle report", "dbs.migration_bundle.ImportMigrationBundle")
+	}
+	a.Writer.Write(data)
+	return nil
+}
+
+// helper function to import migration bundle and report status of its blocks
+//
+//gocyclo:ignore
+func (a *API) importMigrationBundle() ([]MigrationBundleReport, error) {
+	var reports []MigrationBundleReport
+	reader := bufio.NewReader(a.Reader)
+	// bundle can be provided either compressed or already decompressed,
+	// e.g. via HTTP Content-Encoding
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(reader)
+		if err != nil {
+			return reports, Error(err, ReaderErrorCode, "unable to read compressed bundle", "dbs.migration_bundle.ImportMigrationBundle")
+		}
+		defer gr.Close()
+		reader = bufio.NewReader(gr)
+	}
+
+	// read bundle header
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return reports, Error(err, ReaderErrorCode, "unable to read bundle header", "dbs.migration_bundle.ImportMigrationBundle")
+	}
+	var header MigrationBundleHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return reports, Error(err, UnmarshalErrorCode, "unable to decode bundle header", "dbs.migration_bundle.ImportMigrationBundle")
+	}
+	if header.Version != MigrationBundleVersion {
+		msg := fmt.Sprintf("unsupported bundle version %d", header.Version)
+		return reports, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+	}
+	log.Printf("import migration bundle of %s from %s with %d blocks", header.MigrationInput, header.MigrationURL, len(header.Blocks))
+
+	for idx, blk := range header.Blocks {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(bytes.TrimSpace(line)) == 0) {
+			msg := fmt.Sprintf("unable to read blockdump record of %s", blk)
+			return reports, Error(err, ReaderErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+		}
+		var brec BulkBlocks
+		if err := json.Unmarshal(line, &brec); err != nil {
+			msg := fmt.Sprintf("unable to decode blockdump record of %s", blk)
+			return reports, Error(err, UnmarshalErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+		}
+		// blocks should follow migration order of bundle header
+		if brec.Block.BlockName != blk {
+			msg := fmt.Sprintf("bundle record %d has block %s while expected %s", idx, brec.Block.BlockName, blk)
+			return reports, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+		}
+		// skip blocks which already exist in DBS
+		blocks, err := blocksInDB([]string{blk})
+		if err != nil {
+			return reports, err
+		}
+		if len(blocks) > 0 {
+			reports = append(reports, MigrationBundleReport{Block: blk, Status: statusString(EXIST_IN_DB)})
+			continue
+		}
+		status, _, err := migrateBlockDump(line, a.CreateBy, a.Separator)
+		report := MigrationBundleReport{Block: blk, Status: statusString(status)}
+		if err != nil {
+			report.Error = err.Error()
+		}
+		reports = append(reports, report)
+		if status == FAILED {
+			msg := fmt.Sprintf("unable to import block %s", blk)
+			return reports, Error(err, MigrationErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+		}
+		if utils.VERBOSE > 0 {
+			log.Printf("import block %s from migration bundle with status %s", blk, report.Status)
+		}
+	}
+	if line, _ := reader.ReadBytes('\n'); len(bytes.TrimSpace(line)) != 0 {
+		msg := "bundle contains more records than listed in its header"
+		return reports, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migration_bundle.ImportMigrationBundle")
+	}
+	return reports, nil
+}
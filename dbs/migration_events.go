@@ -0,0 +1,207 @@
+package dbs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MigrationWebhooks defines list of webhook URLs which receive all
+// migration events
+var MigrationWebhooks []string
+
+// MigrationWebhookSecret defines secret used to sign webhook payloads
+var MigrationWebhookSecret string
+
+// MigrationWebhookRetries defines number of retries of webhook delivery
+var MigrationWebhookRetries int
+
+// MigrationCallbackURLs defines URL prefixes which are allowed as callback
+// URLs of migration requests, callbacks are not allowed if list is empty
+var MigrationCallbackURLs []string
+
+// MigrationEventsFile defines file where migration events are written as
+// new line delimited JSON records
+var MigrationEventsFile string
+
+// MigrationSignatureHeader defines HTTP header with HMAC-SHA256 signature of
+// webhook payload
+const MigrationSignatureHeader = "X-Dbs-Signature"
+
+// MigrationEvent represents change of migration request status
+type MigrationEvent struct {
+	MigrationRequestID int64  `json:"migration_request_id"`
+	MigrationURL       string `json:"migration_url"`
+	MigrationInput     string `json:"migration_input"`
+	CreateBy           string `json:"create_by"`
+	PreviousStatus     string `json:"previous_status,omitempty"`
+	Status             string `json:"status"`
+	MigrationStatus    int64  `json:"migration_status"`
+	RetryCount         int64  `json:"retry_count"`
+	Timestamp          int64  `json:"timestamp"`
+}
+
+// MigrationEventSink represents destination of migration events
+type MigrationEventSink interface {
+	Send(data []byte) error
+}
+
+// WebhookSink delivers migration events to HTTP webhook
+type WebhookSink struct {
+	URL     string
+	Secret  string
+	Retries int
+}
+
+// Send implements MigrationEventSink interface for WebhookSink. The event is
+// posted to webhook URL with HMAC signature and it is retried with
+// exponential backoff on failures.
+func (s WebhookSink) Send(data []byte) error {
+	var err error
+	client := &http.Client{Timeout: 10 * time.Second}
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+		var req *http.Request
+		req, err = http.NewRequest("POST", s.URL, bytes.NewReader(data))
+		if err != nil {
+			return Error(err, HttpRequestErrorCode, "", "dbs.migration_events.Send")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.Secret != "" {
+			req.Header.Set(MigrationSignatureHeader, "sha256="+MigrationEventSignature(data, s.Secret))
+		}
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("webhook %s responded with status %d", s.URL, resp.StatusCode)
+		}
+		if utils.VERBOSE > 0 {
+			log.Printf("fail to deliver migration event to %s, attempt %d, error %v", s.URL, attempt+1, err)
+		}
+	}
+	return Error(err, HttpRequestErrorCode, "unable to deliver migration event", "dbs.migration_events.Send")
+}
+
+// FileSink writes migration events to a file as new line delimited JSON
+type FileSink struct {
+	Name string
+}
+
+// mutex to serialize writes to file sink
+var fileSinkMutex sync.Mutex
+
+// Send implements MigrationEventSink interface for FileSink
+func (s FileSink) Send(data []byte) error {
+	fileSinkMutex.Lock()
+	defer fileSinkMutex.Unlock()
+	file, err := os.OpenFile(s.Name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return Error(err, WriterErrorCode, "", "dbs.migration_events.Send")
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return Error(err, WriterErrorCode, "", "dbs.migration_events.Send")
+	}
+	return nil
+}
+
+// MigrationEventSignature returns hex encoded HMAC-SHA256 signature of
+// given payload
+func MigrationEventSignature(data []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// helper function to check if callback URL has scheme, host and path prefix
+// of one of allowed callback URLs. The path prefix should match entire path
+// segments, e.g. /hooks allows /hooks and /hooks/123 but not /hooksfoo.
+func allowedCallbackURL(rurl string) bool {
+	u, err := url.Parse(rurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return false
+	}
+	for _, prefix := range MigrationCallbackURLs {
+		p, err := url.Parse(prefix)
+		if err != nil || p.Scheme != u.Scheme || p.Host != u.Host {
+			continue
+		}
+		ppath := strings.TrimSuffix(p.Path, "/")
+		if u.Path == ppath || strings.HasPrefix(u.Path, ppath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to notify all sinks about change of migration request
+// status. The file sink is written synchronously, while webhooks are called
+// asynchronously such that migration processing is not blocked.
+func notifyMigrationEvent(mrec MigrationRequest, prevStatus, status, retryCount int64) {
+	if MigrationEventsFile == "" && len(MigrationWebhooks) == 0 && mrec.CALLBACK_URL == "" {
+		return
+	}
+	event := MigrationEvent{
+		MigrationRequestID: mrec.MIGRATION_REQUEST_ID,
+		MigrationURL:       mrec.MIGRATION_URL,
+		MigrationInput:     mrec.MIGRATION_INPUT,
+		CreateBy:           mrec.CREATE_BY,
+		Status:             statusString(status),
+		MigrationStatus:    status,
+		RetryCount:         retryCount,
+		Timestamp:          time.Now().Unix(),
+	}
+	if prevStatus >= 0 {
+		event.PreviousStatus = statusString(prevStatus)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("unable to encode migration event %+v, error %v", event, err)
+		return
+	}
+	if MigrationEventsFile != "" {
+		sink := FileSink{Name: MigrationEventsFile}
+		if err := sink.Send(data); err != nil {
+			log.Printf("unable to write migration event to %s, error %v", MigrationEventsFile, err)
+		}
+	}
+	var sinks []MigrationEventSink
+	for _, rurl := range MigrationWebhooks {
+		sinks = append(sinks, WebhookSink{URL: rurl, Secret: MigrationWebhookSecret, Retries: MigrationWebhookRetries})
+	}
+	// callback URLs are provided by users, they are not signed with secret
+	// of configured webhooks and they are re-checked since allowed callback
+	// URLs may change after submission of migration request
+	if mrec.CALLBACK_URL != "" {
+		if allowedCallbackURL(mrec.CALLBACK_URL) {
+			sinks = append(sinks, WebhookSink{URL: mrec.CALLBACK_URL, Retries: MigrationWebhookRetries})
+		} else {
+			log.Printf("callback url %s of migration request %d is not allowed", mrec.CALLBACK_URL, mrec.MIGRATION_REQUEST_ID)
+		}
+	}
+	for _, sink := range sinks {
+		go func(s MigrationEventSink) {
+			if err := s.Send(data); err != nil {
+				log.Printf("unable to deliver migration event %s, error %v", string(data), err)
+			}
+		}(sink)
+	}
+}
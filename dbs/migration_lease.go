@@ -0,0 +1,146 @@
+package dbs
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MigrationLeaseDuration defines migration request lease duration in seconds
+var MigrationLeaseDuration int
+
+// migrationOwner holds identity of this migration server used as lease owner
+var migrationOwner string
+
+// MigrationLease represents lease of migration request acquired by
+// migration server. The lease is stored in MIGRATION_SERVER (owner) and
+// LEASE_EXPIRATION_DATE (expiry) columns of MIGRATION_REQUESTS table.
+type MigrationLease struct {
+	MigrationRequestID int64
+	Owner              string
+	Expiration         int64
+	stop               chan bool
+	once               sync.Once
+}
+
+// MigrationOwner returns identity of this migration server, i.e.
+// hostname and process id, used as lease owner
+func MigrationOwner() string {
+	if migrationOwner != "" {
+		return migrationOwner
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	migrationOwner = fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	return migrationOwner
+}
+
+// helper function to return lease duration in seconds
+func leaseDuration() int64 {
+	if MigrationLeaseDuration <= 0 {
+		return 300 // by default we'll keep the lease for 5 minutes
+	}
+	return int64(MigrationLeaseDuration)
+}
+
+// ClaimMigrationRequest acquires lease of given migration request.
+// The claim is an atomic conditional update which only succeeds if request
+// has no lease or its lease is expired, e.g. its migration server died.
+func ClaimMigrationRequest(mid int64) (*MigrationLease, error) {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("claim_migration_request", tmplData)
+	if err != nil {
+		return nil, Error(err, LoadErrorCode, "unable to load claim_migration_request template", "dbs.migration_lease.ClaimMigrationRequest")
+	}
+	now := time.Now().Unix()
+	lease := &MigrationLease{
+		MigrationRequestID: mid,
+		Owner:              MigrationOwner(),
+		Expiration:         now + leaseDuration(),
+		stop:               make(chan bool),
+	}
+	args := []interface{}{lease.Owner, lease.Expiration, mid, now}
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	res, err := DB.Exec(stm, args...)
+	if err != nil {
+		return nil, Error(err, UpdateMigrationErrorCode, "unable to claim migration request", "dbs.migration_lease.ClaimMigrationRequest")
+	}
+	if nrows, err := res.RowsAffected(); err != nil || nrows != 1 {
+		msg := fmt.Sprintf("migration request %d is already leased by another migration server", mid)
+		return nil, Error(ConcurrencyErr, MigrationErrorCode, msg, "dbs.migration_lease.ClaimMigrationRequest")
+	}
+	if utils.VERBOSE > 0 {
+		log.Printf("migration request %d is leased by %s until %d", mid, lease.Owner, lease.Expiration)
+	}
+	return lease, nil
+}
+
+// Renew extends migration request lease by lease duration
+func (l *MigrationLease) Renew() error {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("renew_migration_lease", tmplData)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load renew_migration_lease template", "dbs.migration_lease.Renew")
+	}
+	expiration := time.Now().Unix() + leaseDuration()
+	res, err := DB.Exec(stm, expiration, l.MigrationRequestID, l.Owner)
+	if err != nil {
+		return Error(err, UpdateMigrationErrorCode, "unable to renew migration lease", "dbs.migration_lease.Renew")
+	}
+	if nrows, err := res.RowsAffected(); err != nil || nrows != 1 {
+		msg := fmt.Sprintf("lease of migration request %d is lost", l.MigrationRequestID)
+		return Error(ConcurrencyErr, MigrationErrorCode, msg, "dbs.migration_lease.Renew")
+	}
+	l.Expiration = expiration
+	return nil
+}
+
+// Heartbeat periodically renews migration request lease until it is released.
+// The lease is renewed every third of lease duration.
+func (l *MigrationLease) Heartbeat() {
+	interval := time.Duration(leaseDuration()) * time.Second / 3
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				if err := l.Renew(); err != nil {
+					log.Printf("unable to renew lease of migration request %d, error %v", l.MigrationRequestID, err)
+				}
+			}
+		}
+	}()
+}
+
+// Release stops lease heartbeat and releases migration request lease
+func (l *MigrationLease) Release() error {
+	l.once.Do(func() { close(l.stop) })
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("release_migration_lease", tmplData)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load release_migration_lease template", "dbs.migration_lease.Release")
+	}
+	_, err = DB.Exec(stm, l.MigrationRequestID, l.Owner)
+	if err != nil {
+		log.Printf("unable to release lease of migration request %d, error %v", l.MigrationRequestID, err)
+		return Error(err, UpdateMigrationErrorCode, "unable to release migration lease", "dbs.migration_lease.Release")
+	}
+	if utils.VERBOSE > 0 {
+		log.Printf("migration request %d lease is released by %s", l.MigrationRequestID, l.Owner)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package dbs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MigrationPlanBlock represents block of migration plan along with its
+// estimated number of files, lumis and size
+type MigrationPlanBlock struct {
+	BlockName string `json:"block_name"`
+	NumFile   int64  `json:"num_file"`
+	NumLumi   int64  `json:"num_lumi"`
+	FileSize  int64  `json:"file_size"`
+}
+
+// MigrationPlan represents migration plan of migration request, i.e. what
+// migration server would do for it
+type MigrationPlan struct {
+	MigrationURL     string               `json:"migration_url"`
+	MigrationInput   string               `json:"migration_input"`
+	Blocks           []MigrationPlanBlock `json:"blocks"`
+	ExistingBlocks   []string             `json:"existing_blocks"`
+	NumBlock         int64                `json:"num_block"`
+	NumFile          int64                `json:"num_file"`
+	NumLumi          int64                `json:"num_lumi"`
+	FileSize         int64                `json:"file_size"`
+	LexiconConflicts []string             `json:"lexicon_conflicts"`
+}
+
+// PlanMigration API provides migration plan for given migration request:
+// ordered list of blocks to migrate (parents first), blocks which already
+// exist in DBS, estimated number of files, lumis and bytes, and lexicon
+// conflicts of migration blocks. It does not write anything to database.
+func (a *API) PlanMigration() error {
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		return Error(err, ReaderErrorCode, "unable to read migration record", "dbs.migration_plan.PlanMigration")
+	}
+	var rec MigrationRequest
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		return Error(err, UnmarshalErrorCode, "unable to decode migration record", "dbs.migration_plan.PlanMigration")
+	}
+	if rec.MIGRATION_URL == "" || rec.MIGRATION_INPUT == "" {
+		msg := "migration_url and migration_input are required for migration plan"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.migration_plan.PlanMigration")
+	}
+	plan, err := GetMigrationPlan(rec.MIGRATION_URL, rec.MIGRATION_INPUT)
+	if err != nil {
+		return err
+	}
+	data, err = json.Marshal([]MigrationPlan{plan})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to encode migration plan", "dbs.migration_plan.PlanMigration")
+	}
+	a.Writer.Write(data)
+	return nil
+}
+
+// GetMigrationPlan returns migration plan of given input (dataset or block)
+// from remote DBS url
+func GetMigrationPlan(rurl, input string) (MigrationPlan, error) {
+	plan := MigrationPlan{
+		MigrationURL:     rurl,
+		MigrationInput:   input,
+		Blocks:           []MigrationPlanBlock{},
+		ExistingBlocks:   []string{},
+		LexiconConflicts: []string{},
+	}
+	migBlocks, srcBlocks, err := migrationBlockList(rurl, input)
+	if err != nil {
+		msg := fmt.Sprintf("unable to get migration blocks of %s", input)
+		return plan, Error(err, MigrationErrorCode, msg, "dbs.migration_plan.GetMigrationPlan")
+	}
+	// the same way as migration server we add block input to migration
+	// blocks unless it already exists in DBS
+	if strings.Contains(input, "#") && !utils.InList(input, migBlocks) && !utils.InList(input, srcBlocks) {
+		migBlocks = append(migBlocks, input)
+	}
+	plan.ExistingBlocks = append(plan.ExistingBlocks, srcBlocks...)
+	if err := CheckPattern("dataset", strings.Split(input, "#")[0]); err != nil {
+		plan.LexiconConflicts = append(plan.LexiconConflicts, fmt.Sprintf("%s: %v", input, err))
+	}
+	for _, blk := range migBlocks {
+		// migration blocks of dataset input include dataset itself
+		if !strings.Contains(blk, "#") {
+			continue
+		}
+		if err := CheckPattern("block_name", blk); err != nil {
+			plan.LexiconConflicts = append(plan.LexiconConflicts, fmt.Sprintf("%s: %v", blk, err))
+		}
+		pblk := MigrationPlanBlock{BlockName: blk}
+		if err := blockEstimates(rurl, &pblk); err != nil {
+			log.Printf("unable to get estimates of block %s, error %v", blk, err)
+		}
+		plan.Blocks = append(plan.Blocks, pblk)
+		plan.NumBlock++
+		plan.NumFile += pblk.NumFile
+		plan.NumLumi += pblk.NumLumi
+		plan.FileSize += pblk.FileSize
+	}
+	return plan, nil
+}
+
+// helper function to obtain number of files, lumis and size of given block
+// from remote DBS
+func blockEstimates(rurl string, pblk *MigrationPlanBlock) error {
+	remote, err := remoteDBS(rurl)
+	if err != nil {
+		return err
+	}
+	records, err := remote.FileSummaries(url.Values{"block_name": []string{pblk.BlockName}})
+	if err != nil {
+		return Error(err, HttpRequestErrorCode, "", "dbs.migration_plan.blockEstimates")
+	}
+	for _, r := range records {
+		pblk.NumFile += recordInt(r, "num_file")
+		pblk.NumLumi += recordInt(r, "num_lumi")
+		pblk.FileSize += recordInt(r, "file_size")
+	}
+	return nil
+}
+
+// helper function to get integer value of given record attribute, numbers
+// of records decoded from JSON are float64 values
+func recordInt(rec Record, key string) int64 {
+	if v, ok := rec[key].(float64); ok {
+		return int64(v)
+	}
+	return 0
+}
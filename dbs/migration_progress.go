@@ -0,0 +1,188 @@
+package dbs
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MigrationBlockReport represents migration progress of individual block
+// of migration request
+type MigrationBlockReport struct {
+	BlockName          string `json:"block_name"`
+	MigrationRequestID int64  `json:"migration_request_id"`
+	Order              int64  `json:"migration_order"`
+	MigrationStatus    int64  `json:"migration_status"`
+	Status             string `json:"status"`
+	AttemptCount       int64  `json:"attempt_count"`
+	RetryCount         int64  `json:"retry_count"`
+	LastError          string `json:"last_error,omitempty"`
+	StartDate          int64  `json:"start_date,omitempty"`
+	EndDate            int64  `json:"end_date,omitempty"`
+	FileCount          int64  `json:"file_count"`
+	BlockSize          int64  `json:"block_size"`
+}
+
+// MigrationProgress represents summary of migration request progress
+type MigrationProgress struct {
+	TotalBlocks      int64   `json:"total_blocks"`
+	CompletedBlocks  int64   `json:"completed_blocks"`
+	FailedBlocks     int64   `json:"failed_blocks"`
+	InProgressBlocks int64   `json:"in_progress_blocks"`
+	PendingBlocks    int64   `json:"pending_blocks"`
+	Percentage       float64 `json:"percentage"`
+	FileCount        int64   `json:"file_count"`
+	BlockSize        int64   `json:"block_size"`
+}
+
+// maximum length of error message we store in MIGRATION_BLOCKS table
+const maxMigrationErrorLength = 4000
+
+// helper function to mark start of migration block processing
+func startMigrationBlock(mid int64) {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("update_migration_block_start", tmplData)
+	if err != nil {
+		log.Println("unable to load update_migration_block_start template", err)
+		return
+	}
+	now := time.Now().Unix()
+	args := []interface{}{IN_PROGRESS, now, now, mid}
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	if _, err := DB.Exec(stm, args...); err != nil {
+		log.Printf("unable to update migration block of request %d, error %v", mid, err)
+	}
+}
+
+// helper function to persist outcome of migration block processing, i.e.
+// its status, number of files, size and failure reason
+func endMigrationBlock(mid, status, fileCount, blockSize int64, migErr error) {
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	tmplData["Error"] = migErr != nil
+	stm, err := LoadTemplateSQL("update_migration_block_end", tmplData)
+	if err != nil {
+		log.Println("unable to load update_migration_block_end template", err)
+		return
+	}
+	now := time.Now().Unix()
+	args := []interface{}{status, now, fileCount, blockSize}
+	if migErr != nil {
+		msg := migErr.Error()
+		if len(msg) > maxMigrationErrorLength {
+			msg = msg[:maxMigrationErrorLength]
+		}
+		args = append(args, msg)
+	}
+	args = append(args, now, mid)
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	if _, err := DB.Exec(stm, args...); err != nil {
+		log.Printf("unable to update migration block of request %d, error %v", mid, err)
+	}
+}
+
+// helper function to obtain number of files and size of migrated block
+func blockDumpCounts(brec BulkBlocks) (int64, int64) {
+	fileCount := brec.Block.FileCount
+	blockSize := brec.Block.BlockSize
+	if fileCount == 0 {
+		fileCount = int64(len(brec.Files))
+	}
+	if blockSize == 0 {
+		for _, f := range brec.Files {
+			blockSize += f.FileSize
+		}
+	}
+	return fileCount, blockSize
+}
+
+// MigrationBlockReports returns per-block reports of given migration request.
+// It includes blocks of the request itself and all blocks (e.g. parents)
+// which were scheduled for migration on its behalf.
+func MigrationBlockReports(mid int64) ([]MigrationBlockReport, error) {
+	var reports []MigrationBlockReport
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("migration_block_reports", tmplData)
+	if err != nil {
+		return reports, Error(err, LoadErrorCode, "unable to load migration_block_reports template", "dbs.migration_progress.MigrationBlockReports")
+	}
+	stm = CleanStatement(stm)
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, []interface{}{mid, mid}, "execute")
+	}
+	rows, err := DB.Query(stm, mid, mid)
+	if err != nil {
+		msg := fmt.Sprintf("fail to execute %s", stm)
+		return reports, Error(err, QueryErrorCode, msg, "dbs.migration_progress.MigrationBlockReports")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r MigrationBlockReport
+		var order, status, attempts, retries, sdate, edate, nfiles, size sql.NullInt64
+		var lastErr sql.NullString
+		err := rows.Scan(
+			&r.BlockName,
+			&r.MigrationRequestID,
+			&order,
+			&status,
+			&attempts,
+			&retries,
+			&lastErr,
+			&sdate,
+			&edate,
+			&nfiles,
+			&size,
+		)
+		if err != nil {
+			return reports, Error(err, RowsScanErrorCode, "", "dbs.migration_progress.MigrationBlockReports")
+		}
+		r.Order = order.Int64
+		r.MigrationStatus = status.Int64
+		r.Status = statusString(status.Int64)
+		r.AttemptCount = attempts.Int64
+		r.RetryCount = retries.Int64
+		r.LastError = lastErr.String
+		r.StartDate = sdate.Int64
+		r.EndDate = edate.Int64
+		r.FileCount = nfiles.Int64
+		r.BlockSize = size.Int64
+		reports = append(reports, r)
+	}
+	if err := rows.Err(); err != nil {
+		return reports, Error(err, RowsScanErrorCode, "", "dbs.migration_progress.MigrationBlockReports")
+	}
+	return reports, nil
+}
+
+// MigrationProgressSummary returns progress summary of given block reports
+func MigrationProgressSummary(reports []MigrationBlockReport) MigrationProgress {
+	var p MigrationProgress
+	for _, r := range reports {
+		p.TotalBlocks++
+		switch r.MigrationStatus {
+		case COMPLETED, EXIST_IN_DB:
+			p.CompletedBlocks++
+			p.FileCount += r.FileCount
+			p.BlockSize += r.BlockSize
+		case FAILED, TERM_FAILED:
+			p.FailedBlocks++
+		case IN_PROGRESS:
+			p.InProgressBlocks++
+		default:
+			p.PendingBlocks++
+		}
+	}
+	if p.TotalBlocks > 0 {
+		p.Percentage = float64(100*p.CompletedBlocks) / float64(p.TotalBlocks)
+	}
+	return p
+}
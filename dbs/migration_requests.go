@@ -24,6 +24,10 @@ type MigrationRequest struct {
 	LAST_MODIFIED_BY       string `json:"last_modified_by" validate:"required"`
 	LAST_MODIFICATION_DATE int64  `json:"last_modification_date" validate:"required,number,gt=0"`
 	RETRY_COUNT            int64  `json:"retry_count"`
+	LEASE_EXPIRATION_DATE  int64  `json:"lease_expiration_date"`
+	PRIORITY               int64  `json:"priority" validate:"gte=0,lte=10"`
+	NEXT_RETRY_DATE        int64  `json:"next_retry_date"`
+	CALLBACK_URL           string `json:"callback_url,omitempty" validate:"omitempty,url,startswith=http"`
 }
 
 // Copy creates a new copy of migration request
@@ -39,6 +43,10 @@ func (r *MigrationRequest) Copy() MigrationRequest {
 		LAST_MODIFIED_BY:       r.LAST_MODIFIED_BY,
 		LAST_MODIFICATION_DATE: r.LAST_MODIFICATION_DATE,
 		RETRY_COUNT:            r.RETRY_COUNT,
+		LEASE_EXPIRATION_DATE:  r.LEASE_EXPIRATION_DATE,
+		PRIORITY:               r.PRIORITY,
+		NEXT_RETRY_DATE:        r.NEXT_RETRY_DATE,
+		CALLBACK_URL:           r.CALLBACK_URL,
 	}
 	return req
 }
@@ -81,7 +89,9 @@ func (r *MigrationRequest) Insert(tx *sql.Tx) error {
 		r.CREATE_BY,
 		r.LAST_MODIFICATION_DATE,
 		r.LAST_MODIFIED_BY,
-		r.RETRY_COUNT)
+		r.RETRY_COUNT,
+		r.PRIORITY,
+		r.CALLBACK_URL)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "unique") {
 			// if we try to insert the same migration input we'll continue
@@ -140,11 +150,11 @@ func MigrationRequests(mid int64) ([]MigrationRequest, error) {
 	tmplData["Owner"] = DBOWNER
 	if mid == -1 {
 		tmplData["Oldest"] = true
-		tmplData["Date1"] = time.Now().Unix() - 1*60*60        // failed during 1h
-		tmplData["Date2"] = time.Now().Unix() - 2*60*60        // failed during 2h
-		tmplData["Date3"] = time.Now().Unix() - 3*60*60        // failed during 3h
+		tmplData["Date1"] = time.Now().Unix() - 1*60*60        // queued during 1h
+		tmplData["RetryDate"] = time.Now().Unix()              // failed requests with reached retry date
 		tmplData["ProgressDate"] = time.Now().Unix() - 3*60*60 // in progress during 3h
 		tmplData["PendingDate"] = time.Now().Unix() - 3*60*60  // pending during 3h
+		tmplData["LeaseDate"] = time.Now().Unix()              // skip requests with active lease
 	}
 	stm, err := LoadTemplateSQL("migration_requests", tmplData)
 	if err != nil {
@@ -200,7 +210,8 @@ func MigrationRequests(mid int64) ([]MigrationRequest, error) {
 	for rows.Next() {
 		var mid, migRetryCount, migCreationDate, migLastModificationDate, migStatus int64
 		var migURL, migInput, migCreateBy, migLastModifiedBy string
-		var msrv sql.NullString
+		var msrv, callback sql.NullString
+		var lease, priority, retryDate sql.NullInt64
 		err := rows.Scan(
 			&mid,
 			&migURL,
@@ -212,6 +223,10 @@ func MigrationRequests(mid int64) ([]MigrationRequest, error) {
 			&migLastModifiedBy,
 			&migLastModificationDate,
 			&migRetryCount,
+			&lease,
+			&priority,
+			&retryDate,
+			&callback,
 		)
 		if err != nil {
 			return records, Error(err, RowsScanErrorCode, "", "dbs.migration_requests.MigrationRequests")
@@ -227,6 +242,10 @@ func MigrationRequests(mid int64) ([]MigrationRequest, error) {
 			LAST_MODIFIED_BY:       migLastModifiedBy,
 			LAST_MODIFICATION_DATE: migLastModificationDate,
 			RETRY_COUNT:            migRetryCount,
+			LEASE_EXPIRATION_DATE:  lease.Int64,
+			PRIORITY:               priority.Int64,
+			NEXT_RETRY_DATE:        retryDate.Int64,
+			CALLBACK_URL:           callback.String,
 		}
 		records = append(records, rec)
 	}
@@ -0,0 +1,124 @@
+package dbs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationRetryBase defines base delay in seconds of migration retry backoff
+var MigrationRetryBase int64
+
+// MigrationRetryMax defines maximum delay in seconds of migration retry backoff
+var MigrationRetryMax int64
+
+// MigrationRetryJitter defines fraction of retry delay used as random jitter
+var MigrationRetryJitter float64
+
+// permanentMigrationCodes defines DBS error codes of migration failures
+// which can not be fixed by retrying the migration request
+var permanentMigrationCodes = []int{
+	ValidateErrorCode,
+	InvalidPatternErrorCode,
+	DecodeErrorCode,
+	InvalidParameterErrorCode,
+	UnmarshalErrorCode,
+	FileDataTypesDoesNotExist,
+	FileParentDoesNotExist,
+	DatasetParentDoesNotExist,
+	ProcessedDatasetDoesNotExist,
+	PrimaryDatasetTypeDoesNotExist,
+	PrimaryDatasetDoesNotExist,
+	ProcessingEraDoesNotExist,
+	AcquisitionEraDoesNotExist,
+	DataTierDoesNotExist,
+	PhysicsGroupDoesNotExist,
+	DatasetAccessTypeDoesNotExist,
+	DatasetDoesNotExist,
+}
+
+// pattern to extract error codes of (nested) DBS errors from error message
+var dbsErrorCodePattern = regexp.MustCompile(`Code: (\d+)`)
+
+// IsPermanentMigrationError classifies migration failure. The permanent
+// failures are caused by invalid input data, e.g. validation or lexicon
+// errors or missing parents, while all other failures, e.g. remote DBS
+// HTTP errors or timeouts, are considered transient.
+func IsPermanentMigrationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var codes []int
+	var dbsErr *DBSError
+	if errors.As(err, &dbsErr) {
+		codes = append(codes, dbsErr.Code)
+	}
+	for _, m := range dbsErrorCodePattern.FindAllStringSubmatch(err.Error(), -1) {
+		if code, e := strconv.Atoi(m[1]); e == nil {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range codes {
+		for _, c := range permanentMigrationCodes {
+			if code == c {
+				return true
+			}
+		}
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "lexicon")
+}
+
+// MigrationRetryDelay returns delay in seconds before next retry of migration
+// request with given number of retries. The delay grows exponentially from
+// MigrationRetryBase up to MigrationRetryMax and has random jitter.
+func MigrationRetryDelay(retryCount int64) int64 {
+	base := MigrationRetryBase
+	if base <= 0 {
+		base = 600 // by default we start with 10 minutes
+	}
+	delay := base
+	for i := int64(0); i < retryCount; i++ {
+		delay *= 2
+		if MigrationRetryMax > 0 && delay >= MigrationRetryMax {
+			break
+		}
+	}
+	if MigrationRetryMax > 0 && delay > MigrationRetryMax {
+		delay = MigrationRetryMax
+	}
+	if MigrationRetryJitter > 0 {
+		delay += int64(rand.Float64() * MigrationRetryJitter * float64(delay))
+	}
+	return delay
+}
+
+// helper function to handle failure of migration request. Permanent failures
+// terminate migration request, while transient ones are retried with backoff.
+// It returns final status of migration request and classified failure reason.
+func failMigrationRequest(mrec MigrationRequest, migErr error) (int64, error) {
+	if migErr == nil {
+		migErr = errors.New("unknown error")
+	}
+	status := int64(FAILED)
+	reason := fmt.Errorf("transient failure: %v", migErr)
+	if IsPermanentMigrationError(migErr) {
+		status = TERM_FAILED
+		reason = fmt.Errorf("permanent failure: %v", migErr)
+	}
+	log.Printf("migration request %d %v", mrec.MIGRATION_REQUEST_ID, reason)
+	if status == FAILED && mrec.RETRY_COUNT > MigrationRetries {
+		status = TERM_FAILED
+	}
+	updateMigrationStatus(mrec, status)
+	return status, reason
+}
+
+// helper function to get next retry date of failed migration request
+func nextRetryDate(retryCount int64) int64 {
+	return time.Now().Unix() + MigrationRetryDelay(retryCount)
+}
@@ -0,0 +1,142 @@
+package dbs
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MaxMigrationPriority defines highest priority of migration request,
+// requests with higher priority are processed first
+const MaxMigrationPriority = 10
+
+// MigrationUserConcurrency defines number of migration requests of a single
+// user which can be processed concurrently, 0 disables the limit
+var MigrationUserConcurrency int
+
+// helper function to compare migration requests, requests with higher
+// priority come first and then the oldest ones
+func migrationBefore(r1, r2 MigrationRequest) bool {
+	if r1.PRIORITY != r2.PRIORITY {
+		return r1.PRIORITY > r2.PRIORITY
+	}
+	if r1.CREATION_DATE != r2.CREATION_DATE {
+		return r1.CREATION_DATE < r2.CREATION_DATE
+	}
+	return r1.MIGRATION_REQUEST_ID < r2.MIGRATION_REQUEST_ID
+}
+
+// ScheduleMigrationRequests orders given migration requests using fair-share
+// policy. Requests of every user (CREATE_BY) are ordered by priority and
+// creation date, and users take turns, i.e. each round selects one request of
+// every user. The active map provides number of requests currently processed
+// for every user, and users who reached given limit of concurrent requests
+// are skipped. The limit equal to 0 disables this check.
+func ScheduleMigrationRequests(records []MigrationRequest, active map[string]int, limit int) []MigrationRequest {
+	queues := make(map[string][]MigrationRequest)
+	var users []string
+	for _, r := range records {
+		if _, ok := queues[r.CREATE_BY]; !ok {
+			users = append(users, r.CREATE_BY)
+		}
+		queues[r.CREATE_BY] = append(queues[r.CREATE_BY], r)
+	}
+	for _, user := range users {
+		queue := queues[user]
+		sort.Slice(queue, func(i, j int) bool {
+			return migrationBefore(queue[i], queue[j])
+		})
+	}
+	taken := make(map[string]int)
+	for user, n := range active {
+		taken[user] = n
+	}
+	var out []MigrationRequest
+	for {
+		// users with pending requests within their concurrency limit
+		var round []string
+		for _, user := range users {
+			if len(queues[user]) == 0 {
+				continue
+			}
+			if limit > 0 && taken[user] >= limit {
+				continue
+			}
+			round = append(round, user)
+		}
+		if len(round) == 0 {
+			break
+		}
+		// within a round users are ordered by their best request
+		sort.SliceStable(round, func(i, j int) bool {
+			return migrationBefore(queues[round[i]][0], queues[round[j]][0])
+		})
+		for _, user := range round {
+			out = append(out, queues[user][0])
+			queues[user] = queues[user][1:]
+			taken[user]++
+		}
+	}
+	return out
+}
+
+// MigrationQueuePositions returns queue positions of migration requests
+// waiting for processing. The positions follow fair-share order used by
+// migration server, while requests which are processed by migration servers
+// (i.e. leased) do not have queue position.
+func MigrationQueuePositions() (map[int64]int64, error) {
+	positions := make(map[int64]int64)
+	records, err := MigrationRequests(-1)
+	if err != nil {
+		return positions, err
+	}
+	for idx, r := range ScheduleMigrationRequests(records, nil, 0) {
+		positions[r.MIGRATION_REQUEST_ID] = int64(idx + 1)
+	}
+	return positions, nil
+}
+
+// ActiveMigrationRequests returns number of migration requests of every user
+// which are currently leased by migration servers
+func ActiveMigrationRequests() (map[string]int, error) {
+	active := make(map[string]int)
+	tmplData := make(Record)
+	tmplData["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("migration_active_leases", tmplData)
+	if err != nil {
+		return active, Error(err, LoadErrorCode, "unable to load migration_active_leases template", "dbs.migration_scheduler.ActiveMigrationRequests")
+	}
+	if MigrationDB == nil {
+		msg := "Migration DB access is closed"
+		return active, Error(DatabaseErr, DatabaseErrorCode, msg, "dbs.migration_scheduler.ActiveMigrationRequests")
+	}
+	stm = CleanStatement(stm)
+	now := time.Now().Unix()
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, []interface{}{now}, "execute")
+	}
+	rows, err := MigrationDB.Query(stm, now)
+	if err != nil {
+		msg := fmt.Sprintf("fail to execute %s", stm)
+		return active, Error(err, QueryErrorCode, msg, "dbs.migration_scheduler.ActiveMigrationRequests")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user string
+		var count int
+		if err := rows.Scan(&user, &count); err != nil {
+			return active, Error(err, RowsScanErrorCode, "", "dbs.migration_scheduler.ActiveMigrationRequests")
+		}
+		active[user] = count
+	}
+	if err := rows.Err(); err != nil {
+		return active, Error(err, RowsScanErrorCode, "", "dbs.migration_scheduler.ActiveMigrationRequests")
+	}
+	if utils.VERBOSE > 0 && len(active) > 0 {
+		log.Printf("active migration requests per user %v", active)
+	}
+	return active, nil
+}
@@ -42,6 +42,10 @@ func MigrationServer(interval, timeout int, ch <-chan bool) {
 	}
 
 	lastCall := time.Now()
+	ticker := time.NewTicker(time.Duration(1) * time.Second)
+	defer ticker.Stop()
+	// report that migration daemon is alive, see /readyz
+	MigrationHeartbeat()
 	for {
 		select {
 		case v := <-ch:
@@ -49,11 +53,14 @@ func MigrationServer(interval, timeout int, ch <-chan bool) {
 				log.Println("Received notification to stop migration server")
 				return
 			}
-		default:
-			time.Sleep(time.Duration(1) * time.Second)
+		case <-ticker.C:
+			MigrationHeartbeat()
 			if time.Since(lastCall).Seconds() < float64(interval) {
 				continue
 			}
+			if Draining() {
+				continue // do not pick up new migration requests while draining
+			}
 			if utils.VERBOSE > 0 {
 				log.Println("call MigrationRequests")
 			}
@@ -67,6 +74,12 @@ func MigrationServer(interval, timeout int, ch <-chan bool) {
 			if utils.VERBOSE > 0 {
 				log.Printf("found %d migration requests", len(records))
 			}
+			// order requests by priority using per-user fair-share policy
+			active, err := ActiveMigrationRequests()
+			if err != nil {
+				log.Println("fail to fetch active migration requests, error", err)
+			}
+			records = ScheduleMigrationRequests(records, active, MigrationUserConcurrency)
 			for _, r := range records {
 				if utils.VERBOSE > 0 {
 					log.Printf("process %+v", r)
@@ -88,15 +101,43 @@ func MigrationServer(interval, timeout int, ch <-chan bool) {
 					// context timeout
 					go StartMigrationRequest(r)
 				} else {
-					api.ProcessMigration()
+					processWithHeartbeat(func() { api.ProcessMigration() })
 				}
 				log.Printf("migration process %+v finished in %v", params, time.Since(time0))
+				MigrationHeartbeat()
 			}
 		}
 	}
 	log.Println("Exit migration server")
 }
 
+// helper function to report migration daemon heartbeat while migration
+// request is processed. The heartbeat is not reported once processing
+// exceeds migration process timeout such that stuck daemon fails /readyz.
+func processWithHeartbeat(process func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Duration(1) * time.Second)
+		defer ticker.Stop()
+		var deadline <-chan time.Time
+		if MigrationProcessTimeout > 0 {
+			deadline = time.After(time.Duration(MigrationProcessTimeout) * time.Second)
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-deadline:
+				return
+			case <-ticker.C:
+				MigrationHeartbeat()
+			}
+		}
+	}()
+	defer close(done)
+	process()
+}
+
 // MigrationCleanupServer represents migration cleanup daemon..
 func MigrationCleanupServer(interval int, offset int64, ch <-chan bool) {
 	log.Println("Start migration cleanup server")
@@ -3,7 +3,6 @@ package dbs
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 
@@ -25,9 +24,10 @@ type ApiParametersMap map[string][]string
 // ApiParamMap an object which holds API parameter records
 var ApiParamMap ApiParametersMap
 
-// LoadApiParameters loads Api parameters and constructs ApiParameters map
+// LoadApiParameters loads Api parameters and constructs ApiParameters map,
+// the file name without directory is looked up in DBS static files
 func LoadApiParameters(fname string) (ApiParametersMap, error) {
-	data, err := ioutil.ReadFile(fname)
+	data, err := utils.ReadStaticFile(fname)
 	if err != nil {
 		log.Printf("Unable to read, file '%s', error: %v\n", fname, err)
 		return nil, Error(err, ReaderErrorCode, "", "dbs.parameters.LoadParameters")
@@ -0,0 +1,340 @@
+package dbs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// ParentageFinding represents single inconsistency found by ParentageAudit API
+// Type: type of the finding, e.g. missing_file_parents, foreign_file_parents,
+// missing_block_parents, extra_block_parents, missing_dataset_parents or
+// lumi_derivable_file_parents
+// Name: name of child file, block or dataset
+// Parent: name of parent file, block or dataset
+// Count: number of child files, used by lumi_derivable_file_parents finding
+type ParentageFinding struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+	Count  int64  `json:"count,omitempty"`
+}
+
+// ParentageAuditReport represents output of ParentageAudit API
+type ParentageAuditReport struct {
+	Dataset             string             `json:"dataset"`
+	BlockName           string             `json:"block_name,omitempty"`
+	ParentDatasets      []string           `json:"parent_datasets"`
+	Findings            []ParentageFinding `json:"findings"`
+	FixedBlockParents   int64              `json:"fixed_block_parents"`
+	FixedDatasetParents int64              `json:"fixed_dataset_parents"`
+}
+
+// helper structure to keep parentage pair along with ids of its records
+type parentagePair struct {
+	ThisName   string
+	ParentName string
+	ThisID     int64
+	ParentID   int64
+}
+
+// ParentageAudit DBS API checks consistency of dataset, block and file
+// parentage of given dataset or block. If fix parameter is provided the API
+// inserts block and dataset parents which can be derived from file parentage.
+//
+//gocyclo:ignore
+func (a *API) ParentageAudit() error {
+	dataset, _ := getSingleValue(a.Params, "dataset")
+	blk, _ := getSingleValue(a.Params, "block_name")
+	if dataset == "" && blk == "" {
+		msg := "dataset or block_name is required for parentageaudit api"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.parentageaudit.ParentageAudit")
+	}
+	if strings.Contains(dataset, "*") || strings.Contains(blk, "*") {
+		msg := "wild-card dataset or block_name value is not allowed"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.parentageaudit.ParentageAudit")
+	}
+	if blk != "" {
+		dataset = strings.Split(blk, "#")[0]
+	}
+	fix := false
+	if val, err := getSingleValue(a.Params, "fix"); err == nil {
+		val = strings.ToLower(val)
+		fix = val == "true" || val == "1"
+	}
+	report := ParentageAuditReport{
+		Dataset:        dataset,
+		BlockName:      blk,
+		ParentDatasets: []string{},
+		Findings:       []ParentageFinding{},
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "transaction error", "dbs.parentageaudit.ParentageAudit")
+	}
+	defer tx.Rollback()
+
+	datasetID, err := GetID(tx, "DATASETS", "dataset_id", "dataset", dataset)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find dataset %s", dataset)
+		return Error(err, GetDatasetIDErrorCode, msg, "dbs.parentageaudit.ParentageAudit")
+	}
+
+	// obtain declared dataset parents
+	declared := make(map[int64]string)
+	stm := getSQL("datasetparent")
+	stm = WhereClause(stm, []string{fmt.Sprintf("D.DATASET = %s", placeholder("dataset"))})
+	stm = CleanStatement(stm)
+	rows, err := tx.Query(stm, dataset)
+	if err != nil {
+		log.Printf("query='%s' args='%v' error=%v", stm, dataset, err)
+		return Error(err, QueryErrorCode, "unable to query dataset parents", "dbs.parentageaudit.ParentageAudit")
+	}
+	for rows.Next() {
+		var pds, ds string
+		var pid int64
+		if err := rows.Scan(&pds, &pid, &ds); err != nil {
+			rows.Close()
+			return Error(err, RowsScanErrorCode, "unable to scan dataset parents", "dbs.parentageaudit.ParentageAudit")
+		}
+		declared[pid] = pds
+		report.ParentDatasets = append(report.ParentDatasets, pds)
+	}
+	rows.Close()
+	sort.Strings(report.ParentDatasets)
+
+	// obtain file parentage of files in given dataset or block
+	var conds []string
+	var args []interface{}
+	conds = append(conds, fmt.Sprintf("D.DATASET = %s", placeholder("dataset")))
+	args = append(args, dataset)
+	if blk != "" {
+		conds = append(conds, fmt.Sprintf("B.BLOCK_NAME = %s", placeholder("block_name")))
+		args = append(args, blk)
+	}
+	tmpl := make(Record)
+	tmpl["Owner"] = DBOWNER
+	stm, err = LoadTemplateSQL("fileparents_audit", tmpl)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load fileparents_audit template", "dbs.parentageaudit.ParentageAudit")
+	}
+	stm = CleanStatement(WhereClause(stm, conds))
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	rows, err = tx.Query(stm, args...)
+	if err != nil {
+		log.Printf("query='%s' args='%v' error=%v", stm, args, err)
+		return Error(err, QueryErrorCode, "unable to query file parents", "dbs.parentageaudit.ParentageAudit")
+	}
+	var orphans []string
+	orphanIDs := make(map[int64]bool)
+	orphanBlocks := make(map[string]int64)
+	derivedBlocks := make(map[string]parentagePair)
+	derivedDatasets := make(map[int64]string)
+	for rows.Next() {
+		var lfn, blockName string
+		var fid, blockID int64
+		var plfn, pblockName, pds sql.NullString
+		var pblockID, pdsID sql.NullInt64
+		err = rows.Scan(&fid, &lfn, &blockID, &blockName, &plfn, &pblockID, &pblockName, &pdsID, &pds)
+		if err != nil {
+			rows.Close()
+			return Error(err, RowsScanErrorCode, "unable to scan file parents", "dbs.parentageaudit.ParentageAudit")
+		}
+		if !plfn.Valid {
+			orphans = append(orphans, lfn)
+			orphanIDs[fid] = true
+			orphanBlocks[blockName] += 1
+			continue
+		}
+		if _, ok := declared[pdsID.Int64]; !ok {
+			report.Findings = append(report.Findings, ParentageFinding{
+				Type: "foreign_file_parents", Name: lfn, Parent: plfn.String})
+		}
+		key := fmt.Sprintf("%s %s", blockName, pblockName.String)
+		derivedBlocks[key] = parentagePair{
+			ThisName: blockName, ParentName: pblockName.String,
+			ThisID: blockID, ParentID: pblockID.Int64}
+		derivedDatasets[pdsID.Int64] = pds.String
+	}
+	rows.Close()
+
+	// files without parents are inconsistent only if dataset declares its parents
+	if len(declared) > 0 {
+		for _, lfn := range orphans {
+			report.Findings = append(report.Findings, ParentageFinding{
+				Type: "missing_file_parents", Name: lfn})
+		}
+		// check if missing file parents can be derived from run/lumi overlap
+		var blocks []string
+		for b := range orphanBlocks {
+			blocks = append(blocks, b)
+		}
+		sort.Strings(blocks)
+		tmpl["ChildLfnList"] = false
+		tmpl["ParentDataset"] = true
+		stm, err = LoadTemplateSQL("fileparentsbylumi", tmpl)
+		if err != nil {
+			return Error(err, LoadErrorCode, "unable to load fileparentsbylumi sql template", "dbs.parentageaudit.ParentageAudit")
+		}
+		stm = CleanStatement(stm)
+		for _, b := range blocks {
+			for _, pds := range report.ParentDatasets {
+				count, err := lumiDerivableFiles(tx, stm, pds, b, orphanIDs)
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					report.Findings = append(report.Findings, ParentageFinding{
+						Type: "lumi_derivable_file_parents", Name: b, Parent: pds, Count: count})
+				}
+			}
+		}
+	}
+
+	// obtain recorded block parents of given dataset or block
+	conds = []string{}
+	tmpl = make(Record)
+	tmpl["Owner"] = DBOWNER
+	tmpl["TokenGenerator"] = ""
+	var bval interface{}
+	if blk != "" {
+		conds = append(conds, fmt.Sprintf("BC.BLOCK_NAME = %s", placeholder("block_name")))
+		bval = blk
+	} else {
+		conds = append(conds, fmt.Sprintf("BC.DATASET_ID = %s", placeholder("dataset_id")))
+		bval = datasetID
+	}
+	stm, err = LoadTemplateSQL("blockparent", tmpl)
+	if err != nil {
+		return Error(err, LoadErrorCode, "unable to load block parent template", "dbs.parentageaudit.ParentageAudit")
+	}
+	stm = CleanStatement(WhereClause(stm, conds))
+	rows, err = tx.Query(stm, bval)
+	if err != nil {
+		log.Printf("query='%s' args='%v' error=%v", stm, bval, err)
+		return Error(err, QueryErrorCode, "unable to query block parents", "dbs.parentageaudit.ParentageAudit")
+	}
+	recordedBlocks := make(map[string]parentagePair)
+	for rows.Next() {
+		var p parentagePair
+		if err := rows.Scan(&p.ThisName, &p.ParentName); err != nil {
+			rows.Close()
+			return Error(err, RowsScanErrorCode, "unable to scan block parents", "dbs.parentageaudit.ParentageAudit")
+		}
+		recordedBlocks[fmt.Sprintf("%s %s", p.ThisName, p.ParentName)] = p
+	}
+	rows.Close()
+
+	// compare block parentage derived from file parents with recorded one
+	var missingBlocks []parentagePair
+	for _, key := range sortedKeys(derivedBlocks) {
+		if _, ok := recordedBlocks[key]; !ok {
+			p := derivedBlocks[key]
+			missingBlocks = append(missingBlocks, p)
+			report.Findings = append(report.Findings, ParentageFinding{
+				Type: "missing_block_parents", Name: p.ThisName, Parent: p.ParentName})
+		}
+	}
+	for _, key := range sortedKeys(recordedBlocks) {
+		if _, ok := derivedBlocks[key]; !ok {
+			p := recordedBlocks[key]
+			report.Findings = append(report.Findings, ParentageFinding{
+				Type: "extra_block_parents", Name: p.ThisName, Parent: p.ParentName})
+		}
+	}
+
+	// compare dataset parentage derived from file parents with declared one
+	var missingDatasets []int64
+	for pid, pds := range derivedDatasets {
+		if _, ok := declared[pid]; !ok {
+			missingDatasets = append(missingDatasets, pid)
+			report.Findings = append(report.Findings, ParentageFinding{
+				Type: "missing_dataset_parents", Name: dataset, Parent: pds})
+		}
+	}
+
+	if fix {
+		for _, p := range missingBlocks {
+			vals := []interface{}{p.ThisID, p.ParentID}
+			keys := []string{"this_block_id", "parent_block_id"}
+			if IfExistMulti(tx, "BLOCK_PARENTS", "this_block_id", keys, vals...) {
+				continue
+			}
+			_, err = tx.Exec(getSQL("insert_block_parents"), vals...)
+			if err != nil {
+				return Error(err, InsertBlockParentErrorCode, "unable to insert block parents", "dbs.parentageaudit.ParentageAudit")
+			}
+			report.FixedBlockParents += 1
+		}
+		for _, pid := range missingDatasets {
+			vals := []interface{}{datasetID, pid}
+			keys := []string{"this_dataset_id", "parent_dataset_id"}
+			if IfExistMulti(tx, "DATASET_PARENTS", "this_dataset_id", keys, vals...) {
+				continue
+			}
+			_, err = tx.Exec(getSQL("insert_dataset_parents"), vals...)
+			if err != nil {
+				return Error(err, InsertDatasetParentErrorCode, "unable to insert dataset parents", "dbs.parentageaudit.ParentageAudit")
+			}
+			report.FixedDatasetParents += 1
+		}
+		err = tx.Commit()
+		if err != nil {
+			log.Println("fail to commit transaction", err)
+			return Error(err, CommitErrorCode, "unable to commit parentage fixes", "dbs.parentageaudit.ParentageAudit")
+		}
+	}
+
+	data, err := json.Marshal([]ParentageAuditReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to marshal parentage audit report", "dbs.parentageaudit.ParentageAudit")
+	}
+	if a.Writer != nil {
+		a.Writer.Write(data)
+	}
+	return nil
+}
+
+// helper function to count given child files of a block which have parents
+// in given parent dataset based on overlapping run/lumi pairs
+func lumiDerivableFiles(
+	tx *sql.Tx,
+	stm, parentDataset, blk string,
+	orphanIDs map[int64]bool) (int64, error) {
+	rows, err := tx.Query(stm, parentDataset, blk)
+	if err != nil {
+		log.Printf("query='%s' args='%v %v' error=%v", stm, parentDataset, blk, err)
+		return 0, Error(err, QueryErrorCode, "unable to query file parents by lumi", "dbs.parentageaudit.lumiDerivableFiles")
+	}
+	defer rows.Close()
+	fids := make(map[int64]bool)
+	for rows.Next() {
+		var cid, pid int64
+		if err := rows.Scan(&cid, &pid); err != nil {
+			return 0, Error(err, RowsScanErrorCode, "unable to scan file parents by lumi", "dbs.parentageaudit.lumiDerivableFiles")
+		}
+		if orphanIDs[cid] {
+			fids[cid] = true
+		}
+	}
+	return int64(len(fids)), nil
+}
+
+// helper function to return sorted keys of parentage pairs map
+func sortedKeys(pairs map[string]parentagePair) []string {
+	var keys []string
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
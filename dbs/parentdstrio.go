@@ -8,7 +8,7 @@ func (a *API) ParentDSTrio() error {
 	stm := getSQL("datasetchildren")
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query parent dataset trio", "dbs.parentdstrio.ParentDSTrio")
 	}
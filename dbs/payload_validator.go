@@ -0,0 +1,257 @@
+package dbs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MaxPayloadViolations defines maximum number of violations reported for
+// single payload
+const MaxPayloadViolations = 100
+
+// payloadLexicon maps payload keys to writer lexicon pattern names
+var payloadLexicon = map[string]string{
+	"dataset":                  "dataset",
+	"parent_dataset":           "dataset",
+	"new_dataset":              "dataset",
+	"dataset_parent_list":      "dataset",
+	"block_name":               "block_name",
+	"parent_block_name":        "block_name",
+	"this_block_name":          "block_name",
+	"logical_file_name":        "logical_file_name",
+	"parent_logical_file_name": "logical_file_name",
+	"this_logical_file_name":   "logical_file_name",
+	"file_parent_lfn":          "logical_file_name",
+	"lfn":                      "logical_file_name",
+	"primary_ds_name":          "primary_ds_name",
+	"primary_ds_type":          "primary_ds_type",
+	"processed_ds_name":        "processed_ds_name",
+	"processing_version":       "processing_version",
+	"acquisition_era_name":     "acquisition_era_name",
+	"data_tier_name":           "data_tier_name",
+	"global_tag":               "global_tag",
+	"physics_group_name":       "physics_group",
+	"release_version":          "cmssw_version",
+	"create_by":                "create_by",
+	"last_modified_by":         "last_modified_by",
+	"tag_key":                  "tag_key",
+	"remove":                   "tag_key",
+}
+
+// payload keys which should contain unix time stamps
+var payloadDateKeys = []string{"creation_date", "last_modification_date"}
+
+// payload keys which should contain non-negative integers
+var payloadIntKeys = []string{"run_num", "lumi_section_num"}
+
+// PayloadViolation represents single lexicon violation of POST/PUT payload
+type PayloadViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// PayloadError represents validation error of POST/PUT payload which holds
+// all violations found in it
+type PayloadError struct {
+	*DBSError
+	Violations []PayloadViolation `json:"violations"`
+}
+
+// PayloadValidationError returns PayloadError for given list of violations
+func PayloadValidationError(violations []PayloadViolation, function string) error {
+	msg := fmt.Sprintf("payload has %d lexicon violation(s)", len(violations))
+	if len(violations) > MaxPayloadViolations {
+		msg = fmt.Sprintf("%s, first %d are reported", msg, MaxPayloadViolations)
+		violations = violations[:MaxPayloadViolations]
+	}
+	reason := violations[0].Path + ": " + violations[0].Message
+	err := Error(ValidationErr, ValidateErrorCode, msg, function).(*DBSError)
+	err.Reason = reason
+	return &PayloadError{DBSError: err, Violations: violations}
+}
+
+// ValidatePayload validates JSON payload of writer API, including all its
+// nested records, against writer lexicon patterns and lengths. It returns
+// list of violations along with their JSON paths in order of their
+// appearance in the payload. The payload is validated token by token
+// without decoding it into memory. The malformed JSON is not reported here
+// since it is reported by API decoding the payload.
+func ValidatePayload(data []byte) []PayloadViolation {
+	var violations []PayloadViolation
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := walkPayloadTokens(decoder, "$", "", &violations); err != nil {
+		return nil
+	}
+	return violations
+}
+
+// helper function to walk through single JSON value of given decoder and
+// collect its violations, it follows the rules of walkPayload function
+func walkPayloadTokens(decoder *json.Decoder, path, key string, violations *[]PayloadViolation) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		if msg := checkPayloadValue(key, token); msg != "" {
+			*violations = append(*violations, PayloadViolation{Path: path, Message: msg})
+		}
+		return nil
+	}
+	switch delim {
+	case '{':
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			k, _ := token.(string)
+			if key == "tags" {
+				// tags hold free-form values, only their keys are checked
+				if msg := checkPayloadValue("tag_key", k); msg != "" {
+					*violations = append(*violations, PayloadViolation{Path: path + "." + k, Message: msg})
+				}
+				var val json.RawMessage
+				if err := decoder.Decode(&val); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walkPayloadTokens(decoder, path+"."+k, k, violations); err != nil {
+				return err
+			}
+		}
+	case '[':
+		// elements of lists inherit key of the list, e.g. dataset_parent_list
+		for idx := 0; decoder.More(); idx++ {
+			if err := walkPayloadTokens(decoder, fmt.Sprintf("%s[%d]", path, idx), key, violations); err != nil {
+				return err
+			}
+		}
+	}
+	// read closing delimiter of object or list
+	_, err = decoder.Token()
+	return err
+}
+
+// ValidatePayloadRecord validates given record, e.g. PUT parameters,
+// against writer lexicon patterns and lengths
+func ValidatePayloadRecord(rec Record) []PayloadViolation {
+	var violations []PayloadViolation
+	walkPayload("$", "", map[string]interface{}(rec), &violations)
+	return violations
+}
+
+// helper function to walk through payload and collect its violations
+func walkPayload(path, key string, val interface{}, violations *[]PayloadViolation) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if key == "tags" {
+				// tags hold free-form values, only their keys are checked
+				if msg := checkPayloadValue("tag_key", k); msg != "" {
+					*violations = append(*violations, PayloadViolation{Path: path + "." + k, Message: msg})
+				}
+				continue
+			}
+			walkPayload(path+"."+k, k, v[k], violations)
+		}
+	case Record:
+		walkPayload(path, key, map[string]interface{}(v), violations)
+	case []interface{}:
+		// elements of lists inherit key of the list, e.g. dataset_parent_list
+		for idx, vvv := range v {
+			walkPayload(fmt.Sprintf("%s[%d]", path, idx), key, vvv, violations)
+		}
+	case []string:
+		for idx, vvv := range v {
+			walkPayload(fmt.Sprintf("%s[%d]", path, idx), key, vvv, violations)
+		}
+	default:
+		if msg := checkPayloadValue(key, val); msg != "" {
+			*violations = append(*violations, PayloadViolation{Path: path, Message: msg})
+		}
+	}
+}
+
+// helper function to check single payload value, it returns violation
+// message or empty string if value is valid
+//
+//gocyclo:ignore
+func checkPayloadValue(key string, val interface{}) string {
+	if val == nil {
+		return ""
+	}
+	if lkey, ok := payloadLexicon[key]; ok {
+		var v string
+		switch vvv := val.(type) {
+		case string:
+			v = vvv
+		case json.Number:
+			// numeric values are allowed for numeric patterns only
+			if key != "processing_version" {
+				return fmt.Sprintf("invalid type of '%s' value %v, expect string", key, val)
+			}
+			v = vvv.String()
+		default:
+			return fmt.Sprintf("invalid type of '%s' value %v, expect string", key, val)
+		}
+		if v == "" {
+			// presence of required attributes is checked by records
+			return ""
+		}
+		p, ok := LexiconPatterns[lkey]
+		if !ok {
+			return ""
+		}
+		if p.Lexicon.Length > 0 && len(v) > p.Lexicon.Length {
+			return fmt.Sprintf("length of '%s' value exceeds %d characters", key, p.Lexicon.Length)
+		}
+		if lkey == "block_name" {
+			if err := checkBlockHash(v); err != nil {
+				return fmt.Sprintf("wrong '%s' value '%s', expect <dataset>#<hash> with hash up to 36 characters", key, v)
+			}
+		}
+		for _, pat := range p.Patterns {
+			if pat.MatchString(v) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("'%s' value '%s' does not match lexicon patterns of %s", key, v, lkey)
+	}
+	for _, k := range payloadDateKeys {
+		if key == k {
+			v := fmt.Sprintf("%v", val)
+			if v == "0" {
+				// default value will be assigned
+				return ""
+			}
+			if !unixTimePattern.MatchString(v) {
+				return fmt.Sprintf("'%s' value %v is not unix time stamp", key, val)
+			}
+			return ""
+		}
+	}
+	for _, k := range payloadIntKeys {
+		if key == k {
+			if key == "run_num" && runRangePattern.MatchString(fmt.Sprintf("%v", val)) {
+				return ""
+			}
+			if _, err := strconv.ParseUint(fmt.Sprintf("%v", val), 10, 64); err != nil {
+				return fmt.Sprintf("'%s' value %v is not non-negative integer", key, val)
+			}
+			return ""
+		}
+	}
+	return ""
+}
@@ -22,7 +22,7 @@ func (a *API) PhysicsGroups() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query physics group", "dbs.physicsgroups.PhysicsGroups")
 	}
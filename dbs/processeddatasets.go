@@ -17,7 +17,7 @@ func (a *API) ProcessedDatasets() error {
 	stm := getSQL("processed_datasets")
 
 	// use generic query API to fetch the results from DB
-	err := executeAll(a.Writer, a.Separator, stm, args...)
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "unable to query processed dataset", "dbs.processeddatasets.ProcessedDatasets")
 	}
@@ -0,0 +1,444 @@
+package dbs
+
+// rename.go - provides admin API to rename dataset and resolution of old
+// dataset names used by DBS reader APIs
+//
+// The dataset is renamed in place, i.e. it keeps its id, files and parentage,
+// while its processed dataset, acquisition and processing eras are replaced by
+// the ones of the new name and its blocks are renamed with their #uuid suffix
+// kept. The old to new name mapping is recorded in DATASET_RENAMES table.
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// DatasetRenamesTTL defines how long (in seconds) dataset renames are cached
+// by DBS server before they are re-read from DB
+var DatasetRenamesTTL int64 = 60
+
+// RenameRecord represents input record of RenameDataset API
+// Dataset: name of dataset to rename
+// NewDataset: new name of the dataset
+// AcquisitionEraName: acquisition era of new dataset, by default it is
+// taken from processed dataset name, e.g. era from era-string-v1
+// ProcessingVersion: processing version of new dataset, by default it is
+// taken from processed dataset name, e.g. 1 from era-string-v1
+// Reason: reason of rename recorded along with old to new name mapping
+type RenameRecord struct {
+	Dataset            string `json:"dataset"`
+	NewDataset         string `json:"new_dataset"`
+	AcquisitionEraName string `json:"acquisition_era_name,omitempty"`
+	ProcessingVersion  int64  `json:"processing_version,omitempty"`
+	Reason             string `json:"reason,omitempty"`
+}
+
+// RenameReport represents report of RenameDataset API
+type RenameReport struct {
+	Dataset    string            `json:"dataset"`
+	NewDataset string            `json:"new_dataset"`
+	Blocks     map[string]string `json:"blocks"`
+}
+
+// DatasetRename represents DATASET_RENAMES DBS DB table
+type DatasetRename struct {
+	RENAME_ID   int64  `json:"rename_id"`
+	DATASET_ID  int64  `json:"dataset_id"`
+	OLD_DATASET string `json:"old_dataset"`
+	NEW_DATASET string `json:"new_dataset"`
+	REASON      string `json:"reason"`
+	RENAMED_BY  string `json:"renamed_by"`
+	RENAME_DATE int64  `json:"rename_date"`
+}
+
+// helper function to split dataset name into primary, processed dataset
+// and data tier names
+func splitDataset(dataset string) (string, string, string, error) {
+	parts := strings.Split(dataset, "/")
+	if len(parts) != 4 || parts[0] != "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		msg := fmt.Sprintf("invalid dataset name '%s'", dataset)
+		return "", "", "", Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.rename.splitDataset")
+	}
+	return parts[1], parts[2], parts[3], nil
+}
+
+// helper function to get acquisition era and processing version from
+// processed dataset name which has era-string-vN form
+func processedDatasetEras(procds string) (string, int64) {
+	era := strings.Split(procds, "-")[0]
+	var version int64
+	if idx := strings.LastIndex(procds, "-v"); idx > 0 {
+		if v, err := strconv.ParseInt(procds[idx+2:], 10, 64); err == nil {
+			version = v
+		}
+	}
+	return era, version
+}
+
+// RenameDataset DBS API renames dataset and its blocks
+//
+//gocyclo:ignore
+func (a *API) RenameDataset() error {
+	// read given input
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		log.Println("fail to read data", err)
+		return Error(err, ReaderErrorCode, "unable to read rename record", "dbs.rename.RenameDataset")
+	}
+	var rec RenameRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		log.Println("fail to decode data as RenameRecord", err)
+		return Error(err, UnmarshalErrorCode, "unable to decode rename record", "dbs.rename.RenameDataset")
+	}
+	if utils.VERBOSE > 0 {
+		log.Printf("RenameDataset record %+v", rec)
+	}
+	if rec.Dataset == "" || rec.NewDataset == "" {
+		msg := "RenameDataset API requires dataset and new_dataset"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+	prim, _, tier, err := splitDataset(rec.Dataset)
+	if err != nil {
+		return err
+	}
+	newPrim, procds, newTier, err := splitDataset(rec.NewDataset)
+	if err != nil {
+		return err
+	}
+	if prim != newPrim || tier != newTier {
+		msg := "rename of dataset can only change its processed dataset name"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+	era, version := processedDatasetEras(procds)
+	if rec.AcquisitionEraName != "" {
+		era = rec.AcquisitionEraName
+	}
+	if rec.ProcessingVersion > 0 {
+		version = rec.ProcessingVersion
+	}
+	if err := CheckPattern("processed_ds_name", procds); err != nil {
+		return Error(err, InvalidParameterErrorCode, "invalid processed dataset name of new dataset", "dbs.rename.RenameDataset")
+	}
+	if err := CheckPattern("acquisition_era_name", era); err != nil {
+		return Error(err, InvalidParameterErrorCode, "invalid acquisition era of new dataset", "dbs.rename.RenameDataset")
+	}
+	if version <= 0 {
+		msg := "unable to determine processing version of new dataset, please provide processing_version"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "transaction error", "dbs.rename.RenameDataset")
+	}
+	defer tx.Rollback()
+
+	datasetID, err := GetID(tx, "DATASETS", "dataset_id", "dataset", rec.Dataset)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find dataset %s", rec.Dataset)
+		return Error(err, GetDatasetIDErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+	if _, err := GetID(tx, "DATASETS", "dataset_id", "dataset", rec.NewDataset); err == nil {
+		msg := fmt.Sprintf("dataset %s already exists", rec.NewDataset)
+		return Error(errors.New(msg), RenameDatasetErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+
+	// create or reuse processed dataset, acquisition and processing eras
+	tstamp := time.Now().Unix()
+	procDS := ProcessedDatasets{PROCESSED_DS_NAME: procds}
+	processedDatasetID, err := GetRecID(tx, &procDS, "PROCESSED_DATASETS", "processed_ds_id", "processed_ds_name", procds)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find processed_ds_id for %s", procds)
+		return Error(err, GetProcessedDatasetIDErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+	aera := AcquisitionEras{
+		ACQUISITION_ERA_NAME: era,
+		START_DATE:           tstamp,
+		CREATION_DATE:        tstamp,
+		CREATE_BY:            a.CreateBy,
+	}
+	acquisitionEraID, err := GetRecID(tx, &aera, "ACQUISITION_ERAS", "acquisition_era_id", "acquisition_era_name", era)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find acquisition_era_id for %s", era)
+		return Error(err, GetAcquisitionEraIDErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+	pera := ProcessingEras{
+		PROCESSING_VERSION: version,
+		CREATION_DATE:      tstamp,
+		CREATE_BY:          a.CreateBy,
+	}
+	processingEraID, err := GetRecID(tx, &pera, "PROCESSING_ERAS", "processing_era_id", "processing_version", version)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find processing_era_id for %d", version)
+		return Error(err, GetProcessingEraIDErrorCode, msg, "dbs.rename.RenameDataset")
+	}
+
+	// rename dataset
+	stm := getSQL("rename_dataset")
+	args := []interface{}{rec.NewDataset, processedDatasetID, acquisitionEraID, processingEraID, a.CreateBy, tstamp, datasetID}
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	if _, err := tx.Exec(stm, args...); err != nil {
+		return Error(err, RenameDatasetErrorCode, "unable to rename dataset", "dbs.rename.RenameDataset")
+	}
+
+	// rename blocks keeping their #uuid suffix
+	blocks, err := datasetBlocks(tx, datasetID)
+	if err != nil {
+		return err
+	}
+	report := RenameReport{Dataset: rec.Dataset, NewDataset: rec.NewDataset, Blocks: make(map[string]string)}
+	stm = getSQL("rename_block")
+	for bid, blk := range blocks {
+		parts := strings.SplitN(blk, "#", 2)
+		if len(parts) != 2 {
+			msg := fmt.Sprintf("block %s does not have #uuid suffix", blk)
+			return Error(errors.New(msg), RenameDatasetErrorCode, msg, "dbs.rename.RenameDataset")
+		}
+		newBlock := fmt.Sprintf("%s#%s", rec.NewDataset, parts[1])
+		if _, err := tx.Exec(stm, newBlock, a.CreateBy, tstamp, bid); err != nil {
+			msg := fmt.Sprintf("unable to rename block %s", blk)
+			return Error(err, RenameDatasetErrorCode, msg, "dbs.rename.RenameDataset")
+		}
+		report.Blocks[blk] = newBlock
+	}
+
+	// the new name is no longer an old name of renamed dataset
+	stm = getSQL("delete_dataset_renames")
+	if _, err := tx.Exec(stm, rec.NewDataset); err != nil {
+		return Error(err, RenameDatasetErrorCode, "unable to update dataset renames", "dbs.rename.RenameDataset")
+	}
+	rename := DatasetRename{
+		DATASET_ID:  datasetID,
+		OLD_DATASET: rec.Dataset,
+		NEW_DATASET: rec.NewDataset,
+		REASON:      rec.Reason,
+		RENAMED_BY:  a.CreateBy,
+		RENAME_DATE: tstamp,
+	}
+	if err := rename.Insert(tx); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println("fail to commit transaction", err)
+		return Error(err, CommitErrorCode, "unable to commit dataset rename", "dbs.rename.RenameDataset")
+	}
+	resetDatasetRenames()
+	log.Printf("dataset %s renamed to %s by %s", rec.Dataset, rec.NewDataset, a.CreateBy)
+
+	data, err = json.Marshal([]RenameReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to marshal rename report", "dbs.rename.RenameDataset")
+	}
+	if a.Writer != nil {
+		a.Writer.Write(data)
+	}
+	return nil
+}
+
+// helper function to get block ids and names of given dataset
+func datasetBlocks(tx *sql.Tx, datasetID int64) (map[int64]string, error) {
+	stm := getSQL("blocks4rename")
+	rows, err := tx.Query(stm, datasetID)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "unable to query dataset blocks", "dbs.rename.datasetBlocks")
+	}
+	defer rows.Close()
+	blocks := make(map[int64]string)
+	for rows.Next() {
+		var bid int64
+		var blk string
+		if err := rows.Scan(&bid, &blk); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "unable to scan dataset blocks", "dbs.rename.datasetBlocks")
+		}
+		blocks[bid] = blk
+	}
+	return blocks, nil
+}
+
+// Insert implementation of DatasetRename
+func (r *DatasetRename) Insert(tx *sql.Tx) error {
+	var tid int64
+	var err error
+	if r.RENAME_ID == 0 {
+		if DBOWNER == "sqlite" {
+			tid, err = LastInsertID(tx, "DATASET_RENAMES", "rename_id")
+			r.RENAME_ID = tid + 1
+		} else {
+			tid, err = IncrementSequence(tx, "SEQ_DSR")
+			r.RENAME_ID = tid
+		}
+		if err != nil {
+			return Error(err, LastInsertErrorCode, "unable to increment dataset renames sequence number", "dbs.rename.Insert")
+		}
+	}
+	// get SQL statement from static area
+	stm := getSQL("insert_dataset_renames")
+	if utils.VERBOSE > 0 {
+		log.Printf("Insert DatasetRename\n%s\n%+v", stm, r)
+	}
+	_, err = tx.Exec(stm,
+		r.RENAME_ID,
+		r.DATASET_ID,
+		r.OLD_DATASET,
+		r.NEW_DATASET,
+		r.REASON,
+		r.RENAMED_BY,
+		r.RENAME_DATE)
+	if err != nil {
+		if utils.VERBOSE > 0 {
+			log.Println("unable to insert DatasetRename", err)
+		}
+		return Error(err, InsertErrorCode, "unable to insert dataset renames record", "dbs.rename.Insert")
+	}
+	return nil
+}
+
+// DatasetRenames DBS API lists old to new dataset name mappings
+func (a *API) DatasetRenames() error {
+	var args []interface{}
+	var conds []string
+
+	conds, args = AddParam("old_dataset", "DR.OLD_DATASET", a.Params, conds, args)
+	conds, args = AddParam("new_dataset", "DR.NEW_DATASET", a.Params, conds, args)
+
+	// get SQL statement from static area
+	stm := getSQL("dataset_renames")
+	stm = WhereClause(stm, conds)
+	stm += " ORDER BY DR.RENAME_ID"
+
+	// use generic query API to fetch the results from DB
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "unable to query dataset renames", "dbs.rename.DatasetRenames")
+	}
+	return nil
+}
+
+// datasetRenames holds cache of old to new dataset names
+var datasetRenames struct {
+	sync.Mutex
+	mapping map[string]string
+	expire  int64
+}
+
+// helper function to reset cache of dataset renames
+func resetDatasetRenames() {
+	datasetRenames.Lock()
+	datasetRenames.expire = 0
+	datasetRenames.Unlock()
+}
+
+// helper function to get cached mapping of old to new dataset names
+func renamedDatasets() map[string]string {
+	datasetRenames.Lock()
+	defer datasetRenames.Unlock()
+	now := time.Now().Unix()
+	if datasetRenames.mapping != nil && now < datasetRenames.expire {
+		return datasetRenames.mapping
+	}
+	mapping := make(map[string]string)
+	stm := getSQL("dataset_renames_mapping")
+	rows, err := DB.Query(stm)
+	if err != nil {
+		// DB without dataset renames table, we'll not resolve old names
+		log.Println("unable to load dataset renames", err)
+	} else {
+		defer rows.Close()
+		for rows.Next() {
+			var old, name string
+			if err := rows.Scan(&old, &name); err != nil {
+				log.Println("unable to scan dataset renames", err)
+				break
+			}
+			mapping[old] = name
+		}
+	}
+	datasetRenames.mapping = mapping
+	datasetRenames.expire = now + DatasetRenamesTTL
+	return mapping
+}
+
+// helper function to resolve old dataset name into its current name
+func resolveDataset(mapping map[string]string, dataset string) (string, bool) {
+	name, ok := mapping[dataset]
+	if !ok {
+		return dataset, false
+	}
+	// dataset may be renamed several times
+	for i := 0; i < len(mapping); i++ {
+		next, ok := mapping[name]
+		if !ok {
+			break
+		}
+		name = next
+	}
+	return name, true
+}
+
+// helper function to check if dataset with given name exists in DBS, the old
+// name of renamed dataset may be reused by newly injected dataset
+func datasetExists(dataset string) bool {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Printf("unable to look-up dataset %s, error %v", dataset, err)
+		return false
+	}
+	defer tx.Rollback()
+	_, err = GetID(tx, "DATASETS", "dataset_id", "dataset", dataset)
+	if err != nil && !strings.Contains(err.Error(), "no rows in result set") {
+		log.Printf("unable to look-up dataset %s, error %v", dataset, err)
+	}
+	return err == nil
+}
+
+// ResolveRenames replaces old names of renamed datasets in dataset and
+// block_name parameters by their current names and returns list of
+// redirect hints in "old -> new" form. The names of existing datasets are
+// not resolved.
+func ResolveRenames(params Record) []string {
+	var hints []string
+	if DB == nil {
+		return hints
+	}
+	var mapping map[string]string
+	for _, key := range []string{"dataset", "block_name"} {
+		vals := getValues(params, key)
+		if len(vals) != 1 || vals[0] == "" || strings.Contains(vals[0], "*") {
+			continue
+		}
+		if mapping == nil {
+			mapping = renamedDatasets()
+		}
+		if len(mapping) == 0 {
+			return hints
+		}
+		val := vals[0]
+		dataset, suffix := val, ""
+		if idx := strings.Index(val, "#"); key == "block_name" && idx > 0 {
+			dataset, suffix = val[:idx], val[idx:]
+		}
+		name, ok := resolveDataset(mapping, dataset)
+		if !ok || datasetExists(dataset) {
+			continue
+		}
+		params[key] = []string{name + suffix}
+		hints = append(hints, fmt.Sprintf("%s -> %s", val, name+suffix))
+	}
+	return hints
+}
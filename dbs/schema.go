@@ -0,0 +1,210 @@
+package dbs
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// PostRecords maps DBS APIs to record structures they accept as POST body
+var PostRecords = map[string]interface{}{
+	"datatiers":               DataTiers{},
+	"datasetaccesstypes":      DatasetAccessTypes{},
+	"physicsgroups":           PhysicsGroups{},
+	"primarydatasets":         PrimaryDatasetRecord{},
+	"acquisitioneras":         AcquisitionEras{},
+	"processingeras":          ProcessingEras{},
+	"outputconfigs":           OutputConfigRecord{},
+	"datasets":                DatasetRecord{},
+	"blocks":                  BlockRecord{},
+	"bulkblocks":              BulkBlocks{},
+	"files":                   PyFileRecord{},
+	"fileparents":             FileParentBlockRecord{},
+	"insertfileparentsbylumi": FileParentsByLumiRecord{},
+	"submit":                  MigrationRequest{},
+	"plan":                    MigrationRequest{},
+	"cancel":                  MigrationRemoveRequest{},
+	"remove":                  MigrationRemoveRequest{},
+	"delete":                  DeleteRecord{},
+	"renamedataset":           RenameRecord{},
+	"tags":                    TagRecord{},
+}
+
+// PutParameters maps DBS APIs to parameters they accept via PUT request
+var PutParameters = map[string][]string{
+	"datasets":        {"dataset", "dataset_access_type", "physics_group_name"},
+	"blocks":          {"block_name", "open_for_writing", "origin_site_name"},
+	"files":           {"logical_file_name", "is_file_valid", "dataset", "block_name", "run_num", "lumi_list"},
+	"acquisitioneras": {"acquisition_era_name", "end_date"},
+}
+
+// ServerAttributes lists record attributes which are assigned by DBS server,
+// they are not required from clients even though records require them
+var ServerAttributes = []string{
+	"create_by", "creation_date", "last_modified_by", "last_modification_date",
+}
+
+// ParameterSchema represents schema of single API parameter
+type ParameterSchema struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Patterns []string `json:"patterns,omitempty"`
+	Length   int      `json:"max_length,omitempty"`
+}
+
+// ApiSchema represents schema of DBS API for given HTTP method
+type ApiSchema struct {
+	Api        string            `json:"api"`
+	Method     string            `json:"method"`
+	Parameters []ParameterSchema `json:"parameters"`
+	Body       Record            `json:"body,omitempty"`
+}
+
+// ParameterType returns type of given DBS API parameter
+func ParameterType(param string) string {
+	if utils.InList(param, intParameters) {
+		return "integer"
+	} else if utils.InList(param, mixParameters) {
+		return "string|integer"
+	}
+	return "string"
+}
+
+// helper function to find lexicon of given parameter
+func parameterLexicon(param string) (Lexicon, bool) {
+	lkey := param
+	if v, ok := payloadLexicon[param]; ok {
+		lkey = v
+	}
+	if p, ok := LexiconPatterns[lkey]; ok {
+		return p.Lexicon, true
+	}
+	return Lexicon{}, false
+}
+
+// NewParameterSchema returns schema of given DBS API parameter
+func NewParameterSchema(param string) ParameterSchema {
+	rec := ParameterSchema{Name: param, Type: ParameterType(param)}
+	if lex, ok := parameterLexicon(param); ok {
+		rec.Patterns = lex.Patterns
+		rec.Length = lex.Length
+	}
+	return rec
+}
+
+// NewApiSchema returns schema of given DBS API and HTTP method. The GET
+// parameters are taken from API parameters file, the POST body schema is
+// generated from record structure used by the API.
+func NewApiSchema(api, method string) ApiSchema {
+	rec := ApiSchema{Api: api, Method: method, Parameters: []ParameterSchema{}}
+	var params []string
+	switch method {
+	case "GET":
+		params = ApiParamMap[api]
+	case "PUT":
+		params = PutParameters[api]
+	case "POST":
+		if v, ok := PostRecords[api]; ok {
+			rec.Body = JSONSchema(v)
+		} else {
+			// reader POST APIs accept their parameters as JSON payload
+			params = ApiParamMap[api]
+		}
+	}
+	for _, p := range params {
+		rec.Parameters = append(rec.Parameters, NewParameterSchema(p))
+	}
+	return rec
+}
+
+// JSONSchema generates JSON Schema of given record structure, the string
+// attributes are constrained by DBS lexicon patterns and lengths
+func JSONSchema(rec interface{}) Record {
+	schema := jsonSchemaType(reflect.TypeOf(rec), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = reflect.TypeOf(rec).Name()
+	return schema
+}
+
+// LexiconSchema returns JSON Schema of string constrained by given lexicon
+// patterns and maximum length, multiple patterns are joined into alternation
+func LexiconSchema(patterns []string, length int) Record {
+	schema := Record{"type": "string"}
+	if len(patterns) == 1 {
+		schema["pattern"] = patterns[0]
+	} else if len(patterns) > 1 {
+		schema["pattern"] = "(" + strings.Join(patterns, ")|(") + ")"
+	}
+	if length > 0 {
+		schema["maxLength"] = length
+	}
+	return schema
+}
+
+// helper function to generate JSON Schema of given type and JSON key
+func jsonSchemaType(t reflect.Type, key string) Record {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchemaType(t.Elem(), key)
+	case reflect.Struct:
+		properties := make(Record)
+		var required []string
+		jsonSchemaFields(t, properties, &required)
+		schema := Record{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return Record{"type": "array", "items": jsonSchemaType(t.Elem(), key)}
+	case reflect.Map:
+		return Record{"type": "object"}
+	case reflect.String:
+		if lex, ok := parameterLexicon(key); ok && key != "" {
+			return LexiconSchema(lex.Patterns, lex.Length)
+		}
+		return Record{"type": "string"}
+	case reflect.Bool:
+		return Record{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Record{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Record{"type": "number"}
+	}
+	// interface types may hold any value
+	return Record{}
+}
+
+// helper function to collect JSON Schema properties of struct fields
+func jsonSchemaFields(t reflect.Type, properties Record, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			jsonSchemaFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchemaType(field.Type, name)
+		validate := field.Tag.Get("validate")
+		if strings.Contains(validate, "required") &&
+			!strings.HasSuffix(name, "_id") &&
+			!utils.InList(name, ServerAttributes) {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package dbs
+
+// tags.go - provides APIs to manage key/value tags of datasets and blocks
+//
+// Tags hold free-form metadata which DBS does not model otherwise, e.g.
+// campaign or physics annotation. Every dataset or block may have a single
+// value per tag key; tags are stored in DATASET_TAGS and BLOCK_TAGS tables,
+// they can be used to select datasets and blocks via tag parameter and they
+// are part of detailed output of datasets and blocks APIs.
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmwm/dbs2go/utils"
+)
+
+// MaxTagValueLength defines maximum length of tag value
+const MaxTagValueLength = 1000
+
+// TagRecord represents input record of Tags API, it is also used as
+// output record which holds all tags of dataset or block
+// Dataset: name of dataset to tag
+// BlockName: name of block to tag
+// Tags: tags to set, existing values of the same keys are replaced
+// Remove: keys of tags to remove
+type TagRecord struct {
+	Dataset   string            `json:"dataset,omitempty"`
+	BlockName string            `json:"block_name,omitempty"`
+	Tags      map[string]string `json:"tags"`
+	Remove    []string          `json:"remove,omitempty"`
+}
+
+// tagScope represents tags table of dataset or block
+type tagScope struct {
+	Table  string // DATASET_TAGS or BLOCK_TAGS table
+	Column string // DATASET_ID or BLOCK_ID column of tags table
+}
+
+// dataset and block tag scopes
+var (
+	datasetTags = tagScope{Table: "DATASET_TAGS", Column: "DATASET_ID"}
+	blockTags   = tagScope{Table: "BLOCK_TAGS", Column: "BLOCK_ID"}
+)
+
+// helper function to validate tag record
+func (r *TagRecord) validate() error {
+	if (r.Dataset == "") == (r.BlockName == "") {
+		msg := "Tags API requires either dataset or block_name"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+	}
+	if len(r.Tags) == 0 && len(r.Remove) == 0 {
+		msg := "Tags API requires tags to set or remove"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+	}
+	for key, val := range r.Tags {
+		if err := CheckPattern("tag_key", key); err != nil {
+			msg := fmt.Sprintf("invalid tag key '%s'", key)
+			return Error(err, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+		}
+		if len(val) > MaxTagValueLength {
+			msg := fmt.Sprintf("value of tag '%s' exceeds %d characters", key, MaxTagValueLength)
+			return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+		}
+	}
+	for _, key := range r.Remove {
+		if err := CheckPattern("tag_key", key); err != nil {
+			msg := fmt.Sprintf("invalid tag key '%s'", key)
+			return Error(err, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+		}
+		if _, ok := r.Tags[key]; ok {
+			msg := fmt.Sprintf("tag '%s' can not be set and removed at the same time", key)
+			return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.validate")
+		}
+	}
+	return nil
+}
+
+// UpdateTags DBS API sets and removes tags of dataset or block
+//
+//gocyclo:ignore
+func (a *API) UpdateTags() error {
+	// read given input
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		log.Println("fail to read data", err)
+		return Error(err, ReaderErrorCode, "unable to read tag record", "dbs.tags.UpdateTags")
+	}
+	var rec TagRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		log.Println("fail to decode data as TagRecord", err)
+		return Error(err, UnmarshalErrorCode, "unable to decode tag record", "dbs.tags.UpdateTags")
+	}
+	if utils.VERBOSE > 0 {
+		log.Printf("UpdateTags record %+v", rec)
+	}
+	if err := rec.validate(); err != nil {
+		return err
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "transaction error", "dbs.tags.UpdateTags")
+	}
+	defer tx.Rollback()
+
+	var eid int64
+	scope := datasetTags
+	if rec.BlockName != "" {
+		scope = blockTags
+		eid, err = GetID(tx, "BLOCKS", "block_id", "block_name", rec.BlockName)
+		if err != nil {
+			msg := fmt.Sprintf("unable to find block %s", rec.BlockName)
+			return Error(err, GetBlockIDErrorCode, msg, "dbs.tags.UpdateTags")
+		}
+	} else {
+		eid, err = GetID(tx, "DATASETS", "dataset_id", "dataset", rec.Dataset)
+		if err != nil {
+			msg := fmt.Sprintf("unable to find dataset %s", rec.Dataset)
+			return Error(err, GetDatasetIDErrorCode, msg, "dbs.tags.UpdateTags")
+		}
+	}
+
+	// remove tags which are either removed or replaced by new values
+	keys := append([]string{}, rec.Remove...)
+	for key := range rec.Tags {
+		keys = append(keys, key)
+	}
+	stm, err := scope.sql("delete_tags", Record{})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if utils.VERBOSE > 1 {
+			utils.PrintSQL(stm, []interface{}{eid, key}, "execute")
+		}
+		if _, err := tx.Exec(stm, eid, key); err != nil {
+			msg := fmt.Sprintf("unable to remove tag %s", key)
+			return Error(err, UpdateTagsErrorCode, msg, "dbs.tags.UpdateTags")
+		}
+	}
+
+	// insert new tags
+	stm = getSQL("insert_" + strings.ToLower(scope.Table))
+	tstamp := time.Now().Unix()
+	for key, val := range rec.Tags {
+		if utils.VERBOSE > 1 {
+			utils.PrintSQL(stm, []interface{}{eid, key, val, a.CreateBy, tstamp}, "execute")
+		}
+		if _, err := tx.Exec(stm, eid, key, val, a.CreateBy, tstamp); err != nil {
+			msg := fmt.Sprintf("unable to insert tag %s", key)
+			return Error(err, UpdateTagsErrorCode, msg, "dbs.tags.UpdateTags")
+		}
+	}
+	tags, err := scope.tags(tx, eid)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println("fail to commit transaction", err)
+		return Error(err, CommitErrorCode, "unable to commit tags", "dbs.tags.UpdateTags")
+	}
+
+	out := TagRecord{Dataset: rec.Dataset, BlockName: rec.BlockName, Tags: tags}
+	data, err = json.Marshal([]TagRecord{out})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "unable to marshal tags", "dbs.tags.UpdateTags")
+	}
+	if a.Writer != nil {
+		a.Writer.Write(data)
+	}
+	return nil
+}
+
+// Tags DBS API lists tags of datasets or blocks
+func (a *API) Tags() error {
+	var args []interface{}
+	var conds []string
+
+	var stm string
+	if _, e := getSingleValue(a.Params, "block_name"); e == nil {
+		stm = getSQL("block_tags")
+		conds, args = AddParam("block_name", "B.BLOCK_NAME", a.Params, conds, args)
+	} else if _, e := getSingleValue(a.Params, "dataset"); e == nil {
+		stm = getSQL("dataset_tags")
+		conds, args = AddParam("dataset", "D.DATASET", a.Params, conds, args)
+	} else {
+		msg := "Tags API requires either dataset or block_name"
+		return Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.Tags")
+	}
+	conds, args = AddParam("tag_key", "T.TAG_KEY", a.Params, conds, args)
+	stm = WhereClause(stm, conds)
+	stm += " ORDER BY T.TAG_KEY"
+
+	// use generic query API to fetch the results from DB
+	err := executeAll(a.Writer, a.Separator, a.fields(), stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "unable to query tags", "dbs.tags.Tags")
+	}
+	return nil
+}
+
+// helper function to load tag statement of given template for tags table
+// of the scope
+func (s tagScope) sql(name string, tmpl Record) (string, error) {
+	tmpl["Owner"] = DBOWNER
+	tmpl["Table"] = s.Table
+	tmpl["Column"] = s.Column
+	stm, err := LoadTemplateSQL(name, tmpl)
+	if err != nil {
+		msg := fmt.Sprintf("unable to load %s template", name)
+		return "", Error(err, LoadErrorCode, msg, "dbs.tags.sql")
+	}
+	return stm, nil
+}
+
+// helper function to get tags of given entity within transaction
+func (s tagScope) tags(tx *sql.Tx, eid int64) (map[string]string, error) {
+	stm, err := s.sql("entity_tags", Record{})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(stm, eid)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "unable to query tags", "dbs.tags.tags")
+	}
+	defer rows.Close()
+	return scanTags(rows)
+}
+
+// helper function to scan tag keys and values from given rows
+func scanTags(rows *sql.Rows) (map[string]string, error) {
+	tags := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var val sql.NullString
+		if err := rows.Scan(&key, &val); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "unable to scan tags", "dbs.tags.scanTags")
+		}
+		tags[key] = val.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error(err, RowsScanErrorCode, "unable to scan tags", "dbs.tags.scanTags")
+	}
+	return tags, nil
+}
+
+// extension returns record extension which adds tags to every record with
+// given id field, tags of all datasets or blocks selected by given statement
+// are fetched by single query before the records are read
+func (s tagScope) extension(idField, stm string, args []interface{}) (*recordExtension, error) {
+	tags, err := s.selectedTags(idField, stm, args)
+	if err != nil {
+		return nil, err
+	}
+	return &recordExtension{
+		Fields: []string{"tags"},
+		Extend: func(rec Record) {
+			// DB drivers may return different types of numeric values
+			val := fmt.Sprintf("%v", rec[idField])
+			eid, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return
+			}
+			if t, ok := tags[eid]; ok {
+				rec["tags"] = t
+			} else {
+				rec["tags"] = map[string]string{}
+			}
+		},
+	}, nil
+}
+
+// helper function to get tags of datasets or blocks selected by given
+// statement, the tags are keyed by id of dataset or block
+func (s tagScope) selectedTags(idField, stm string, args []interface{}) (map[int64]map[string]string, error) {
+	tmpl := Record{"IdField": strings.ToUpper(idField), "Query": stm}
+	stm, err := s.sql("selected_tags", tmpl)
+	if err != nil {
+		return nil, err
+	}
+	stm = CleanStatement(stm)
+	tags := make(map[int64]map[string]string)
+	if DRYRUN {
+		utils.PrintSQL(stm, args, "")
+		return tags, nil
+	}
+	if utils.VERBOSE > 1 {
+		utils.PrintSQL(stm, args, "execute")
+	}
+	rows, err := DB.Query(stm, args...)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "unable to query tags", "dbs.tags.selectedTags")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eid int64
+		var key string
+		var val sql.NullString
+		if err := rows.Scan(&eid, &key, &val); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "unable to scan tags", "dbs.tags.selectedTags")
+		}
+		if _, ok := tags[eid]; !ok {
+			tags[eid] = make(map[string]string)
+		}
+		tags[eid][key] = val.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Error(err, RowsScanErrorCode, "unable to scan tags", "dbs.tags.selectedTags")
+	}
+	return tags, nil
+}
+
+// helper function to return table name with DB owner
+func ownerTable(table string) string {
+	if DBOWNER == "sqlite" {
+		return table
+	}
+	return fmt.Sprintf("%s.%s", DBOWNER, table)
+}
+
+// condition returns where clause condition along with its bind values which
+// selects datasets or blocks with given tag, the tag is either key or
+// key=value pair and both key and value may contain wildcards
+func (s tagScope) condition(col, tag string, idx int) (string, []interface{}, error) {
+	key, val, hasValue := strings.Cut(tag, "=")
+	if key == "" {
+		msg := fmt.Sprintf("invalid tag '%s', expect key or key=value", tag)
+		return "", nil, Error(InvalidParamErr, InvalidParameterErrorCode, msg, "dbs.tags.condition")
+	}
+	op, key := OperatorValue(key)
+	cond := fmt.Sprintf(" %s IN (SELECT %s FROM %s WHERE TAG_KEY %s %s",
+		col, s.Column, ownerTable(s.Table), op, placeholder(fmt.Sprintf("tag_key_%d", idx)))
+	args := []interface{}{key}
+	if hasValue {
+		op, val = OperatorValue(val)
+		cond += fmt.Sprintf(" AND TAG_VALUE %s %s", op, placeholder(fmt.Sprintf("tag_value_%d", idx)))
+		args = append(args, val)
+	}
+	return cond + ")", args, nil
+}
+
+// helper function to add conditions of tag parameters, multiple tags
+// should all be present
+func (s tagScope) addConditions(col string, params Record, conds []string, args []interface{}) ([]string, []interface{}, error) {
+	for idx, tag := range getValues(params, "tag") {
+		cond, vals, err := s.condition(col, tag, idx)
+		if err != nil {
+			return conds, args, err
+		}
+		conds = append(conds, cond)
+		args = append(args, vals...)
+	}
+	return conds, args, nil
+}
@@ -2,12 +2,14 @@ package dbs
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/user"
+	"sync"
 	"time"
 
 	"github.com/vkuznet/x509proxy"
@@ -72,14 +74,18 @@ func tlsCerts(key, cert string) ([]tls.Certificate, error) {
 type TLSCertsManager struct {
 	Certificates []tls.Certificate
 	Time         time.Time
+	mutex        sync.RWMutex
 }
 
 // TlsCerts provides access to TLS certificates for given key and certificate
 func (t *TLSCertsManager) TlsCerts(key, cert string) ([]tls.Certificate, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 	if t.Certificates == nil || time.Since(t.Time).Seconds() > float64(TlsRefreshInterval) {
 		certs, err := tlsCerts(key, cert)
 		if err == nil {
 			t.Certificates = certs
+			t.Time = time.Now()
 		} else {
 			return t.Certificates, err
 		}
@@ -87,17 +93,21 @@ func (t *TLSCertsManager) TlsCerts(key, cert string) ([]tls.Certificate, error)
 	return t.Certificates, nil
 }
 
+// Loaded returns loaded TLS certificates and time when they were loaded
+func (t *TLSCertsManager) Loaded() ([]tls.Certificate, time.Time) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.Certificates, t.Time
+}
+
 // tlsManager is a global tls certiticates manager
-var tlsManager *TLSCertsManager
+var tlsManager = &TLSCertsManager{}
 
 // HttpClient is HTTP client for urlfetch server
 func HttpClient(key, cert string, tout int) *http.Client {
 	var certs []tls.Certificate
 	var err error
 	// get X509 certs
-	if tlsManager == nil {
-		tlsManager = &TLSCertsManager{}
-	}
 	certs, err = tlsManager.TlsCerts(key, cert)
 	//     certs, err = tlsCerts(key, cert)
 	if err != nil {
@@ -120,25 +130,27 @@ func HttpClient(key, cert string, tout int) *http.Client {
 	return &http.Client{Transport: tr}
 }
 
-// helper function to perform HTTP GET request and return its data
-func getData(rurl string) ([]byte, error) {
-	var out []byte
-	client := HttpClient(Ckey, Cert, Timeout)
-	req, err := http.NewRequest("GET", rurl, nil)
-	if err != nil {
-		log.Printf("unable to get data for %s, error %v, http request %+v", rurl, err, req)
-		return out, Error(err, HttpRequestErrorCode, "", "dbs.utils.getData")
-	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Accept", "application/json")
-	resp, err := client.Do(req)
-	if err != nil {
-		return out, Error(err, HttpRequestErrorCode, "", "dbs.utils.getData")
-	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return data, Error(err, ReaderErrorCode, "", "dbs.utils.getData")
+// RemoteDBS represents DBS client of remote DBS server used by migration
+// code, it is implemented by Client type of client package which can not be
+// used here directly since it depends on dbs package record types
+type RemoteDBS interface {
+	BlockDump(blk string) (BulkBlocks, error)
+	Blocks(params url.Values) ([]Record, error)
+	BlockParents(params url.Values) ([]Record, error)
+	Datasets(params url.Values) ([]DatasetRecord, error)
+	DatasetParents(params url.Values) ([]Record, error)
+	FileSummaries(params url.Values) ([]Record, error)
+}
+
+// RemoteClient returns DBS client of remote DBS server with given url, it
+// should be set by the DBS server before migration APIs are used
+var RemoteClient func(rurl string) RemoteDBS
+
+// helper function to get DBS client of remote DBS server
+func remoteDBS(rurl string) (RemoteDBS, error) {
+	if RemoteClient == nil {
+		err := errors.New("DBS client of remote DBS servers is not set")
+		return nil, Error(err, HttpRequestErrorCode, "", "dbs.utils.remoteDBS")
 	}
-	return data, nil
+	return RemoteClient(rurl), nil
 }
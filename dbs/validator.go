@@ -3,7 +3,6 @@ package dbs
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"regexp"
@@ -75,11 +74,12 @@ type LexiconPattern struct {
 // LexiconPatterns represents CMS Lexicon patterns
 var LexiconPatterns map[string]LexiconPattern
 
-// LoadPatterns loads CMS Lexion patterns from given file
+// LoadPatterns loads CMS Lexion patterns from given file, the file name
+// without directory is looked up in DBS static files (see utils.StaticFS)
 // the format of the file is a list of the following dicts:
 // [ {"name": <name>, "patterns": [list of patterns], "length": int},...]
 func LoadPatterns(fname string) (map[string]LexiconPattern, error) {
-	data, err := ioutil.ReadFile(fname)
+	data, err := utils.ReadStaticFile(fname)
 	if err != nil {
 		log.Printf("Unable to read, file '%s', error: %v\n", fname, err)
 		return nil, Error(err, ReaderErrorCode, "", "dbs.validator.LoadPatterns")
@@ -29,12 +29,34 @@ func main() {
 	flag.StringVar(&config, "config", "config.json", "dbs2go config file")
 	var version bool
 	flag.BoolVar(&version, "version", false, "Show version")
+	var export string
+	flag.StringVar(&export, "export", "", "export migration bundle of given dataset or block")
+	var rurl string
+	flag.StringVar(&rurl, "url", "", "DBS url to export migration bundle from")
+	var bundle string
+	flag.StringVar(&bundle, "bundle", "bundle.ndjson.gz", "migration bundle file")
+	var imp bool
+	flag.BoolVar(&imp, "import", false, "import migration bundle into DBS defined in config file")
 	flag.Parse()
 	if version {
 		fmt.Println(info())
 		os.Exit(0)
 
 	}
+	if export != "" {
+		if err := web.ExportBundle(rurl, export, bundle); err != nil {
+			fmt.Println("unable to export migration bundle, error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if imp {
+		if err := web.ImportBundle(config, bundle); err != nil {
+			fmt.Println("unable to import migration bundle, error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	web.GitVersion = gitVersion
 	web.ServerInfo = info()
 	web.Server(config)
@@ -547,6 +547,10 @@ CREATE TABLE `MIGRATION_REQUESTS` (
     `CREATE_BY` VARCHAR(100),
     `LAST_MODIFICATION_DATE` INTEGER,
     `LAST_MODIFIED_BY` VARCHAR(100),
+    `LEASE_EXPIRATION_DATE` INTEGER,
+    `PRIORITY` INTEGER DEFAULT 0,
+    `NEXT_RETRY_DATE` INTEGER,
+    `CALLBACK_URL` VARCHAR(700),
     CONSTRAINT `PK_MR` PRIMARY KEY (`MIGRATION_REQUEST_ID`),
     CONSTRAINT `TUC_MR_1` UNIQUE (`MIGRATION_URL`, `MIGRATION_INPUT`)
 )
@@ -566,6 +570,13 @@ CREATE TABLE `MIGRATION_BLOCKS` (
     `CREATE_BY` VARCHAR(100),
     `LAST_MODIFICATION_DATE` INTEGER,
     `LAST_MODIFIED_BY` VARCHAR(100),
+    `ORIGIN_REQUEST_ID` INTEGER,
+    `ATTEMPT_COUNT` INTEGER DEFAULT 0,
+    `START_DATE` INTEGER,
+    `END_DATE` INTEGER,
+    `LAST_ERROR` VARCHAR(4000),
+    `FILE_COUNT` INTEGER,
+    `BLOCK_SIZE` BIGINT,
     CONSTRAINT `PK_MB` PRIMARY KEY (`MIGRATION_BLOCK_ID`),
     CONSTRAINT `TUC_MB_1` UNIQUE (`MIGRATION_BLOCK_NAME`, `MIGRATION_REQUEST_ID`)
 )
@@ -584,6 +595,72 @@ CREATE TABLE `COMPONENT_STATUS` (
 )
 ENGINE = InnoDB ;
 
+# ---------------------------------------------------------------------- #
+# Add table "DELETION_AUDIT"                                             #
+# ---------------------------------------------------------------------- #
+
+CREATE TABLE `DELETION_AUDIT` (
+    `DELETION_ID` INTEGER NOT NULL,
+    `ENTITY_TYPE` VARCHAR(20),
+    `ENTITY_NAME` VARCHAR(700),
+    `DELETION_MODE` VARCHAR(20),
+    `ROW_COUNTS` VARCHAR(4000),
+    `REASON` VARCHAR(1000),
+    `DELETED_BY` VARCHAR(500),
+    `DELETION_DATE` INTEGER,
+    CONSTRAINT `PK_DLA` PRIMARY KEY (`DELETION_ID`)
+)
+ENGINE = InnoDB ;
+
+# ---------------------------------------------------------------------- #
+# Add table "DATASET_RENAMES"                                            #
+# ---------------------------------------------------------------------- #
+
+CREATE TABLE `DATASET_RENAMES` (
+    `RENAME_ID` INTEGER NOT NULL,
+    `DATASET_ID` INTEGER,
+    `OLD_DATASET` VARCHAR(700) NOT NULL,
+    `NEW_DATASET` VARCHAR(700) NOT NULL,
+    `REASON` VARCHAR(1000),
+    `RENAMED_BY` VARCHAR(500),
+    `RENAME_DATE` INTEGER,
+    CONSTRAINT `PK_DSR` PRIMARY KEY (`RENAME_ID`),
+    CONSTRAINT `TUC_DSR_1` UNIQUE (`OLD_DATASET`)
+)
+ENGINE = InnoDB ;
+
+# ---------------------------------------------------------------------- #
+# Add table "DATASET_TAGS"                                               #
+# ---------------------------------------------------------------------- #
+
+CREATE TABLE `DATASET_TAGS` (
+    `DATASET_ID` INTEGER NOT NULL,
+    `TAG_KEY` VARCHAR(100) NOT NULL,
+    `TAG_VALUE` VARCHAR(1000),
+    `CREATE_BY` VARCHAR(500),
+    `CREATION_DATE` INTEGER,
+    CONSTRAINT `PK_DTG` PRIMARY KEY (`DATASET_ID`, `TAG_KEY`)
+)
+ENGINE = InnoDB ;
+
+CREATE INDEX `IDX_DTG_1` ON `DATASET_TAGS` (`TAG_KEY`);
+
+# ---------------------------------------------------------------------- #
+# Add table "BLOCK_TAGS"                                                 #
+# ---------------------------------------------------------------------- #
+
+CREATE TABLE `BLOCK_TAGS` (
+    `BLOCK_ID` INTEGER NOT NULL,
+    `TAG_KEY` VARCHAR(100) NOT NULL,
+    `TAG_VALUE` VARCHAR(1000),
+    `CREATE_BY` VARCHAR(500),
+    `CREATION_DATE` INTEGER,
+    CONSTRAINT `PK_BTG` PRIMARY KEY (`BLOCK_ID`, `TAG_KEY`)
+)
+ENGINE = InnoDB ;
+
+CREATE INDEX `IDX_BTG_1` ON `BLOCK_TAGS` (`TAG_KEY`);
+
 # ---------------------------------------------------------------------- #
 # Foreign key constraints                                                #
 # ---------------------------------------------------------------------- #
@@ -630,6 +707,12 @@ ALTER TABLE `BLOCK_PARENTS` ADD CONSTRAINT `BK_BP`
 ALTER TABLE `BLOCK_PARENTS` ADD CONSTRAINT `BK_BP2` 
     FOREIGN KEY (`PARENT_BLOCK_ID`) REFERENCES `BLOCKS` (`BLOCK_ID`) ON DELETE CASCADE;
 
+ALTER TABLE `BLOCK_TAGS` ADD CONSTRAINT `BK_BTG` 
+    FOREIGN KEY (`BLOCK_ID`) REFERENCES `BLOCKS` (`BLOCK_ID`) ON DELETE CASCADE;
+
+ALTER TABLE `DATASET_TAGS` ADD CONSTRAINT `DS_DTG` 
+    FOREIGN KEY (`DATASET_ID`) REFERENCES `DATASETS` (`DATASET_ID`) ON DELETE CASCADE;
+
 ALTER TABLE `FILES` ADD CONSTRAINT `DS_FL` 
     FOREIGN KEY (`DATASET_ID`) REFERENCES `DATASETS` (`DATASET_ID`) ON DELETE CASCADE;
 
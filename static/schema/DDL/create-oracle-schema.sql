@@ -308,6 +308,24 @@ CREATE SEQUENCE SEQ_MR
     CACHE 5000
     noorder;
 
+CREATE SEQUENCE SEQ_DLA
+    START WITH 1
+    INCREMENT BY 1
+    NOMINVALUE
+    NOMAXVALUE
+    nocycle
+    CACHE 5000
+    noorder;
+
+CREATE SEQUENCE SEQ_DSR
+    START WITH 1
+    INCREMENT BY 1
+    NOMINVALUE
+    NOMAXVALUE
+    nocycle
+    CACHE 5000
+    noorder;
+
 CREATE SEQUENCE SEQ_CS
     START WITH 1
     INCREMENT BY 1
@@ -585,6 +603,10 @@ CREATE TABLE MIGRATION_REQUESTS (
     LAST_MODIFICATION_DATE INTEGER,
     LAST_MODIFIED_BY VARCHAR2(500),
     RETRY_COUNT INTEGER,
+    LEASE_EXPIRATION_DATE INTEGER,
+    PRIORITY INTEGER DEFAULT 0,
+    NEXT_RETRY_DATE INTEGER,
+    CALLBACK_URL VARCHAR2(700),
     CONSTRAINT PK_MR PRIMARY KEY (MIGRATION_REQUEST_ID),
     CONSTRAINT TUC_MR_1 UNIQUE (MIGRATION_INPUT)
 );
@@ -606,6 +628,13 @@ CREATE TABLE MIGRATION_BLOCKS (
     CREATE_BY VARCHAR2(500),
     LAST_MODIFICATION_DATE INTEGER,
     LAST_MODIFIED_BY VARCHAR2(500),
+    ORIGIN_REQUEST_ID INTEGER,
+    ATTEMPT_COUNT INTEGER DEFAULT 0,
+    START_DATE INTEGER,
+    END_DATE INTEGER,
+    LAST_ERROR VARCHAR2(4000),
+    FILE_COUNT INTEGER,
+    BLOCK_SIZE INTEGER,
     CONSTRAINT PK_MB PRIMARY KEY (MIGRATION_BLOCK_ID),
     CONSTRAINT TUC_MB_1 UNIQUE (MIGRATION_BLOCK_NAME, MIGRATION_REQUEST_ID)
 );
@@ -613,6 +642,43 @@ GRANT SELECT ON MIGRATION_BLOCKS TO CMS_DBS3_READ_ROLE;
 GRANT INSERT, UPDATE, DELETE ON MIGRATION_BLOCKS TO CMS_DBS3_WRITE_ROLE;
 GRANT DELETE ON MIGRATION_BLOCKS TO CMS_DBS3_ADMIN_ROLE;
 
+/* ---------------------------------------------------------------------- */
+/* Add table "DELETION_AUDIT"                                             */
+/* ---------------------------------------------------------------------- */
+
+CREATE TABLE DELETION_AUDIT (
+    DELETION_ID INTEGER CONSTRAINT NN_DLA_DELETION_ID NOT NULL,
+    ENTITY_TYPE VARCHAR2(20),
+    ENTITY_NAME VARCHAR2(700),
+    DELETION_MODE VARCHAR2(20),
+    ROW_COUNTS VARCHAR2(4000),
+    REASON VARCHAR2(1000),
+    DELETED_BY VARCHAR2(500),
+    DELETION_DATE INTEGER,
+    CONSTRAINT PK_DLA PRIMARY KEY (DELETION_ID)
+);
+GRANT SELECT ON DELETION_AUDIT TO CMS_DBS3_READ_ROLE;
+GRANT INSERT ON DELETION_AUDIT TO CMS_DBS3_ADMIN_ROLE;
+
+/* ---------------------------------------------------------------------- */
+/* Add table "DATASET_RENAMES"                                            */
+/* ---------------------------------------------------------------------- */
+
+CREATE TABLE DATASET_RENAMES (
+    RENAME_ID INTEGER CONSTRAINT NN_DSR_RENAME_ID NOT NULL,
+    DATASET_ID INTEGER,
+    OLD_DATASET VARCHAR2(700) CONSTRAINT NN_DSR_OLD_DATASET NOT NULL,
+    NEW_DATASET VARCHAR2(700) CONSTRAINT NN_DSR_NEW_DATASET NOT NULL,
+    REASON VARCHAR2(1000),
+    RENAMED_BY VARCHAR2(500),
+    RENAME_DATE INTEGER,
+    CONSTRAINT PK_DSR PRIMARY KEY (RENAME_ID),
+    CONSTRAINT TUC_DSR_1 UNIQUE (OLD_DATASET)
+);
+GRANT SELECT ON DATASET_RENAMES TO CMS_DBS3_READ_ROLE;
+GRANT INSERT ON DATASET_RENAMES TO CMS_DBS3_ADMIN_ROLE;
+GRANT DELETE ON DATASET_RENAMES TO CMS_DBS3_ADMIN_ROLE;
+
 /* ---------------------------------------------------------------------- */
 /* Add table "DATASETS"                                                   */
 /* ---------------------------------------------------------------------- */
@@ -714,6 +780,24 @@ GRANT DELETE ON BLOCK_PARENTS TO CMS_DBS3_ADMIN_ROLE;
 
 CREATE INDEX IDX_BP_1 ON BLOCK_PARENTS (PARENT_BLOCK_ID);
 
+/* ---------------------------------------------------------------------- */
+/* Add table "BLOCK_TAGS"                                                 */
+/* ---------------------------------------------------------------------- */
+
+CREATE TABLE BLOCK_TAGS (
+    BLOCK_ID INTEGER CONSTRAINT NN_BTG_BLOCK_ID NOT NULL,
+    TAG_KEY VARCHAR2(100) CONSTRAINT NN_BTG_TAG_KEY NOT NULL,
+    TAG_VALUE VARCHAR2(1000),
+    CREATE_BY VARCHAR2(500),
+    CREATION_DATE INTEGER,
+    CONSTRAINT PK_BTG PRIMARY KEY (BLOCK_ID, TAG_KEY)
+);
+GRANT SELECT ON BLOCK_TAGS TO CMS_DBS3_READ_ROLE;
+GRANT INSERT, UPDATE, DELETE ON BLOCK_TAGS TO CMS_DBS3_WRITE_ROLE;
+GRANT DELETE ON BLOCK_TAGS TO CMS_DBS3_ADMIN_ROLE;
+
+CREATE INDEX IDX_BTG_1 ON BLOCK_TAGS (TAG_KEY);
+
 /* ---------------------------------------------------------------------- */
 /* Add table "FILES"                                                      */
 /* ---------------------------------------------------------------------- */
@@ -797,6 +881,24 @@ GRANT DELETE ON DATASET_PARENTS TO CMS_DBS3_ADMIN_ROLE;
 
 CREATE INDEX IDX_DP_1 ON DATASET_PARENTS (PARENT_DATASET_ID);
 
+/* ---------------------------------------------------------------------- */
+/* Add table "DATASET_TAGS"                                               */
+/* ---------------------------------------------------------------------- */
+
+CREATE TABLE DATASET_TAGS (
+    DATASET_ID INTEGER CONSTRAINT NN_DTG_DATASET_ID NOT NULL,
+    TAG_KEY VARCHAR2(100) CONSTRAINT NN_DTG_TAG_KEY NOT NULL,
+    TAG_VALUE VARCHAR2(1000),
+    CREATE_BY VARCHAR2(500),
+    CREATION_DATE INTEGER,
+    CONSTRAINT PK_DTG PRIMARY KEY (DATASET_ID, TAG_KEY)
+);
+GRANT SELECT ON DATASET_TAGS TO CMS_DBS3_READ_ROLE;
+GRANT INSERT, UPDATE, DELETE ON DATASET_TAGS TO CMS_DBS3_WRITE_ROLE;
+GRANT DELETE ON DATASET_TAGS TO CMS_DBS3_ADMIN_ROLE;
+
+CREATE INDEX IDX_DTG_1 ON DATASET_TAGS (TAG_KEY);
+
 /* ---------------------------------------------------------------------- */
 /* Add table "DATASET_RUNS"                                               */
 /* ---------------------------------------------------------------------- */
@@ -940,6 +1042,12 @@ ALTER TABLE BLOCK_PARENTS ADD CONSTRAINT BK_BP
 ALTER TABLE BLOCK_PARENTS ADD CONSTRAINT BK_BP2 
     FOREIGN KEY (PARENT_BLOCK_ID) REFERENCES BLOCKS (BLOCK_ID) ON DELETE CASCADE;
 
+ALTER TABLE BLOCK_TAGS ADD CONSTRAINT BK_BTG 
+    FOREIGN KEY (BLOCK_ID) REFERENCES BLOCKS (BLOCK_ID) ON DELETE CASCADE;
+
+ALTER TABLE DATASET_TAGS ADD CONSTRAINT DS_DTG 
+    FOREIGN KEY (DATASET_ID) REFERENCES DATASETS (DATASET_ID) ON DELETE CASCADE;
+
 ALTER TABLE FILES ADD CONSTRAINT DS_FL 
     FOREIGN KEY (DATASET_ID) REFERENCES DATASETS (DATASET_ID) ON DELETE CASCADE;
 
@@ -1014,6 +1122,8 @@ GRANT SELECT ON SEQ_FP TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_FT TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_MB TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_MR TO CMS_DBS3_READ_ROLE;
+GRANT SELECT ON SEQ_DLA TO CMS_DBS3_READ_ROLE;
+GRANT SELECT ON SEQ_DSR TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_OMC TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_PDS TO CMS_DBS3_READ_ROLE;
 GRANT SELECT ON SEQ_PDT TO CMS_DBS3_READ_ROLE;
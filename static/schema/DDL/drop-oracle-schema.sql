@@ -41,6 +41,10 @@ ALTER TABLE BLOCK_PARENTS DROP CONSTRAINT BK_BP;
 
 ALTER TABLE BLOCK_PARENTS DROP CONSTRAINT BK_BP2;
 
+ALTER TABLE BLOCK_TAGS DROP CONSTRAINT BK_BTG;
+
+ALTER TABLE DATASET_TAGS DROP CONSTRAINT DS_DTG;
+
 ALTER TABLE FILES DROP CONSTRAINT DS_FL;
 
 ALTER TABLE FILES DROP CONSTRAINT BK_FL;
@@ -181,6 +185,22 @@ ALTER TABLE DATASET_PARENTS DROP CONSTRAINT PK_DP;
 
 DROP TABLE DATASET_PARENTS;
 
+/* ---------------------------------------------------------------------- */
+/* Drop table "DATASET_TAGS"                                              */
+/* ---------------------------------------------------------------------- */
+
+/* Drop constraints */
+
+ALTER TABLE DATASET_TAGS DROP CONSTRAINT NN_DTG_DATASET_ID;
+
+ALTER TABLE DATASET_TAGS DROP CONSTRAINT NN_DTG_TAG_KEY;
+
+ALTER TABLE DATASET_TAGS DROP CONSTRAINT PK_DTG;
+
+/* Drop table */
+
+DROP TABLE DATASET_TAGS;
+
 /* ---------------------------------------------------------------------- */
 /* Drop table "DATASET_OUTPUT_MOD_CONFIGS"                                */
 /* ---------------------------------------------------------------------- */
@@ -251,6 +271,22 @@ ALTER TABLE BLOCK_PARENTS DROP CONSTRAINT PK_BP;
 
 DROP TABLE BLOCK_PARENTS;
 
+/* ---------------------------------------------------------------------- */
+/* Drop table "BLOCK_TAGS"                                                */
+/* ---------------------------------------------------------------------- */
+
+/* Drop constraints */
+
+ALTER TABLE BLOCK_TAGS DROP CONSTRAINT NN_BTG_BLOCK_ID;
+
+ALTER TABLE BLOCK_TAGS DROP CONSTRAINT NN_BTG_TAG_KEY;
+
+ALTER TABLE BLOCK_TAGS DROP CONSTRAINT PK_BTG;
+
+/* Drop table */
+
+DROP TABLE BLOCK_TAGS;
+
 /* ---------------------------------------------------------------------- */
 /* Drop table "BLOCKS"                                                    */
 /* ---------------------------------------------------------------------- */
@@ -325,6 +361,40 @@ ALTER TABLE MIGRATION_BLOCKS DROP CONSTRAINT TUC_MB_1;
 
 DROP TABLE MIGRATION_BLOCKS;
 
+/* ---------------------------------------------------------------------- */
+/* Drop table "DATASET_RENAMES"                                           */
+/* ---------------------------------------------------------------------- */
+
+/* Drop constraints */
+
+ALTER TABLE DATASET_RENAMES DROP CONSTRAINT NN_DSR_RENAME_ID;
+
+ALTER TABLE DATASET_RENAMES DROP CONSTRAINT NN_DSR_OLD_DATASET;
+
+ALTER TABLE DATASET_RENAMES DROP CONSTRAINT NN_DSR_NEW_DATASET;
+
+ALTER TABLE DATASET_RENAMES DROP CONSTRAINT PK_DSR;
+
+ALTER TABLE DATASET_RENAMES DROP CONSTRAINT TUC_DSR_1;
+
+/* Drop table */
+
+DROP TABLE DATASET_RENAMES;
+
+/* ---------------------------------------------------------------------- */
+/* Drop table "DELETION_AUDIT"                                            */
+/* ---------------------------------------------------------------------- */
+
+/* Drop constraints */
+
+ALTER TABLE DELETION_AUDIT DROP CONSTRAINT NN_DLA_DELETION_ID;
+
+ALTER TABLE DELETION_AUDIT DROP CONSTRAINT PK_DLA;
+
+/* Drop table */
+
+DROP TABLE DELETION_AUDIT;
+
 /* ---------------------------------------------------------------------- */
 /* Drop table "MIGRATION_REQUESTS"                                        */
 /* ---------------------------------------------------------------------- */
@@ -689,6 +759,10 @@ DROP SEQUENCE SEQ_MB;
 
 DROP SEQUENCE SEQ_MR;
 
+DROP SEQUENCE SEQ_DLA;
+
+DROP SEQUENCE SEQ_DSR;
+
 DROP SEQUENCE SEQ_CS;
 
 DROP ROLE CMS_DBS3_READ_ROLE;
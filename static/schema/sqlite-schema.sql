@@ -59,6 +59,18 @@
 	 CONSTRAINT "PK_BP" PRIMARY KEY ("THIS_BLOCK_ID", "PARENT_BLOCK_ID")
    ) ;
 --------------------------------------------------------
+--  DDL for Table BLOCK_TAGS
+--------------------------------------------------------
+
+  CREATE TABLE "BLOCK_TAGS" 
+   (	"BLOCK_ID" INTEGER NOT NULL, 
+	"TAG_KEY" VARCHAR2(100) NOT NULL, 
+	"TAG_VALUE" VARCHAR2(1000), 
+	"CREATE_BY" VARCHAR2(500), 
+	"CREATION_DATE" INTEGER, 
+	 CONSTRAINT "PK_BTG" PRIMARY KEY ("BLOCK_ID", "TAG_KEY")
+   ) ;
+--------------------------------------------------------
 --  DDL for Table BRANCH_HASHES
 --------------------------------------------------------
 
@@ -116,6 +128,20 @@
 	 CONSTRAINT "PK_DP" PRIMARY KEY ("THIS_DATASET_ID", "PARENT_DATASET_ID")
    ) ;
 --------------------------------------------------------
+--  DDL for Table DATASET_RENAMES
+--------------------------------------------------------
+
+  CREATE TABLE "DATASET_RENAMES" 
+   (	"RENAME_ID" INTEGER, 
+	"DATASET_ID" INTEGER, 
+	"OLD_DATASET" VARCHAR2(700) NOT NULL, 
+	"NEW_DATASET" VARCHAR2(700) NOT NULL, 
+	"REASON" VARCHAR2(1000), 
+	"RENAMED_BY" VARCHAR2(500), 
+	"RENAME_DATE" INTEGER, 
+	 CONSTRAINT "TUC_DSR_1" UNIQUE ("OLD_DATASET")
+   ) ;
+--------------------------------------------------------
 --  DDL for Table DATASET_RUNS
 --------------------------------------------------------
 
@@ -129,6 +155,18 @@
 	"CREATE_BY" VARCHAR2(500)
    ) ;
 --------------------------------------------------------
+--  DDL for Table DATASET_TAGS
+--------------------------------------------------------
+
+  CREATE TABLE "DATASET_TAGS" 
+   (	"DATASET_ID" INTEGER NOT NULL, 
+	"TAG_KEY" VARCHAR2(100) NOT NULL, 
+	"TAG_VALUE" VARCHAR2(1000), 
+	"CREATE_BY" VARCHAR2(500), 
+	"CREATION_DATE" INTEGER, 
+	 CONSTRAINT "PK_DTG" PRIMARY KEY ("DATASET_ID", "TAG_KEY")
+   ) ;
+--------------------------------------------------------
 --  DDL for Table DATA_TIERS
 --------------------------------------------------------
 
@@ -152,6 +190,20 @@
 	"LAST_MODIFICATION_DATE" INTEGER
    ) ;
 --------------------------------------------------------
+--  DDL for Table DELETION_AUDIT
+--------------------------------------------------------
+
+  CREATE TABLE "DELETION_AUDIT" 
+   (	"DELETION_ID" INTEGER, 
+	"ENTITY_TYPE" VARCHAR2(20), 
+	"ENTITY_NAME" VARCHAR2(700), 
+	"DELETION_MODE" VARCHAR2(20), 
+	"ROW_COUNTS" VARCHAR2(4000), 
+	"REASON" VARCHAR2(1000), 
+	"DELETED_BY" VARCHAR2(500), 
+	"DELETION_DATE" INTEGER
+   ) ;
+--------------------------------------------------------
 --  DDL for Table FILES
 --------------------------------------------------------
 
@@ -223,7 +275,14 @@
 	"CREATION_DATE" INTEGER, 
 	"CREATE_BY" VARCHAR2(500), 
 	"LAST_MODIFICATION_DATE" INTEGER, 
-	"LAST_MODIFIED_BY" VARCHAR2(500)
+	"LAST_MODIFIED_BY" VARCHAR2(500),
+	"ORIGIN_REQUEST_ID" INTEGER,
+	"ATTEMPT_COUNT" INTEGER DEFAULT 0,
+	"START_DATE" INTEGER,
+	"END_DATE" INTEGER,
+	"LAST_ERROR" VARCHAR2(4000),
+	"FILE_COUNT" INTEGER,
+	"BLOCK_SIZE" INTEGER
    ) ;
 --------------------------------------------------------
 --  DDL for Table MIGRATION_REQUESTS
@@ -239,7 +298,11 @@
 	"CREATE_BY" VARCHAR2(500), 
 	"LAST_MODIFICATION_DATE" INTEGER, 
 	"LAST_MODIFIED_BY" VARCHAR2(500), 
-	"RETRY_COUNT" INTEGER
+	"RETRY_COUNT" INTEGER,
+	"LEASE_EXPIRATION_DATE" INTEGER,
+	"PRIORITY" INTEGER DEFAULT 0,
+	"NEXT_RETRY_DATE" INTEGER,
+	"CALLBACK_URL" VARCHAR2(700)
    ) ;
 --------------------------------------------------------
 --  DDL for Table OUTPUT_MODULE_CONFIGS
@@ -386,6 +449,18 @@
   CREATE INDEX "IDX_BP_1" ON "BLOCK_PARENTS" ("PARENT_BLOCK_ID") 
   ;
 --------------------------------------------------------
+--  DDL for Index IDX_BTG_1
+--------------------------------------------------------
+
+  CREATE INDEX "IDX_BTG_1" ON "BLOCK_TAGS" ("TAG_KEY") 
+  ;
+--------------------------------------------------------
+--  DDL for Index IDX_DTG_1
+--------------------------------------------------------
+
+  CREATE INDEX "IDX_DTG_1" ON "DATASET_TAGS" ("TAG_KEY") 
+  ;
+--------------------------------------------------------
 --  DDL for Index IDX_DC_1
 --------------------------------------------------------
 
@@ -722,6 +797,18 @@
   CREATE UNIQUE INDEX "PK_FT" ON "FILE_DATA_TYPES" ("FILE_TYPE_ID") 
   ;
 --------------------------------------------------------
+--  DDL for Index PK_DSR
+--------------------------------------------------------
+
+  CREATE UNIQUE INDEX "PK_DSR" ON "DATASET_RENAMES" ("RENAME_ID") 
+  ;
+--------------------------------------------------------
+--  DDL for Index PK_DLA
+--------------------------------------------------------
+
+  CREATE UNIQUE INDEX "PK_DLA" ON "DELETION_AUDIT" ("DELETION_ID") 
+  ;
+--------------------------------------------------------
 --  DDL for Index PK_MB
 --------------------------------------------------------
 
@@ -0,0 +1,3 @@
+SELECT B.BLOCK_NAME, T.TAG_KEY, T.TAG_VALUE, T.CREATE_BY, T.CREATION_DATE
+FROM {{.Owner}}.BLOCK_TAGS T
+JOIN {{.Owner}}.BLOCKS B ON B.BLOCK_ID = T.BLOCK_ID
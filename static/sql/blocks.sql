@@ -4,7 +4,9 @@ SELECT DISTINCT
 {{else}}
 SELECT
 {{end}}
-{{if .Detail}}
+{{if .Columns}}
+    {{.Columns}}
+{{else if .Detail}}
     B.BLOCK_ID, B.BLOCK_NAME, B.OPEN_FOR_WRITING, 
     B.BLOCK_SIZE, B.FILE_COUNT,
     B.DATASET_ID, DS.DATASET,
@@ -16,7 +18,10 @@ SELECT
 {{if .Runs}}
     , FLM.RUN_NUM
 {{end}}
-FROM {{.Owner}}.BLOCKS B JOIN {{.Owner}}.DATASETS DS ON DS.DATASET_ID = B.DATASET_ID 
+FROM {{.Owner}}.BLOCKS B
+{{if not .SkipDataset}}
+JOIN {{.Owner}}.DATASETS DS ON DS.DATASET_ID = B.DATASET_ID 
+{{end}}
 {{if .Lfns}}
 JOIN {{.Owner}}.FILES FL ON FL.BLOCK_ID = B.BLOCK_ID
 {{else}}
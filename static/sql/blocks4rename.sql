@@ -0,0 +1,3 @@
+SELECT B.BLOCK_ID, B.BLOCK_NAME
+FROM {{.Owner}}.BLOCKS B
+WHERE B.DATASET_ID = :dataset_id
@@ -1,3 +0,0 @@
-SELECT MIGRATION_SERVER
-FROM {{.Owner}}.MIGRATION_REQUESTS
-WHERE MIGRATION_REQUEST_ID = :migration_request_id
@@ -0,0 +1,5 @@
+UPDATE {{.Owner}}.MIGRATION_REQUESTS
+    SET MIGRATION_SERVER = :migration_server,
+    LEASE_EXPIRATION_DATE = :lease_expiration_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
+AND (LEASE_EXPIRATION_DATE IS NULL OR LEASE_EXPIRATION_DATE < :now)
@@ -0,0 +1,3 @@
+SELECT DR.RENAME_ID, DR.DATASET_ID, DR.OLD_DATASET, DR.NEW_DATASET,
+    DR.REASON, DR.RENAMED_BY, DR.RENAME_DATE
+FROM {{.Owner}}.DATASET_RENAMES DR
@@ -0,0 +1,2 @@
+SELECT DR.OLD_DATASET, DR.NEW_DATASET
+FROM {{.Owner}}.DATASET_RENAMES DR
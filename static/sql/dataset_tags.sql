@@ -0,0 +1,3 @@
+SELECT D.DATASET, T.TAG_KEY, T.TAG_VALUE, T.CREATE_BY, T.CREATION_DATE
+FROM {{.Owner}}.DATASET_TAGS T
+JOIN {{.Owner}}.DATASETS D ON D.DATASET_ID = T.DATASET_ID
@@ -4,7 +4,9 @@ SELECT DISTINCT
 {{else}}
 SELECT
 {{end}}
-{{if .Detail}}
+{{if .Columns}}
+        {{.Columns}}
+{{else if .Detail}}
         D.DATASET_ID, D.DATASET, D.PREP_ID, 
         D.XTCROSSSECTION, 
         D.CREATION_DATE, D.CREATE_BY, 
@@ -43,15 +45,29 @@ JOIN {{.Owner}}.FILES FL on FL.DATASET_ID = D.DATASET_ID
 JOIN {{.Owner}}.FILE_LUMIS FLLU on FLLU.FILE_ID=FL.FILE_ID
 {{end}}
 {{end}}
+{{if not .SkipPrimary}}
 JOIN {{.Owner}}.PRIMARY_DATASETS P ON P.PRIMARY_DS_ID = D.PRIMARY_DS_ID
+{{end}}
+{{if not .SkipPrimaryType}}
 JOIN {{.Owner}}.PRIMARY_DS_TYPES PDT ON PDT.PRIMARY_DS_TYPE_ID = P.PRIMARY_DS_TYPE_ID
+{{end}}
+{{if not .SkipProcessed}}
 JOIN {{.Owner}}.PROCESSED_DATASETS PD ON PD.PROCESSED_DS_ID = D.PROCESSED_DS_ID
+{{end}}
+{{if not .SkipTier}}
 JOIN {{.Owner}}.DATA_TIERS DT ON DT.DATA_TIER_ID = D.DATA_TIER_ID
+{{end}}
 JOIN {{.Owner}}.DATASET_ACCESS_TYPES DP on DP.DATASET_ACCESS_TYPE_ID= D.DATASET_ACCESS_TYPE_ID
 
+{{if not .SkipAcquisitionEra}}
 LEFT OUTER JOIN {{.Owner}}.ACQUISITION_ERAS AE ON AE.ACQUISITION_ERA_ID = D.ACQUISITION_ERA_ID
+{{end}}
+{{if not .SkipProcessingEra}}
 LEFT OUTER JOIN {{.Owner}}.PROCESSING_ERAS PE ON PE.PROCESSING_ERA_ID = D.PROCESSING_ERA_ID
+{{end}}
+{{if not .SkipPhysicsGroup}}
 LEFT OUTER JOIN {{.Owner}}.PHYSICS_GROUPS PH ON PH.PHYSICS_GROUP_ID = D.PHYSICS_GROUP_ID
+{{end}}
 {{if .ParentDataset}}
 LEFT OUTER JOIN {{.Owner}}.DATASET_PARENTS DSP ON DSP.THIS_DATASET_ID = D.DATASET_ID
 LEFT OUTER JOIN {{.Owner}}.DATASETS PDS ON PDS.DATASET_ID = DSP.PARENT_DATASET_ID
@@ -0,0 +1,137 @@
+{{if eq .Owner "sqlite"}}
+SELECT
+{{range .Groups}}
+    DS.{{.}},
+{{end}}
+    COUNT(DS.DATASET_ID) AS NUM_DATASET,
+    COALESCE(SUM(DS.NUM_BLOCK), 0) AS NUM_BLOCK,
+    COALESCE(SUM(DS.NUM_FILE), 0) AS NUM_FILE,
+    COALESCE(SUM(DS.NUM_EVENT), 0) AS NUM_EVENT,
+    COALESCE(SUM(DS.FILE_SIZE), 0) AS FILE_SIZE
+FROM
+    (
+        SELECT D.DATASET_ID,
+{{if .Tier}}
+            DT.DATA_TIER_NAME AS DATA_TIER_NAME,
+{{end}}
+{{if .AcquisitionEra}}
+            AE.ACQUISITION_ERA_NAME AS ACQUISITION_ERA_NAME,
+{{end}}
+{{if .PhysicsGroup}}
+            PH.PHYSICS_GROUP_NAME AS PHYSICS_GROUP_NAME,
+{{end}}
+{{if .PrimaryType}}
+            PDT.PRIMARY_DS_TYPE AS PRIMARY_DS_TYPE,
+{{end}}
+{{if .CreationMonth}}
+            strftime('%Y-%m', D.CREATION_DATE, 'unixepoch') AS CREATION_MONTH,
+{{end}}
+            DP.DATASET_ACCESS_TYPE AS DATASET_ACCESS_TYPE,
+            (
+                SELECT COUNT(BS.BLOCK_ID)
+                FROM BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_BLOCK,
+            (
+                SELECT COALESCE(SUM(BS.FILE_COUNT), 0)
+                FROM BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_FILE,
+            (
+                SELECT COALESCE(SUM(FS.EVENT_COUNT), 0)
+                FROM FILES FS
+                WHERE FS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_EVENT,
+            (
+                SELECT COALESCE(SUM(BS.BLOCK_SIZE), 0)
+                FROM BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS FILE_SIZE
+        FROM DATASETS D
+        JOIN DATASET_ACCESS_TYPES DP ON DP.DATASET_ACCESS_TYPE_ID=D.DATASET_ACCESS_TYPE_ID
+{{if .Tier}}
+        JOIN DATA_TIERS DT ON DT.DATA_TIER_ID=D.DATA_TIER_ID
+{{end}}
+{{if .PrimaryType}}
+        JOIN PRIMARY_DATASETS P ON P.PRIMARY_DS_ID=D.PRIMARY_DS_ID
+        JOIN PRIMARY_DS_TYPES PDT ON PDT.PRIMARY_DS_TYPE_ID=P.PRIMARY_DS_TYPE_ID
+{{end}}
+{{if .AcquisitionEra}}
+        LEFT OUTER JOIN ACQUISITION_ERAS AE ON AE.ACQUISITION_ERA_ID=D.ACQUISITION_ERA_ID
+{{end}}
+{{if .PhysicsGroup}}
+        LEFT OUTER JOIN PHYSICS_GROUPS PH ON PH.PHYSICS_GROUP_ID=D.PHYSICS_GROUP_ID
+{{end}}
+        WHERE {{.Where}}
+    ) DS
+{{else}}
+SELECT
+{{range .Groups}}
+    DS.{{.}},
+{{end}}
+    COUNT(DS.DATASET_ID) AS NUM_DATASET,
+    NVL(SUM(DS.NUM_BLOCK), 0) AS NUM_BLOCK,
+    NVL(SUM(DS.NUM_FILE), 0) AS NUM_FILE,
+    NVL(SUM(DS.NUM_EVENT), 0) AS NUM_EVENT,
+    NVL(SUM(DS.FILE_SIZE), 0) AS FILE_SIZE
+FROM
+    (
+        SELECT D.DATASET_ID,
+{{if .Tier}}
+            DT.DATA_TIER_NAME AS DATA_TIER_NAME,
+{{end}}
+{{if .AcquisitionEra}}
+            AE.ACQUISITION_ERA_NAME AS ACQUISITION_ERA_NAME,
+{{end}}
+{{if .PhysicsGroup}}
+            PH.PHYSICS_GROUP_NAME AS PHYSICS_GROUP_NAME,
+{{end}}
+{{if .PrimaryType}}
+            PDT.PRIMARY_DS_TYPE AS PRIMARY_DS_TYPE,
+{{end}}
+{{if .CreationMonth}}
+            TO_CHAR(TO_DATE('1970-01-01', 'YYYY-MM-DD') + D.CREATION_DATE/86400, 'YYYY-MM') AS CREATION_MONTH,
+{{end}}
+            DP.DATASET_ACCESS_TYPE AS DATASET_ACCESS_TYPE,
+            (
+                SELECT COUNT(BS.BLOCK_ID)
+                FROM {{.Owner}}.BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_BLOCK,
+            (
+                SELECT NVL(SUM(BS.FILE_COUNT), 0)
+                FROM {{.Owner}}.BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_FILE,
+            (
+                SELECT NVL(SUM(FS.EVENT_COUNT), 0)
+                FROM {{.Owner}}.FILES FS
+                WHERE FS.DATASET_ID=D.DATASET_ID
+            ) AS NUM_EVENT,
+            (
+                SELECT NVL(SUM(BS.BLOCK_SIZE), 0)
+                FROM {{.Owner}}.BLOCKS BS
+                WHERE BS.DATASET_ID=D.DATASET_ID
+            ) AS FILE_SIZE
+        FROM {{.Owner}}.DATASETS D
+        JOIN {{.Owner}}.DATASET_ACCESS_TYPES DP ON DP.DATASET_ACCESS_TYPE_ID=D.DATASET_ACCESS_TYPE_ID
+{{if .Tier}}
+        JOIN {{.Owner}}.DATA_TIERS DT ON DT.DATA_TIER_ID=D.DATA_TIER_ID
+{{end}}
+{{if .PrimaryType}}
+        JOIN {{.Owner}}.PRIMARY_DATASETS P ON P.PRIMARY_DS_ID=D.PRIMARY_DS_ID
+        JOIN {{.Owner}}.PRIMARY_DS_TYPES PDT ON PDT.PRIMARY_DS_TYPE_ID=P.PRIMARY_DS_TYPE_ID
+{{end}}
+{{if .AcquisitionEra}}
+        LEFT OUTER JOIN {{.Owner}}.ACQUISITION_ERAS AE ON AE.ACQUISITION_ERA_ID=D.ACQUISITION_ERA_ID
+{{end}}
+{{if .PhysicsGroup}}
+        LEFT OUTER JOIN {{.Owner}}.PHYSICS_GROUPS PH ON PH.PHYSICS_GROUP_ID=D.PHYSICS_GROUP_ID
+{{end}}
+        WHERE {{.Where}}
+    ) DS
+{{end}}
+{{if .GroupBy}}
+GROUP BY {{.GroupBy}}
+ORDER BY {{.GroupBy}}
+{{end}}
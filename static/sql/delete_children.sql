@@ -0,0 +1,15 @@
+{{if .FileChildren}}
+SELECT COUNT(*) FROM {{.Owner}}.FILE_PARENTS FP
+    JOIN {{.Owner}}.FILES F ON F.FILE_ID = FP.THIS_FILE_ID
+    WHERE FP.PARENT_FILE_ID IN (SELECT FILE_ID FROM {{.Owner}}.FILES WHERE {{.Column}} = :entity_id)
+    AND F.{{.Column}} <> :child_id
+{{else if .BlockChildren}}
+SELECT COUNT(*) FROM {{.Owner}}.BLOCK_PARENTS BP
+    JOIN {{.Owner}}.BLOCKS B ON B.BLOCK_ID = BP.THIS_BLOCK_ID
+    WHERE BP.PARENT_BLOCK_ID IN (SELECT BLOCK_ID FROM {{.Owner}}.BLOCKS WHERE {{.Column}} = :entity_id)
+    AND B.{{.Column}} <> :child_id
+{{else}}
+SELECT COUNT(*) FROM {{.Owner}}.DATASET_PARENTS DP
+    WHERE DP.PARENT_DATASET_ID = :entity_id
+    AND DP.THIS_DATASET_ID <> :child_id
+{{end}}
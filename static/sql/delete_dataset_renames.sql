@@ -0,0 +1,2 @@
+DELETE FROM {{.Owner}}.DATASET_RENAMES
+    WHERE OLD_DATASET = :new_dataset
@@ -0,0 +1,12 @@
+{{if .DryRun}}
+SELECT COUNT(*) FROM {{.Owner}}.{{.Table}}
+{{else}}
+DELETE FROM {{.Owner}}.{{.Table}}
+{{end}}
+{{if .Files}}
+    WHERE {{.Column}} IN (SELECT FILE_ID FROM {{.Owner}}.FILES WHERE {{.Scope}} = :entity_id)
+{{else if .Blocks}}
+    WHERE {{.Column}} IN (SELECT BLOCK_ID FROM {{.Owner}}.BLOCKS WHERE {{.Scope}} = :entity_id)
+{{else}}
+    WHERE {{.Column}} = :entity_id
+{{end}}
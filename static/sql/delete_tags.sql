@@ -0,0 +1,3 @@
+DELETE FROM {{.Owner}}.{{.Table}}
+    WHERE {{.Column}} = :entity_id
+    AND TAG_KEY = :tag_key
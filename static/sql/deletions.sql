@@ -0,0 +1,3 @@
+SELECT DA.DELETION_ID, DA.ENTITY_TYPE, DA.ENTITY_NAME, DA.DELETION_MODE,
+    DA.ROW_COUNTS, DA.REASON, DA.DELETED_BY, DA.DELETION_DATE
+FROM {{.Owner}}.DELETION_AUDIT DA
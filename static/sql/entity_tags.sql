@@ -0,0 +1,3 @@
+SELECT T.TAG_KEY, T.TAG_VALUE
+FROM {{.Owner}}.{{.Table}} T
+WHERE T.{{.Column}} = :entity_id
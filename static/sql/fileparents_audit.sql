@@ -0,0 +1,17 @@
+SELECT
+    F.FILE_ID,
+    F.LOGICAL_FILE_NAME,
+    B.BLOCK_ID this_block_id,
+    B.BLOCK_NAME this_block_name,
+    PF.LOGICAL_FILE_NAME parent_logical_file_name,
+    PB.BLOCK_ID parent_block_id,
+    PB.BLOCK_NAME parent_block_name,
+    PD.DATASET_ID parent_dataset_id,
+    PD.DATASET parent_dataset
+FROM {{.Owner}}.FILES F
+JOIN {{.Owner}}.BLOCKS B ON B.BLOCK_ID = F.BLOCK_ID
+JOIN {{.Owner}}.DATASETS D ON D.DATASET_ID = F.DATASET_ID
+LEFT OUTER JOIN {{.Owner}}.FILE_PARENTS FP ON FP.THIS_FILE_ID = F.FILE_ID
+LEFT OUTER JOIN {{.Owner}}.FILES PF ON PF.FILE_ID = FP.PARENT_FILE_ID
+LEFT OUTER JOIN {{.Owner}}.BLOCKS PB ON PB.BLOCK_ID = PF.BLOCK_ID
+LEFT OUTER JOIN {{.Owner}}.DATASETS PD ON PD.DATASET_ID = PF.DATASET_ID
@@ -6,9 +6,14 @@ select run_num as R, Lumi_section_num as L, file_id as pid from {{.Owner}}.file_
 where fl.file_id in (
 	select file_id from {{.Owner}}.files f
 	where F.DATASET_ID in (
+{{if .ParentDataset}}
+		select d.dataset_id from {{.Owner}}.datasets d
+		where d.dataset = :parent_ds_name )
+{{else}}
 		select parent_dataset_id from {{.Owner}}.dataset_parents dp
 		inner join {{.Owner}}.datasets d on d.dataset_id=DP.THIS_DATASET_ID
 		where d.dataset = :child_ds_name )
+{{end}}
 )
 ),
 children as (
@@ -28,9 +33,14 @@ select run_num as R, Lumi_section_num as L, file_id as pid from {{.Owner}}.file_
 where fl.file_id in (
 	select file_id from {{.Owner}}.files f
 	where F.DATASET_ID in (
+{{if .ParentDataset}}
+		select d.dataset_id from {{.Owner}}.datasets d
+		where d.dataset = :parent_ds_name )
+{{else}}
 		select parent_dataset_id from {{.Owner}}.dataset_parents dp
 		inner join {{.Owner}}.datasets d on d.dataset_id=DP.THIS_DATASET_ID
 		where d.dataset = :child_ds_name )
+{{end}}
 )
 ),
 children as (
@@ -3,7 +3,9 @@ SELECT DISTINCT
 {{else}}
 SELECT 
 {{end}}
-{{if .Detail}}
+{{if .Columns}}
+        {{.Columns}}
+{{else if .Detail}}
         F.FILE_ID, F.LOGICAL_FILE_NAME, F.IS_FILE_VALID,
         F.DATASET_ID, D.DATASET,
         F.BLOCK_ID, B.BLOCK_NAME,
@@ -20,10 +22,18 @@ SELECT
         , FL.RUN_NUM
 {{end}}
 FROM {{.Owner}}.FILES F
+{{if not .SkipFileType}}
 JOIN {{.Owner}}.FILE_DATA_TYPES FT ON  FT.FILE_TYPE_ID = F.FILE_TYPE_ID
+{{end}}
+{{if not .SkipDataset}}
 JOIN {{.Owner}}.DATASETS D ON  D.DATASET_ID = F.DATASET_ID
+{{end}}
+{{if not .SkipBlock}}
 JOIN {{.Owner}}.BLOCKS B ON B.BLOCK_ID = F.BLOCK_ID
+{{end}}
+{{if not .SkipAccessType}}
 JOIN {{.Owner}}.DATASET_ACCESS_TYPES DT ON  DT.DATASET_ACCESS_TYPE_ID = D.DATASET_ACCESS_TYPE_ID
+{{end}}
 {{if .RunNumber}}
 JOIN {{.Owner}}.FILE_LUMIS FL on FL.FILE_ID=F.FILE_ID
 {{end}}
@@ -0,0 +1,12 @@
+INSERT INTO {{.Owner}}.BLOCK_TAGS
+    (BLOCK_ID,
+    TAG_KEY,
+    TAG_VALUE,
+    CREATE_BY,
+    CREATION_DATE)
+VALUES
+    (:block_id,
+    :tag_key,
+    :tag_value,
+    :create_by,
+    :creation_date)
@@ -0,0 +1,16 @@
+INSERT INTO {{.Owner}}.DATASET_RENAMES
+    (RENAME_ID,
+    DATASET_ID,
+    OLD_DATASET,
+    NEW_DATASET,
+    REASON,
+    RENAMED_BY,
+    RENAME_DATE)
+VALUES
+    (:rename_id,
+    :dataset_id,
+    :old_dataset,
+    :new_dataset,
+    :reason,
+    :renamed_by,
+    :rename_date)
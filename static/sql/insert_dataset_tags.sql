@@ -0,0 +1,12 @@
+INSERT INTO {{.Owner}}.DATASET_TAGS
+    (DATASET_ID,
+    TAG_KEY,
+    TAG_VALUE,
+    CREATE_BY,
+    CREATION_DATE)
+VALUES
+    (:dataset_id,
+    :tag_key,
+    :tag_value,
+    :create_by,
+    :creation_date)
@@ -0,0 +1,18 @@
+INSERT INTO {{.Owner}}.DELETION_AUDIT
+    (DELETION_ID,
+    ENTITY_TYPE,
+    ENTITY_NAME,
+    DELETION_MODE,
+    ROW_COUNTS,
+    REASON,
+    DELETED_BY,
+    DELETION_DATE)
+VALUES
+    (:deletion_id,
+    :entity_type,
+    :entity_name,
+    :deletion_mode,
+    :row_counts,
+    :reason,
+    :deleted_by,
+    :deletion_date)
@@ -7,7 +7,8 @@ INSERT INTO {{.Owner}}.MIGRATION_BLOCKS
     CREATION_DATE,
     CREATE_BY,
     LAST_MODIFICATION_DATE,
-    LAST_MODIFIED_BY)
+    LAST_MODIFIED_BY,
+    ORIGIN_REQUEST_ID)
 VALUES
     (:migration_block_id,
     :migration_request_id,
@@ -17,4 +18,5 @@ VALUES
     :creation_date,
     :create_by,
     :last_modification_date,
-    :last_modified_by)
+    :last_modified_by,
+    :origin_request_id)
@@ -8,7 +8,9 @@ INSERT INTO {{.Owner}}.MIGRATION_REQUESTS
     CREATE_BY,
     LAST_MODIFICATION_DATE,
     LAST_MODIFIED_BY,
-    RETRY_COUNT)
+    RETRY_COUNT,
+    PRIORITY,
+    CALLBACK_URL)
 VALUES
     (:migration_request_id,
     :migration_url,
@@ -19,4 +21,6 @@ VALUES
     :create_by,
     :last_modification_date,
     :last_modified_by,
-    :retry_count)
+    :retry_count,
+    :priority,
+    :callback_url)
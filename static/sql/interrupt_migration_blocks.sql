@@ -0,0 +1,6 @@
+UPDATE {{.Owner}}.MIGRATION_BLOCKS
+    SET MIGRATION_STATUS = 0,
+    LAST_ERROR = :last_error,
+    LAST_MODIFICATION_DATE = :last_modification_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
+AND MIGRATION_STATUS = 1
@@ -0,0 +1,7 @@
+UPDATE {{.Owner}}.MIGRATION_REQUESTS
+    SET MIGRATION_STATUS = 0,
+    LEASE_EXPIRATION_DATE = NULL,
+    LAST_MODIFICATION_DATE = :last_modification_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
+AND MIGRATION_STATUS = 1
+AND MIGRATION_SERVER = :migration_server
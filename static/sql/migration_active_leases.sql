@@ -0,0 +1,4 @@
+SELECT MR.CREATE_BY, COUNT(MR.MIGRATION_REQUEST_ID) AS ACTIVE
+FROM {{.Owner}}.MIGRATION_REQUESTS MR
+WHERE MR.LEASE_EXPIRATION_DATE >= :now
+GROUP BY MR.CREATE_BY
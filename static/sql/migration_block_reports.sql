@@ -0,0 +1,8 @@
+SELECT MB.MIGRATION_BLOCK_NAME, MB.MIGRATION_REQUEST_ID, MB.MIGRATION_ORDER,
+       MR.MIGRATION_STATUS, MB.ATTEMPT_COUNT, MR.RETRY_COUNT, MB.LAST_ERROR,
+       MB.START_DATE, MB.END_DATE, MB.FILE_COUNT, MB.BLOCK_SIZE
+FROM {{.Owner}}.MIGRATION_BLOCKS MB
+JOIN {{.Owner}}.MIGRATION_REQUESTS MR ON MR.MIGRATION_REQUEST_ID = MB.MIGRATION_REQUEST_ID
+WHERE MB.ORIGIN_REQUEST_ID = :origin_request_id
+OR MB.MIGRATION_REQUEST_ID = :migration_request_id
+ORDER BY MB.MIGRATION_ORDER
@@ -0,0 +1,2 @@
+SELECT MR.MIGRATION_STATUS FROM {{.Owner}}.MIGRATION_REQUESTS MR
+WHERE MR.MIGRATION_REQUEST_ID = :migration_request_id
@@ -1,17 +1,20 @@
 SELECT MR.MIGRATION_REQUEST_ID, MR.MIGRATION_URL,
        MR.MIGRATION_INPUT, MR.MIGRATION_STATUS, MR.MIGRATION_SERVER,
        MR.CREATE_BY, MR.CREATION_DATE,
-       MR.LAST_MODIFIED_BY, MR.LAST_MODIFICATION_DATE, MR.RETRY_COUNT
+       MR.LAST_MODIFIED_BY, MR.LAST_MODIFICATION_DATE, MR.RETRY_COUNT,
+       MR.LEASE_EXPIRATION_DATE, MR.PRIORITY, MR.NEXT_RETRY_DATE,
+       MR.CALLBACK_URL
 FROM {{.Owner}}.MIGRATION_REQUESTS MR
 {{if .Blocks}}
 JOIN {{.Owner}}.MIGRATION_BLOCKS MB ON MB.MIGRATION_REQUEST_ID=MR.MIGRATION_REQUEST_ID
 {{end}}
 
 {{if .Oldest}}
-WHERE MR.MIGRATION_STATUS=0
+WHERE (MR.MIGRATION_STATUS=0
 or MR.MIGRATION_STATUS=1
-or (MR.migration_status=3 and MR.retry_count=0 and MR.last_modification_date <= {{.Date1}})
-or (MR.migration_status=3 and MR.retry_count=1 and MR.last_modification_date <= {{.Date2}})
-or (MR.migration_status=3 and MR.retry_count=2 and MR.last_modification_date <= {{.Date3}})
-or (MR.migration_status=5 and MR.retry_count=0 and MR.last_modification_date <= {{.Date1}})
+or (MR.migration_status=3 and (MR.NEXT_RETRY_DATE IS NULL or MR.NEXT_RETRY_DATE <= {{.RetryDate}}))
+or (MR.migration_status=5 and MR.retry_count=0 and MR.last_modification_date <= {{.Date1}}))
+{{if .LeaseDate}}
+and (MR.LEASE_EXPIRATION_DATE IS NULL or MR.LEASE_EXPIRATION_DATE < {{.LeaseDate}})
+{{end}}
 {{end}}
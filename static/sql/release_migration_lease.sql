@@ -0,0 +1,4 @@
+UPDATE {{.Owner}}.MIGRATION_REQUESTS
+    SET LEASE_EXPIRATION_DATE = NULL
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
+AND MIGRATION_SERVER = :migration_server
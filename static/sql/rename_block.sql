@@ -0,0 +1,5 @@
+UPDATE {{.Owner}}.BLOCKS
+    SET BLOCK_NAME = :new_block_name,
+        LAST_MODIFIED_BY = :myuser,
+        LAST_MODIFICATION_DATE = :mydate
+    WHERE BLOCK_ID = :block_id
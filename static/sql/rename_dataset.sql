@@ -0,0 +1,8 @@
+UPDATE {{.Owner}}.DATASETS
+    SET DATASET = :new_dataset,
+        PROCESSED_DS_ID = :processed_ds_id,
+        ACQUISITION_ERA_ID = :acquisition_era_id,
+        PROCESSING_ERA_ID = :processing_era_id,
+        LAST_MODIFIED_BY = :myuser,
+        LAST_MODIFICATION_DATE = :mydate
+    WHERE DATASET_ID = :dataset_id
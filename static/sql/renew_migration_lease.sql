@@ -0,0 +1,5 @@
+UPDATE {{.Owner}}.MIGRATION_REQUESTS
+    SET LEASE_EXPIRATION_DATE = :lease_expiration_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
+AND MIGRATION_SERVER = :migration_server
+AND LEASE_EXPIRATION_DATE IS NOT NULL
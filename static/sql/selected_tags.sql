@@ -0,0 +1,3 @@
+SELECT T.{{.Column}}, T.TAG_KEY, T.TAG_VALUE
+FROM {{.Owner}}.{{.Table}} T
+WHERE T.{{.Column}} IN (SELECT Q.{{.IdField}} FROM ({{.Query}}) Q)
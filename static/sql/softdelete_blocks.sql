@@ -0,0 +1,2 @@
+SELECT BLOCK_ID FROM {{.Owner}}.BLOCKS
+    WHERE {{.Column}} = :entity_id
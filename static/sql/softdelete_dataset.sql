@@ -0,0 +1,5 @@
+UPDATE {{.Owner}}.DATASETS
+    SET DATASET_ACCESS_TYPE_ID = :access_type_id,
+        LAST_MODIFIED_BY = :myuser,
+        LAST_MODIFICATION_DATE = :mydate
+    WHERE DATASET_ID = :entity_id
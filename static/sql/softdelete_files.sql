@@ -0,0 +1,10 @@
+{{if .DryRun}}
+SELECT COUNT(*) FROM {{.Owner}}.FILES
+{{else}}
+UPDATE {{.Owner}}.FILES
+    SET IS_FILE_VALID = -1,
+        LAST_MODIFIED_BY = :myuser,
+        LAST_MODIFICATION_DATE = :mydate
+{{end}}
+    WHERE {{.Column}} = :entity_id
+    AND IS_FILE_VALID <> -1
@@ -0,0 +1,24 @@
+SELECT B.BLOCK_ID, B.BLOCK_NAME, B.BLOCK_SIZE, B.FILE_COUNT,
+       B.ORIGIN_SITE_NAME, B.CREATION_DATE, B.CREATE_BY,
+       B.LAST_MODIFICATION_DATE, B.LAST_MODIFIED_BY
+FROM {{.Owner}}.BLOCKS B
+{{if .Dataset}}
+JOIN {{.Owner}}.DATASETS DS ON DS.DATASET_ID = B.DATASET_ID
+{{end}}
+WHERE B.OPEN_FOR_WRITING = 1
+AND (
+    (
+        B.LAST_MODIFICATION_DATE <= :idle_date
+        AND NOT EXISTS (
+            SELECT F.FILE_ID FROM {{.Owner}}.FILES F
+            WHERE F.BLOCK_ID = B.BLOCK_ID
+            AND F.LAST_MODIFICATION_DATE > :idle_date
+        )
+    )
+{{if .MaxSize}}
+    OR B.BLOCK_SIZE >= :max_size
+{{end}}
+{{if .MaxFiles}}
+    OR B.FILE_COUNT >= :max_files
+{{end}}
+)
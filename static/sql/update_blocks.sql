@@ -10,4 +10,7 @@ UPDATE {{.Owner}}.BLOCKS
         LAST_MODIFIED_BY=:myuser,
         LAST_MODIFICATION_DATE = :ltime
     WHERE BLOCK_NAME = :block_name
+{{if .OpenOnly}}
+    AND OPEN_FOR_WRITING = 1
+{{end}}
 {{end}}
@@ -0,0 +1,17 @@
+{{if .SQLite}}
+UPDATE {{.Owner}}.FILES
+{{else}}
+UPDATE {{.Owner}}.FILES F
+{{end}}
+    SET LAST_MODIFIED_BY=:myuser,
+        LAST_MODIFICATION_DATE=:mydate,
+        IS_FILE_VALID = :is_file_valid
+WHERE IS_FILE_VALID <> :current_file_valid
+AND LOGICAL_FILE_NAME IN (
+{{.TokenGenerator}}
+{{if .SQLite}}
+    SELECT token FROM TOKEN_GENERATOR WHERE token <> ''
+{{else}}
+    SELECT TOKEN FROM TOKEN_GENERATOR
+{{end}}
+)
@@ -0,0 +1,12 @@
+{{.TokenGenerator}}
+SELECT DISTINCT F.LOGICAL_FILE_NAME
+FROM {{.Owner}}.FILES F
+{{if .Block}}
+INNER JOIN {{.Owner}}.BLOCKS B ON B.BLOCK_ID = F.BLOCK_ID
+{{end}}
+{{if .Dataset}}
+INNER JOIN {{.Owner}}.DATASETS D ON D.DATASET_ID = F.DATASET_ID
+{{end}}
+{{if .Lumis}}
+INNER JOIN {{.Owner}}.FILE_LUMIS FL ON FL.FILE_ID = F.FILE_ID
+{{end}}
@@ -0,0 +1,10 @@
+UPDATE {{.Owner}}.MIGRATION_BLOCKS
+    SET MIGRATION_STATUS = :migration_status,
+    END_DATE = :end_date,
+    FILE_COUNT = :file_count,
+    BLOCK_SIZE = :block_size,
+{{if .Error}}
+    LAST_ERROR = :last_error,
+{{end}}
+    LAST_MODIFICATION_DATE = :last_modification_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
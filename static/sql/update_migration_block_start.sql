@@ -0,0 +1,7 @@
+UPDATE {{.Owner}}.MIGRATION_BLOCKS
+    SET MIGRATION_STATUS = :migration_status,
+    ATTEMPT_COUNT = {{if eq .Owner "sqlite"}}COALESCE{{else}}NVL{{end}}(ATTEMPT_COUNT, 0) + 1,
+    START_DATE = :start_date,
+    END_DATE = NULL,
+    LAST_MODIFICATION_DATE = :last_modification_date
+WHERE MIGRATION_REQUEST_ID = :migration_request_id
@@ -1,5 +1,9 @@
 UPDATE {{.Owner}}.MIGRATION_REQUESTS
     SET MIGRATION_STATUS = :status,
     RETRY_COUNT = :retry_count,
-    MIGRATION_SERVER = :migration_server
+    MIGRATION_SERVER = :migration_server,
+    NEXT_RETRY_DATE = :next_retry_date
 WHERE MIGRATION_REQUEST_ID = :migration_request_id
+{{if .Lease}}
+AND (MIGRATION_SERVER = :owner OR LEASE_EXPIRATION_DATE IS NULL OR LEASE_EXPIRATION_DATE < :now)
+{{end}}
@@ -0,0 +1,11 @@
+// Package static provides DBS static files, i.e. SQL templates, lexicon
+// patterns, API parameters, HTTP templates, CSS and images, embedded into
+// DBS server binary
+package static
+
+import "embed"
+
+// Files holds DBS static files embedded into DBS server binary
+//
+//go:embed sql/*.sql lexicon_reader.json lexicon_writer.json parameters.json templates css images
+var Files embed.FS
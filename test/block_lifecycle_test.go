@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestBlockCloseStale tests closing of open blocks by age, size and number
+// of files thresholds
+func TestBlockCloseStale(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	for i := range bulk.Files {
+		bulk.Files[i].IsFileValid = 1
+	}
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	block := bulk.Block.BlockName
+
+	// helper functions to set and get block state
+	setBlock := func(open int, mdate int64) {
+		stm := "UPDATE BLOCKS SET OPEN_FOR_WRITING = ?, LAST_MODIFICATION_DATE = ? WHERE BLOCK_NAME = ?"
+		if _, err := dbs.DB.Exec(stm, open, mdate, block); err != nil {
+			t.Fatal(err)
+		}
+		stm = "UPDATE FILES SET LAST_MODIFICATION_DATE = ? WHERE BLOCK_ID = (SELECT BLOCK_ID FROM BLOCKS WHERE BLOCK_NAME = ?)"
+		if _, err := dbs.DB.Exec(stm, mdate, block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getBlock := func() (int, int64, int64) {
+		var open int
+		var size, count int64
+		stm := "SELECT OPEN_FOR_WRITING, BLOCK_SIZE, FILE_COUNT FROM BLOCKS WHERE BLOCK_NAME = ?"
+		if err := dbs.DB.QueryRow(stm, block).Scan(&open, &size, &count); err != nil {
+			t.Fatal(err)
+		}
+		return open, size, count
+	}
+	api := dbs.API{CreateBy: "test"}
+	now := time.Now().Unix()
+
+	// recently modified block is kept open
+	setBlock(1, now)
+	if err := api.CloseStaleBlocks(1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if open, _, _ := getBlock(); open != 1 {
+		t.Fatal("recently modified block is closed")
+	}
+
+	// idle block is closed and its statistics are recomputed
+	setBlock(1, now-2*3600)
+	if err := api.CloseStaleBlocks(1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	open, size, count := getBlock()
+	if open != 0 {
+		t.Fatal("idle block is not closed")
+	}
+	if count != int64(len(bulk.Files)) || size <= 0 {
+		t.Errorf("wrong statistics of closed block, size %d, file count %d", size, count)
+	}
+
+	// blocks are closed when they reach size or number of files thresholds
+	tests := []struct {
+		maxSize  int64
+		maxFiles int64
+		closed   bool
+	}{
+		{size + 1, 0, false},
+		{size, 0, true},
+		{0, count + 1, false},
+		{0, count, true},
+		{size + 1, count + 1, false},
+	}
+	for _, tt := range tests {
+		setBlock(1, now)
+		if err := api.CloseStaleBlocks(1, tt.maxSize, tt.maxFiles); err != nil {
+			t.Fatal(err)
+		}
+		if open, _, _ := getBlock(); (open == 0) != tt.closed {
+			t.Errorf("wrong state of block with max size %d and max files %d, open_for_writing=%d",
+				tt.maxSize, tt.maxFiles, open)
+		}
+	}
+}
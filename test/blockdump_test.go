@@ -0,0 +1,148 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestBlockDumpSections tests blockdump API with section mask and ndjson output
+func TestBlockDumpSections(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	block := bulk.Block.BlockName
+
+	// full block dump keeps all sections
+	full, err := c.BlockDump(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.Block.BlockName != block || len(full.Files) != len(bulk.Files) {
+		t.Fatalf("wrong block dump %+v", full.Block)
+	}
+
+	// block dump with selected sections
+	tests := []struct {
+		params url.Values
+		keys   []string
+	}{
+		{url.Values{"include": []string{"block,dataset"}}, []string{"block", "dataset"}},
+		{url.Values{"include": []string{"block", "primds"}}, []string{"block", "primds"}},
+		{url.Values{"include": []string{"file_lumi_list"}}, []string{"files"}},
+		{url.Values{"include": []string{"dataset_parent_list"}}, []string{"dataset_parent_list", "ds_parent_list"}},
+		{url.Values{"exclude": []string{"files,dataset_parent_list,file_conf_list,file_parent_list,dataset_conf_list"}},
+			[]string{"block", "dataset", "primds", "processing_era", "acquisition_era", "block_parent_list"}},
+	}
+	for _, tt := range tests {
+		rec, err := c.PartialBlockDump(block, tt.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rec) != len(tt.keys) {
+			t.Errorf("wrong block dump sections for %v: %v", tt.params, rec)
+		}
+		for _, key := range tt.keys {
+			if _, ok := rec[key]; !ok {
+				t.Errorf("block dump for %v has no %s section", tt.params, key)
+			}
+		}
+	}
+
+	// files without lumis
+	rec, err := c.PartialBlockDump(block, url.Values{"include": []string{"files"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, ok := rec["files"].([]interface{})
+	if !ok || len(files) != len(bulk.Files) {
+		t.Fatalf("wrong files in block dump %v", rec)
+	}
+	for _, f := range files {
+		if lumis := f.(map[string]interface{})["file_lumi_list"]; lumis != nil {
+			t.Errorf("file lumis are not excluded %v", f)
+		}
+	}
+
+	// invalid sections are rejected
+	for _, params := range []url.Values{
+		{"include": []string{"bla"}},
+		{"exclude": []string{"files,bla"}},
+		{"include": []string{"block"}, "exclude": []string{"files"}},
+	} {
+		if _, err := c.PartialBlockDump(block, params); err == nil {
+			t.Errorf("no error for block dump parameters %v", params)
+		}
+	}
+
+	// plain ndjson block dump holds the whole record
+	pit, err := c.Stream("blockdump", url.Values{"block_name": []string{block}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pit.Close()
+	var plain dbs.BulkBlocks
+	if !pit.Next(&plain) || len(plain.Files) != len(full.Files) {
+		t.Errorf("wrong ndjson block dump %+v, error %v", plain.Block, pit.Err())
+	}
+	if pit.Next(&plain) {
+		t.Errorf("ndjson block dump has more than one record")
+	}
+
+	// streaming requires ndjson output and supports only files
+	for _, params := range []url.Values{
+		{"stream": []string{"files"}},
+		{"stream": []string{"lumis"}},
+	} {
+		if _, err := c.PartialBlockDump(block, params); err == nil {
+			t.Errorf("no error for block dump parameters %v", params)
+		}
+	}
+
+	// ndjson block dump streams files one by one
+	rec, it, err := c.BlockDumpIterator(block, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	if _, ok := rec["files"]; ok {
+		t.Errorf("streamed block dump record contains files")
+	}
+	if blk, ok := rec["block"].(map[string]interface{}); !ok || blk["block_name"] != block {
+		t.Errorf("wrong streamed block dump record %v", rec)
+	}
+	var nfiles, nlumis int
+	var file dbs.File
+	for it.Next(&file) {
+		nfiles++
+		nlumis += len(file.FileLumiList)
+		file = dbs.File{}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var expect int
+	for _, f := range full.Files {
+		expect += len(f.FileLumiList)
+	}
+	if nfiles != len(full.Files) || nlumis != expect {
+		t.Errorf("wrong streamed files %d and lumis %d, expect %d and %d", nfiles, nlumis, len(full.Files), expect)
+	}
+
+	// ndjson block dump without files yields single record
+	rec, it, err = c.BlockDumpIterator(block, url.Values{"exclude": []string{"files"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	if it.Next(&file) {
+		t.Errorf("unexpected file in block dump without files %+v", file)
+	}
+	if _, ok := rec["file_conf_list"]; !ok {
+		t.Errorf("wrong streamed block dump record %v", rec)
+	}
+}
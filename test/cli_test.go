@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/dbs2go/cli"
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestCLI tests dbs command line tool against DBS writer server
+func TestCLI(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := cli.Run(append([]string{"-url", c.URL}, args...), &out)
+		return out.String(), err
+	}
+
+	// flags of DBS APIs are defined by DBS API parameters
+	if _, err := run("datasets", "-bla", "1"); err == nil {
+		t.Error("no error for unknown parameter of datasets API")
+	}
+	if _, err := run("bla"); err == nil {
+		t.Error("no error for unknown command")
+	}
+
+	// inject bulkblocks
+	out, err := run("-format", "json", "inject", "-file", "data/bulkblocks1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "injected") {
+		t.Errorf("wrong inject output %s", out)
+	}
+	dataset := "/unittest_web_primary_ds_name_207/acq_era_207-v207/GEN-SIM-RAW"
+
+	// list files in all output formats
+	out, err = run("-format", "json", "files", "-dataset", dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []dbs.Record
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 12 {
+		t.Fatalf("wrong number of files %d", len(records))
+	}
+	out, err = run("-format", "ndjson", "files", "-dataset", dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 12 {
+		t.Errorf("wrong number of ndjson records %d", len(lines))
+	}
+	out, err = run("files", "-dataset", dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 13 || !strings.HasPrefix(lines[0], "LOGICAL_FILE_NAME") {
+		t.Errorf("wrong table output\n%s", out)
+	}
+
+	// invalidate dataset and its files
+	if err := c.InsertDatasetAccessTypes(dbs.DatasetAccessTypes{DATASET_ACCESS_TYPE: "INVALID"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("invalidate", "-dataset", dataset); err != nil {
+		t.Fatal(err)
+	}
+	out, err = run("-format", "json", "files", "-dataset", dataset, "-validFileOnly", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("dataset files are not invalidated %s", out)
+	}
+
+	// the same instance has no differences
+	out, err = run("-format", "json", "compare", "-url2", c.URL, "files", "-dataset", dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("wrong compare output %s", out)
+	}
+}
+
+// TestCLIWatch tests that dbs command line tool keeps watching migration
+// request after its retryable failure
+func TestCLIWatch(t *testing.T) {
+	statuses := []int64{dbs.PENDING, dbs.IN_PROGRESS, dbs.FAILED, dbs.IN_PROGRESS, dbs.COMPLETED}
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/submit"):
+			fmt.Fprintf(w, `[{"migration_details":{"migration_request_id":1,"migration_status":%d},"migration_report":"","status":"PENDING","error":null}]`, dbs.PENDING)
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			status := statuses[len(statuses)-1]
+			if calls < len(statuses) {
+				status = statuses[calls]
+			}
+			calls++
+			fmt.Fprintf(w, `[{"migration_request_id":1,"migration_status":%d}]`, status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var out bytes.Buffer
+	args := []string{"-url", ts.URL, "-format", "ndjson", "migrate", "-watch", "-interval", "1ms",
+		"-from", "http://localhost:8989/dbs", "-input", "/a/b/RAW#1"}
+	if err := cli.Run(args, &out); err != nil {
+		t.Fatal(err)
+	}
+	if calls != len(statuses) {
+		t.Errorf("watch stopped after %d status calls, expect %d", calls, len(statuses))
+	}
+	var seen []int64
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[1:] {
+		var req dbs.MigrationRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, req.MIGRATION_STATUS)
+	}
+	if fmt.Sprint(seen) != fmt.Sprint(statuses) {
+		t.Errorf("wrong watched statuses %v, expect %v", seen, statuses)
+	}
+
+	// terminally failed migration request is reported as error
+	statuses = []int64{dbs.FAILED, dbs.TERM_FAILED}
+	calls = 0
+	out.Reset()
+	if err := cli.Run(args, &out); err == nil {
+		t.Error("no error for terminally failed migration request")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmwm/dbs2go/client"
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestClient tests DBS client against DBS writer server
+func TestClient(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+
+	// insert data tier, unset server attributes should be assigned by server
+	if err := c.InsertDataTiers(dbs.DataTiers{DATA_TIER_NAME: "GEN-SIM"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := c.DataTiers(url.Values{"data_tier_name": []string{"GEN-SIM"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0]["data_tier_name"] != "GEN-SIM" {
+		t.Fatalf("wrong data tiers %+v", records)
+	}
+
+	// ndjson iterator
+	iter, err := c.Stream("datatiers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	var rec dbs.Record
+	for iter.Next(&rec) {
+		count++
+	}
+	iter.Close()
+	if iter.Err() != nil || count == 0 {
+		t.Fatalf("wrong iteration over data tiers, count %d error %v", count, iter.Err())
+	}
+
+	// server errors are decoded into client errors
+	err = c.InsertDatasets(dbs.DatasetRecord{DATASET: "bla", DATA_TIER_NAME: "GEN-SIM"})
+	var cerr *client.Error
+	if !errors.As(err, &cerr) {
+		t.Fatalf("wrong error type %T %v", err, err)
+	}
+	if cerr.HTTPCode != http.StatusBadRequest || len(cerr.Violations) == 0 {
+		t.Errorf("wrong client error %+v", cerr)
+	}
+	var derr *dbs.DBSError
+	if !errors.As(err, &derr) || derr.Code != dbs.ValidateErrorCode {
+		t.Errorf("client error does not wrap DBS error %v", err)
+	}
+}
+
+// TestClientRetry tests that DBS client used by migration code retries
+// requests to temporarily unavailable DBS server
+func TestClientRetry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"block_name":"/a/b/RAW#1"}]`))
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL)
+	c.HTTPClient = server.Client()
+	c.RetryDelay = time.Millisecond
+	remote := dbs.RemoteClient
+	dbs.RemoteClient = func(rurl string) dbs.RemoteDBS { return c }
+	defer func() { dbs.RemoteClient = remote }()
+	blocks, err := dbs.GetBlocks(server.URL, "/a/b/RAW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(blocks) != 1 || blocks[0] != "/a/b/RAW#1" {
+		t.Errorf("wrong blocks %v after %d calls", blocks, calls)
+	}
+
+	// client gives up after its retries
+	c.Retries = 0
+	calls = 0
+	if _, err := dbs.GetBlocks(server.URL, "/a/b/RAW"); err == nil {
+		t.Error("no error for unavailable DBS server")
+	}
+}
+
+// TestClientFileValidity tests bulk update of file validity
+func TestClientFileValidity(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+	data, err := os.ReadFile("data/bulkblocks1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bulk dbs.BulkBlocks
+	if err := json.Unmarshal(data, &bulk); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	lfn := "/store/mc/Fall08/BBJets250to500-madgraph/GEN-SIM/StepChain_/207/%d.root"
+	dbs.FileUpdateChunkSize = 2
+	defer func() { dbs.FileUpdateChunkSize = 1000 }()
+
+	// injected files are invalid, files which are not found are reported
+	missing := fmt.Sprintf(lfn, 100)
+	lfns := []string{fmt.Sprintf(lfn, 0), fmt.Sprintf(lfn, 1), fmt.Sprintf(lfn, 2), missing}
+	tests := []struct {
+		rec      dbs.Record
+		matched  int64
+		changed  int64
+		notFound int
+	}{
+		{dbs.Record{"logical_file_name": lfns, "is_file_valid": 1}, 3, 3, 1},
+		{dbs.Record{"logical_file_name": lfns, "is_file_valid": 1}, 3, 0, 1},
+		{dbs.Record{"block_name": bulk.Block.BlockName, "is_file_valid": 0}, 12, 3, 0},
+		{dbs.Record{"block_name": bulk.Block.BlockName, "run_num": 98, "lumi_list": []int{27414, 27415}, "is_file_valid": 1}, 4, 4, 0},
+		{dbs.Record{"dataset": bulk.Dataset.Dataset, "run_num": []int{97, 98}, "is_file_valid": 1}, 12, 8, 0},
+	}
+	for _, tt := range tests {
+		report, err := c.UpdateFileValidity(tt.rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Matched != tt.matched || report.Changed != tt.changed || len(report.NotFound) != tt.notFound {
+			t.Errorf("wrong report %+v for %v", report, tt.rec)
+		}
+	}
+
+	// run selection requires block or dataset
+	if _, err := c.UpdateFileValidity(dbs.Record{"run_num": 98, "is_file_valid": 0}); err == nil {
+		t.Error("no error for run selection without block or dataset")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/dmwm/dbs2go/client"
+	"github.com/dmwm/dbs2go/dbs"
+	"github.com/dmwm/dbs2go/web"
+)
+
+// helper function to load bulkblocks record from given file
+func loadBulkBlocks(t *testing.T, fname string) dbs.BulkBlocks {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bulk dbs.BulkBlocks
+	if err := json.Unmarshal(data, &bulk); err != nil {
+		t.Fatal(err)
+	}
+	return bulk
+}
+
+// TestDelete tests soft and hard deletion of blocks and datasets
+func TestDelete(t *testing.T) {
+	c := newAdminClient(t)
+
+	// inject parent dataset and its child dataset
+	parent := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := loadBulkBlocks(t, "data/bulkblocks0.json")
+	child.DatasetParentList = []string{parent.Dataset.Dataset}
+	child.FileParentList = []dbs.FileParentRecord{{
+		ThisLogicalFileName:   child.Files[0].LogicalFileName,
+		ParentLogicalFileName: parent.Files[0].LogicalFileName,
+	}}
+	if err := c.InsertBulkBlocks(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InsertDatasetAccessTypes(dbs.DatasetAccessTypes{DATASET_ACCESS_TYPE: "DELETED"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// dry-run reports rows without deleting them
+	report, err := c.Delete(dbs.DeleteRecord{Dataset: child.Dataset.Dataset, Mode: dbs.HardDelete, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]int64{
+		"FILES": 10, "FILE_LUMIS": 30, "FILE_PARENTS": 1, "BLOCKS": 1,
+		"DATASETS": 1, "DATASET_PARENTS": 1,
+	}
+	for table, count := range expect {
+		if report.Rows[table] != count {
+			t.Errorf("wrong dry-run count of %s, %d instead of %d", table, report.Rows[table], count)
+		}
+	}
+	params := url.Values{"dataset": []string{child.Dataset.Dataset}, "dataset_access_type": []string{"*"}}
+	var datasets []dbs.Record
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 {
+		t.Fatalf("dry-run modified dataset %v, error %v", datasets, err)
+	}
+
+	// dataset and block with children can not be deleted
+	for _, rec := range []dbs.DeleteRecord{
+		{Dataset: parent.Dataset.Dataset, Mode: dbs.HardDelete},
+		{BlockName: parent.Block.BlockName},
+	} {
+		_, err = c.Delete(rec)
+		var derr *dbs.DBSError
+		if !errors.As(err, &derr) || derr.Code != dbs.DeleteChildrenErrorCode {
+			t.Errorf("wrong error for deletion of %+v, %v", rec, err)
+		}
+	}
+	if _, err := c.Delete(dbs.DeleteRecord{BlockName: parent.Block.BlockName, Mode: "bla"}); err == nil {
+		t.Error("no error for unsupported deletion mode")
+	}
+
+	// hard deletion of child dataset
+	report, err = c.Delete(dbs.DeleteRecord{Dataset: child.Dataset.Dataset, Mode: dbs.HardDelete, Reason: "test data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for table, count := range expect {
+		if report.Rows[table] != count {
+			t.Errorf("wrong deleted count of %s, %d instead of %d", table, report.Rows[table], count)
+		}
+	}
+	datasets = nil
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 0 {
+		t.Fatalf("dataset is not deleted %v, error %v", datasets, err)
+	}
+
+	// soft deletion of parent block and dataset which no longer have children
+	report, err = c.Delete(dbs.DeleteRecord{BlockName: parent.Block.BlockName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Mode != dbs.SoftDelete || report.Rows["FILES"] != 12 {
+		t.Errorf("wrong soft deletion report %+v", report)
+	}
+	var files []dbs.Record
+	if err := c.Get("files", url.Values{"block_name": []string{parent.Block.BlockName}}, &files); err != nil || len(files) != 0 {
+		t.Errorf("files of soft deleted block are listed %v, error %v", files, err)
+	}
+	if _, err := c.Delete(dbs.DeleteRecord{Dataset: parent.Dataset.Dataset, Reason: "test data"}); err != nil {
+		t.Fatal(err)
+	}
+	params = url.Values{"dataset": []string{parent.Dataset.Dataset}, "dataset_access_type": []string{"DELETED"}}
+	datasets = nil
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 {
+		t.Errorf("dataset is not marked as deleted %v, error %v", datasets, err)
+	}
+
+	// every deletion except dry-run is audited
+	audit, err := c.Deletions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit) != 3 {
+		t.Fatalf("wrong number of deletion audit records %+v", audit)
+	}
+	if audit[0].ENTITY_NAME != child.Dataset.Dataset || audit[0].DELETION_MODE != dbs.HardDelete || audit[0].REASON != "test data" {
+		t.Errorf("wrong deletion audit record %+v", audit[0])
+	}
+	audit, err = c.Deletions(url.Values{"entity_type": []string{"block"}})
+	if err != nil || len(audit) != 1 || audit[0].ENTITY_NAME != parent.Block.BlockName {
+		t.Errorf("wrong block deletion audit records %+v, error %v", audit, err)
+	}
+
+	// soft deleted dataset can be hard deleted, it leaves DB clean for other
+	// admin tests which inject the same dataset
+	if _, err := c.Delete(dbs.DeleteRecord{Dataset: parent.Dataset.Dataset, Mode: dbs.HardDelete, Reason: "test data"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDeleteUnauthorized tests that admin APIs reject requests of users
+// without admin role and all requests when admin roles are not configured
+func TestDeleteUnauthorized(t *testing.T) {
+	c := newAdminClient(t)
+	rec := dbs.DeleteRecord{Dataset: "/a/b/RAW", DryRun: true}
+	unauthorized := func(c *client.Client) {
+		_, err := c.Delete(rec)
+		var e *client.Error
+		if !errors.As(err, &e) || e.HTTPCode != http.StatusUnauthorized {
+			t.Errorf("unauthorized delete request is not rejected, error %v", err)
+		}
+	}
+
+	// user without admin role
+	user := client.New(c.URL)
+	user.HTTPClient = http.DefaultClient
+	unauthorized(user)
+
+	// admin roles are not configured
+	web.Config.AdminRole = nil
+	web.Config.AdminGroup = nil
+	unauthorized(c)
+}
@@ -2,19 +2,31 @@ package main
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dmwm/dbs2go/dbs"
 	"github.com/dmwm/dbs2go/utils"
 	"github.com/dmwm/dbs2go/web"
+	"github.com/gorilla/mux"
 	_ "github.com/mattn/go-oci8"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -232,3 +244,276 @@ func TestHTTPPut(t *testing.T) {
 		t.Errorf("acquisition era is not found after GET request")
 	}
 }
+
+// TestHTTPHealth provides test of liveness and readiness endpoints
+func TestHTTPHealth(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// liveness does not depend on any service
+	if _, err := respRecorder("GET", "/dbs2go/livez", nil, web.LivezHandler); err != nil {
+		t.Fatal(err)
+	}
+
+	// server is not ready until lexicon patterns are loaded
+	patterns := dbs.LexiconPatterns
+	defer func() { dbs.LexiconPatterns = patterns }()
+	dbs.LexiconPatterns = nil
+	if _, err := respRecorder("GET", "/dbs2go/readyz", nil, web.ReadyzHandler); err == nil {
+		t.Fatal("server is ready without lexicon patterns")
+	}
+	lexPatterns, err := dbs.LoadPatterns(os.Getenv("DBS_LEXICON_FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.LexiconPatterns = lexPatterns
+	rr, err := respRecorder("GET", "/dbs2go/readyz", nil, web.ReadyzHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []struct {
+		Status int               `json:"status"`
+		Checks []dbs.HealthCheck `json:"checks"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Status != http.StatusOK {
+		t.Fatalf("wrong readiness status %+v", records)
+	}
+	checks := make(map[string]dbs.HealthCheck)
+	for _, c := range records[0].Checks {
+		checks[c.Name] = c
+	}
+	for _, name := range []string{"database", "templates", "drain"} {
+		if c, ok := checks[name]; !ok || c.Status != dbs.HealthOK {
+			t.Fatalf("wrong %s check %+v", name, c)
+		}
+	}
+	if checks["database"].Latency <= 0 {
+		t.Fatalf("database latency is not reported %+v", checks["database"])
+	}
+
+	// migration server is not ready until its daemon reports heartbeat
+	web.Config.ServerType = "DBSMigration"
+	defer func() { web.Config.ServerType = "" }()
+	if _, err := respRecorder("GET", "/dbs2go/readyz", nil, web.ReadyzHandler); err == nil {
+		t.Fatal("migration server is ready without migration daemon")
+	}
+	dbs.MigrationHeartbeat()
+	if _, err := respRecorder("GET", "/dbs2go/readyz", nil, web.ReadyzHandler); err != nil {
+		t.Fatal(err)
+	}
+
+	// readiness checks can run while migration code reloads TLS certificates
+	ckey, cert := writeTestCert(t)
+	interval := dbs.TlsRefreshInterval
+	defer func() { dbs.TlsRefreshInterval = interval }()
+	dbs.TlsRefreshInterval = 0
+	dbs.HttpClient(ckey, cert, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			dbs.HttpClient(ckey, cert, 1)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if _, err := respRecorder("GET", "/dbs2go/readyz", nil, web.ReadyzHandler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
+
+// helper function to write self-signed X509 key and certificate files
+func writeTestCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dbs2go test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	ckey := filepath.Join(dir, "userkey.pem")
+	cert := filepath.Join(dir, "usercert.pem")
+	kdata := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+	cdata := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(ckey, kdata, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cert, cdata, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ckey, cert
+}
+
+// TestHTTPSchema tests schema API
+func TestHTTPSchema(t *testing.T) {
+	lexPatterns, err := dbs.LoadPatterns(os.Getenv("DBS_LEXICON_FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.LexiconPatterns = lexPatterns
+	dbs.ApiParametersFile = os.Getenv("DBS_API_PARAMETERS_FILE")
+	web.Config.ServerType = "DBSWriter"
+	defer func() { web.Config.ServerType = "" }()
+	web.Handlers()
+
+	rr, err := respRecorder("GET", "/dbs2go/schema?api=datasets", nil, web.SchemaHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []dbs.ApiSchema
+	if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	methods := make(map[string]dbs.ApiSchema)
+	for _, rec := range records {
+		methods[rec.Method] = rec
+	}
+	for _, m := range []string{"GET", "POST", "PUT"} {
+		if _, ok := methods[m]; !ok {
+			t.Fatalf("no %s schema of datasets API in %+v", m, records)
+		}
+	}
+
+	// GET parameters should carry their types and lexicon patterns
+	params := make(map[string]dbs.ParameterSchema)
+	for _, p := range methods["GET"].Parameters {
+		params[p.Name] = p
+	}
+	if p := params["dataset"]; p.Type != "string" || len(p.Patterns) == 0 || p.Length == 0 {
+		t.Errorf("wrong dataset parameter schema %+v", p)
+	}
+	if p := params["min_cdate"]; p.Type != "integer" {
+		t.Errorf("wrong min_cdate parameter schema %+v", p)
+	}
+
+	// POST body schema is generated from DatasetRecord
+	body := methods["POST"].Body
+	if body["type"] != "object" {
+		t.Fatalf("wrong body schema %+v", body)
+	}
+	props := body["properties"].(map[string]interface{})
+	dataset := props["dataset"].(map[string]interface{})
+	if dataset["type"] != "string" || dataset["pattern"] == nil || dataset["maxLength"] == nil {
+		t.Errorf("wrong dataset body schema %+v", dataset)
+	}
+
+	// nested records of bulkblocks are described as well
+	rr, err = respRecorder("GET", "/dbs2go/schema?api=bulkblocks&method=post", nil, web.SchemaHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("wrong number of bulkblocks schemas %+v", records)
+	}
+	props = records[0].Body["properties"].(map[string]interface{})
+	files := props["files"].(map[string]interface{})
+	items := files["items"].(map[string]interface{})
+	fprops := items["properties"].(map[string]interface{})
+	if _, ok := fprops["logical_file_name"]; !ok {
+		t.Errorf("no logical_file_name in bulkblocks files schema %+v", fprops)
+	}
+}
+
+// TestHTTPOpenAPI tests that OpenAPI specification matches HTTP routes of all
+// DBS server types
+func TestHTTPOpenAPI(t *testing.T) {
+	lexPatterns, err := dbs.LoadPatterns(os.Getenv("DBS_LEXICON_FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.LexiconPatterns = lexPatterns
+	dbs.ApiParametersFile = os.Getenv("DBS_API_PARAMETERS_FILE")
+	defer func() { web.Config.ServerType = "" }()
+	for _, srv := range []string{"DBSReader", "DBSWriter", "DBSMigrate", "DBSMigration"} {
+		web.Config.ServerType = srv
+		router := web.Handlers()
+
+		// collect actual routes of the server
+		routes := make(map[string]bool)
+		router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			path, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return err
+			}
+			for _, m := range methods {
+				routes[strings.ToLower(m)+" "+path] = true
+			}
+			return nil
+		})
+
+		rr, err := respRecorder("GET", "/dbs2go/openapi.json", nil, web.OpenAPIHandler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var spec struct {
+			OpenAPI string                                `json:"openapi"`
+			Info    map[string]interface{}                `json:"info"`
+			Paths   map[string]map[string]json.RawMessage `json:"paths"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &spec); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(spec.OpenAPI, "3.") {
+			t.Errorf("%s: wrong OpenAPI version %s", srv, spec.OpenAPI)
+		}
+		if spec.Info["title"] != srv+" server" {
+			t.Errorf("%s: wrong OpenAPI title %v", srv, spec.Info["title"])
+		}
+		operations := make(map[string]bool)
+		for path, item := range spec.Paths {
+			for method := range item {
+				operations[method+" "+path] = true
+			}
+		}
+		for op := range routes {
+			if !operations[op] {
+				t.Errorf("%s: route '%s' is not described in OpenAPI specification", srv, op)
+			}
+		}
+		for op := range operations {
+			if !routes[op] {
+				t.Errorf("%s: OpenAPI operation '%s' does not exist", srv, op)
+			}
+		}
+	}
+
+	// writer specification should describe bulkblocks payload
+	web.Config.ServerType = "DBSWriter"
+	web.Handlers()
+	rr, err := respRecorder("GET", "/dbs2go/openapi.json", nil, web.OpenAPIHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(rr.Body.Bytes(), []byte(`"dataset_parent_list"`)) {
+		t.Error("OpenAPI specification does not describe bulkblocks payload")
+	}
+}
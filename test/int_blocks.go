@@ -24,18 +24,19 @@ type blockResponse struct {
 
 // detailed blocks API response
 type blockDetailResponse struct {
-	BlockID              int64  `json:"block_id"`
-	DatasetID            int64  `json:"dataset_id"`
-	CreateBy             string `json:"create_by"`
-	CreationDate         int64  `json:"creation_date"`
-	Dataset              string `json:"dataset"`
-	OpenForWriting       int64  `json:"open_for_writing"`
-	BlockName            string `json:"block_name"`
-	FileCount            int64  `json:"file_count"`
-	OriginSiteName       string `json:"origin_site_name"`
-	BlockSize            int64  `json:"block_size"`
-	LastModifiedBy       string `json:"last_modified_by"`
-	LastModificationDate int64  `json:"last_modification_date"`
+	BlockID              int64             `json:"block_id"`
+	DatasetID            int64             `json:"dataset_id"`
+	CreateBy             string            `json:"create_by"`
+	CreationDate         int64             `json:"creation_date"`
+	Dataset              string            `json:"dataset"`
+	OpenForWriting       int64             `json:"open_for_writing"`
+	BlockName            string            `json:"block_name"`
+	FileCount            int64             `json:"file_count"`
+	OriginSiteName       string            `json:"origin_site_name"`
+	BlockSize            int64             `json:"block_size"`
+	LastModifiedBy       string            `json:"last_modified_by"`
+	LastModificationDate int64             `json:"last_modification_date"`
+	Tags                 map[string]string `json:"tags"`
 }
 
 // blocks endpoint tests
@@ -71,6 +72,7 @@ func getBlocksTestTable(t *testing.T) EndpointTestCase {
 		LastModifiedBy:       TestData.CreateBy,
 		OpenForWriting:       0,
 		OriginSiteName:       TestData.Site,
+		Tags:                 map[string]string{},
 	}
 	blockParentDetailResp := blockDetailResponse{
 		BlockID:              2,
@@ -85,12 +87,13 @@ func getBlocksTestTable(t *testing.T) EndpointTestCase {
 		LastModifiedBy:       TestData.CreateBy,
 		OpenForWriting:       0,
 		OriginSiteName:       TestData.Site,
+		Tags:                 map[string]string{},
 	}
 	dbsError := dbs.DBSError{
 		Function: "dbs.blocks.Blocks",
 		Code:     dbs.InvalidParameterErrorCode,
 		Reason:   dbs.InvalidParamErr.Error(),
-		Message:  "Blocks API requires one of the following: [dataset block_name data_tier_name logical_file_name]",
+		Message:  "Blocks API requires one of the following: [dataset block_name data_tier_name logical_file_name tag]",
 	}
 	hrec := createHTTPError("GET", "/dbs/blocks?origin_site_name=cmssrm.fnal.gov")
 	errorResp := createServerErrorResponse(hrec, &dbsError)
@@ -313,19 +316,20 @@ type blockUpdateSiteRequest struct {
 
 // detailed blocks API response
 type blockRunDetailResponse struct {
-	BlockID              int64  `json:"block_id"`
-	DatasetID            int64  `json:"dataset_id"`
-	CreateBy             string `json:"create_by"`
-	CreationDate         int64  `json:"creation_date"`
-	Dataset              string `json:"dataset"`
-	OpenForWriting       int64  `json:"open_for_writing"`
-	BlockName            string `json:"block_name"`
-	FileCount            int64  `json:"file_count"`
-	OriginSiteName       string `json:"origin_site_name"`
-	BlockSize            int64  `json:"block_size"`
-	LastModifiedBy       string `json:"last_modified_by"`
-	LastModificationDate int64  `json:"last_modification_date"`
-	RunNum               int64  `json:"run_num"`
+	BlockID              int64             `json:"block_id"`
+	DatasetID            int64             `json:"dataset_id"`
+	CreateBy             string            `json:"create_by"`
+	CreationDate         int64             `json:"creation_date"`
+	Dataset              string            `json:"dataset"`
+	OpenForWriting       int64             `json:"open_for_writing"`
+	BlockName            string            `json:"block_name"`
+	FileCount            int64             `json:"file_count"`
+	OriginSiteName       string            `json:"origin_site_name"`
+	BlockSize            int64             `json:"block_size"`
+	LastModifiedBy       string            `json:"last_modified_by"`
+	LastModificationDate int64             `json:"last_modification_date"`
+	RunNum               int64             `json:"run_num"`
+	Tags                 map[string]string `json:"tags"`
 }
 
 // create a detailed response with run_num
@@ -344,6 +348,7 @@ func createBlockRunDetailResponse(blockID int64, runNum int) blockRunDetailRespo
 		OpenForWriting:       0,
 		OriginSiteName:       TestData.Site,
 		RunNum:               int64(runNum),
+		Tags:                 map[string]string{},
 	}
 }
 
@@ -370,12 +375,19 @@ func getBlocksTestTable2(t *testing.T) EndpointTestCase {
 		LastModifiedBy:       TestData.CreateBy,
 		OpenForWriting:       0,
 		OriginSiteName:       TestData.Site,
+		Tags:                 map[string]string{},
 	}
 	blockDetailResp2 := blockDetailResp
 	blockDetailResp2.OpenForWriting = 1
 	blockDetailResp2.LastModifiedBy = "DBS-workflow"
 	blockDetailResp3 := blockDetailResp2
 	blockDetailResp3.OriginSiteName = "cmssrm2.fnal.gov"
+	// closing of the block recomputes its stats, and one of its files
+	// is invalidated by files update tests
+	blockDetailResp4 := blockDetailResp3
+	blockDetailResp4.OpenForWriting = 0
+	blockDetailResp4.FileCount = 9
+	blockDetailResp4.BlockSize = 18109907109
 
 	runs := strings.ReplaceAll(fmt.Sprint(TestData.Runs), " ", ",")
 	var blockRunDetailResp []Response
@@ -456,6 +468,69 @@ func getBlocksTestTable2(t *testing.T) EndpointTestCase {
 				output:   []Response{blockDetailResp3},
 				respCode: http.StatusOK,
 			},
+			{
+				description: "Test GET staleblocks for recently modified block",
+				serverType:  "DBSReader",
+				method:      "GET",
+				endpoint:    "/dbs/staleblocks",
+				handler:     web.StaleBlocksHandler,
+				params: url.Values{
+					"block_name": []string{TestData.Block},
+					"idle_hours": []string{"1"},
+				},
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET staleblocks with dataset",
+				serverType:  "DBSReader",
+				method:      "GET",
+				endpoint:    "/dbs/staleblocks",
+				handler:     web.StaleBlocksHandler,
+				params: url.Values{
+					"dataset":    []string{TestData.Dataset},
+					"idle_hours": []string{"0"},
+					"fields":     []string{"block_name"},
+				},
+				output:   []Response{blockResponse{BLOCK_NAME: TestData.Block}},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test close block",
+				serverType:  "DBSWriter",
+				method:      "PUT",
+				params: url.Values{
+					"block_name":       []string{TestData.Block},
+					"open_for_writing": []string{"0"},
+				},
+				input:    blockUpdateStatusRequest{BLOCK_NAME: TestData.Block, OPEN_FOR_WRITING: "0"},
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET staleblocks after close",
+				serverType:  "DBSReader",
+				method:      "GET",
+				endpoint:    "/dbs/staleblocks",
+				handler:     web.StaleBlocksHandler,
+				params: url.Values{
+					"dataset":    []string{TestData.Dataset},
+					"idle_hours": []string{"0"},
+				},
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "GET block after close",
+				serverType:  "DBSReader",
+				method:      "GET",
+				params: url.Values{
+					"block_name": []string{TestData.Block},
+					"detail":     []string{"true"},
+				},
+				output:   []Response{blockDetailResp4},
+				respCode: http.StatusOK,
+			},
 		},
 	}
 }
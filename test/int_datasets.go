@@ -22,55 +22,76 @@ type datasetsResponse struct {
 	DATASET string `json:"dataset"`
 }
 
+// struct for datasets GET response with fields parameter
+type datasetsFieldsResponse struct {
+	DATASET         string `json:"dataset"`
+	PRIMARY_DS_TYPE string `json:"primary_ds_type"`
+	RELEASE_VERSION string `json:"release_version"`
+}
+
+// struct for datasetstats GET response grouped by data tier
+type datasetStatsResponse struct {
+	DATA_TIER_NAME string `json:"data_tier_name"`
+	NUM_DATASET    int64  `json:"num_dataset"`
+	NUM_FILE       int64  `json:"num_file"`
+}
+
 // struct for datasets GET response with parent_dataset parameter
 type datasetsWithParentsResponse struct {
 	DATASET        string `json:"dataset"`
 	PARENT_DATASET string `json:"parent_dataset"`
 }
 
+// struct for datasets GET response with parent_dataset field only
+type datasetsParentFieldResponse struct {
+	PARENT_DATASET string `json:"parent_dataset"`
+}
+
 // struct for datasets GET response with detail=true query parameter
 type datasetsDetailResponse struct {
-	DATASET_ID             int64  `json:"dataset_id"`
-	PHYSICS_GROUP_NAME     string `json:"physics_group_name"`
-	DATASET                string `json:"dataset"`
-	DATASET_ACCESS_TYPE    string `json:"dataset_access_type"`
-	PROCESSED_DS_NAME      string `json:"processed_ds_name"`
-	PREP_ID                string `json:"prep_id"`
-	PRIMARY_DS_NAME        string `json:"primary_ds_name"`
-	XTCROSSSECTION         int64  `json:"xtcrosssection"`
-	DATA_TIER_NAME         string `json:"data_tier_name"`
-	PRIMARY_DS_TYPE        string `json:"primary_ds_type"`
-	CREATION_DATE          int64  `json:"creation_date"`
-	CREATE_BY              string `json:"create_by"`
-	LAST_MODIFICATION_DATE int64  `json:"last_modification_date"`
-	LAST_MODIFIED_BY       string `json:"last_modified_by"`
-	PROCESSING_VERSION     int64  `json:"processing_version"`
-	ACQUISITION_ERA_NAME   string `json:"acquisition_era_name"`
+	DATASET_ID             int64             `json:"dataset_id"`
+	PHYSICS_GROUP_NAME     string            `json:"physics_group_name"`
+	DATASET                string            `json:"dataset"`
+	DATASET_ACCESS_TYPE    string            `json:"dataset_access_type"`
+	PROCESSED_DS_NAME      string            `json:"processed_ds_name"`
+	PREP_ID                string            `json:"prep_id"`
+	PRIMARY_DS_NAME        string            `json:"primary_ds_name"`
+	XTCROSSSECTION         int64             `json:"xtcrosssection"`
+	DATA_TIER_NAME         string            `json:"data_tier_name"`
+	PRIMARY_DS_TYPE        string            `json:"primary_ds_type"`
+	CREATION_DATE          int64             `json:"creation_date"`
+	CREATE_BY              string            `json:"create_by"`
+	LAST_MODIFICATION_DATE int64             `json:"last_modification_date"`
+	LAST_MODIFIED_BY       string            `json:"last_modified_by"`
+	PROCESSING_VERSION     int64             `json:"processing_version"`
+	ACQUISITION_ERA_NAME   string            `json:"acquisition_era_name"`
+	TAGS                   map[string]string `json:"tags"`
 }
 
 // struct for datasets GET response with detail and when tmpl["Version"] is true
 type datasetsDetailVersionResponse struct {
-	DATASET_ID             int64  `json:"dataset_id"`
-	PHYSICS_GROUP_NAME     string `json:"physics_group_name"`
-	DATASET                string `json:"dataset"`
-	DATASET_ACCESS_TYPE    string `json:"dataset_access_type"`
-	PROCESSED_DS_NAME      string `json:"processed_ds_name"`
-	PREP_ID                string `json:"prep_id"`
-	PRIMARY_DS_NAME        string `json:"primary_ds_name"`
-	XTCROSSSECTION         int64  `json:"xtcrosssection"`
-	DATA_TIER_NAME         string `json:"data_tier_name"`
-	PRIMARY_DS_TYPE        string `json:"primary_ds_type"`
-	CREATION_DATE          int64  `json:"creation_date"`
-	CREATE_BY              string `json:"create_by"`
-	LAST_MODIFICATION_DATE int64  `json:"last_modification_date"`
-	LAST_MODIFIED_BY       string `json:"last_modified_by"`
-	PROCESSING_VERSION     int64  `json:"processing_version"`
-	ACQUISITION_ERA_NAME   string `json:"acquisition_era_name"`
-	OUTPUT_MODULE_LABEL    string `json:"output_module_label"`
-	GLOBAL_TAG             string `json:"global_tag"`
-	RELEASE_VERSION        string `json:"release_version"`
-	PSET_HASH              string `json:"pset_hash"`
-	APP_NAME               string `json:"app_name"`
+	DATASET_ID             int64             `json:"dataset_id"`
+	PHYSICS_GROUP_NAME     string            `json:"physics_group_name"`
+	DATASET                string            `json:"dataset"`
+	DATASET_ACCESS_TYPE    string            `json:"dataset_access_type"`
+	PROCESSED_DS_NAME      string            `json:"processed_ds_name"`
+	PREP_ID                string            `json:"prep_id"`
+	PRIMARY_DS_NAME        string            `json:"primary_ds_name"`
+	XTCROSSSECTION         int64             `json:"xtcrosssection"`
+	DATA_TIER_NAME         string            `json:"data_tier_name"`
+	PRIMARY_DS_TYPE        string            `json:"primary_ds_type"`
+	CREATION_DATE          int64             `json:"creation_date"`
+	CREATE_BY              string            `json:"create_by"`
+	LAST_MODIFICATION_DATE int64             `json:"last_modification_date"`
+	LAST_MODIFIED_BY       string            `json:"last_modified_by"`
+	PROCESSING_VERSION     int64             `json:"processing_version"`
+	ACQUISITION_ERA_NAME   string            `json:"acquisition_era_name"`
+	OUTPUT_MODULE_LABEL    string            `json:"output_module_label"`
+	GLOBAL_TAG             string            `json:"global_tag"`
+	RELEASE_VERSION        string            `json:"release_version"`
+	PSET_HASH              string            `json:"pset_hash"`
+	APP_NAME               string            `json:"app_name"`
+	TAGS                   map[string]string `json:"tags"`
 }
 
 // creates a dataset request
@@ -120,6 +141,7 @@ func createDetailDSResponse(datasetID int64, dataset string, procdataset string,
 		LAST_MODIFIED_BY:       TestData.CreateBy,
 		PROCESSING_VERSION:     TestData.ProcessingVersion,
 		ACQUISITION_ERA_NAME:   TestData.AcquisitionEra,
+		TAGS:                   map[string]string{},
 	}
 }
 
@@ -147,6 +169,7 @@ func createDetailVersionDSResponse(datasetID int64, dataset string, procdataset
 		RELEASE_VERSION:        TestData.ReleaseVersion,
 		PSET_HASH:              TestData.PsetHash,
 		APP_NAME:               TestData.AppName,
+		TAGS:                   map[string]string{},
 	}
 }
 
@@ -251,6 +274,23 @@ func getDatasetsTestTable(t *testing.T) EndpointTestCase {
 				},
 				respCode: http.StatusOK,
 			},
+			{
+				description: "Test GET after POST with fields",
+				serverType:  "DBSReader",
+				method:      "GET",
+				params: url.Values{
+					"dataset": []string{TestData.Dataset},
+					"fields":  []string{"dataset", "primary_ds_type", "release_version"},
+				},
+				output: []Response{
+					datasetsFieldsResponse{
+						DATASET:         TestData.Dataset,
+						PRIMARY_DS_TYPE: TestData.PrimaryDSType,
+						RELEASE_VERSION: TestData.ReleaseVersion,
+					},
+				},
+				respCode: http.StatusOK,
+			},
 			{
 				description: "Test GET after POST with detail and ds access type wildcard", // DBSClientReader_t.test006b.2
 				serverType:  "DBSReader",
@@ -772,6 +812,54 @@ func getDatasetsTestTable2(t *testing.T) EndpointTestCase {
 				},
 				respCode: http.StatusOK,
 			},
+			{
+				description: "Test GET dataset children with parent_dataset field",
+				serverType:  "DBSReader",
+				method:      "GET",
+				params: url.Values{
+					"parent_dataset": []string{TestData.ParentDataset},
+					"fields":         []string{"parent_dataset"},
+				},
+				output: []Response{
+					datasetsParentFieldResponse{
+						PARENT_DATASET: TestData.ParentDataset,
+					},
+				},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET datasetstats grouped by data tier",
+				serverType:  "DBSReader",
+				method:      "GET",
+				endpoint:    "/dbs/datasetstats",
+				handler:     web.DatasetStatsHandler,
+				params: url.Values{
+					"dataset":  []string{fmt.Sprintf("/%s/*", TestData.PrimaryDSName)},
+					"group_by": []string{"data_tier_name"},
+					"fields":   []string{"data_tier_name,num_dataset,num_file"},
+				},
+				output: []Response{
+					datasetStatsResponse{
+						DATA_TIER_NAME: TestData.Tier,
+						NUM_DATASET:    2,
+						NUM_FILE:       int64(len(TestData.Files) + len(TestData.ParentFiles)),
+					},
+				},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET datasetstats with unsupported group_by",
+				serverType:  "DBSReader",
+				method:      "GET",
+				endpoint:    "/dbs/datasetstats",
+				handler:     web.DatasetStatsHandler,
+				params: url.Values{
+					"group_by": []string{"dataset"},
+				},
+				output:     []Response{},
+				respCode:   http.StatusBadRequest,
+				verifyFunc: func(*testing.T, []dbs.Record, []Response) {},
+			},
 			{
 				description: "Test GET dataset invalid parameter key",
 				serverType:  "DBSReader",
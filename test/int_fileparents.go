@@ -92,6 +92,29 @@ func getFileParentsTestTable(t *testing.T) EndpointTestCase {
 	fpRespList = append(fpRespList[:2], fpRespList[1:]...)
 	fpRespList[1] = fpR
 
+	// insert file parents by lumi requests
+	// all child files of TestData.Block share run/lumi pair with every parent file
+	fpByLumiDryRun := dbs.FileParentsByLumiRecord{
+		BlockName:     TestData.Block,
+		ParentDataset: TestData.ParentDataset,
+		DryRun:        true,
+	}
+	fpByLumi := dbs.FileParentsByLumiRecord{
+		BlockName:     TestData.Block,
+		ParentDataset: TestData.ParentDataset,
+	}
+	fpByLumiBad := dbs.FileParentsByLumiRecord{
+		BlockName: TestData.Block,
+	}
+	var fpByLumiResp []Response
+	for i, lfn := range TestData.ParentFiles {
+		fpByLumiResp = append(fpByLumiResp, fileParentResponse{
+			LOGICAL_FILE_NAME:        TestData.Files[1],
+			PARENT_FILE_ID:           i + 1,
+			PARENT_LOGICAL_FILE_NAME: lfn,
+		})
+	}
+
 	return EndpointTestCase{
 		description:     "Test fileparents",
 		defaultHandler:  web.FileParentsHandler,
@@ -168,6 +191,168 @@ func getFileParentsTestTable(t *testing.T) EndpointTestCase {
 				output:   fpRespList,
 				respCode: http.StatusOK,
 			},
+			{
+				description: "Test insertfileparentsbylumi without parent_dataset",
+				method:      "POST",
+				serverType:  "DBSWriter",
+				endpoint:    "/dbs/insertfileparentsbylumi",
+				params: url.Values{
+					"logical_file_name": []string{TestData.Files[1]},
+				},
+				input:    fpByLumiBad,
+				output:   []Response{},
+				respCode: http.StatusBadRequest,
+			},
+			{
+				description: "Test insertfileparentsbylumi with dry_run",
+				method:      "POST",
+				serverType:  "DBSWriter",
+				endpoint:    "/dbs/insertfileparentsbylumi",
+				params: url.Values{
+					"logical_file_name": []string{TestData.Files[1]},
+				},
+				input:    fpByLumiDryRun,
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test insertfileparentsbylumi",
+				method:      "POST",
+				serverType:  "DBSWriter",
+				endpoint:    "/dbs/insertfileparentsbylumi",
+				params: url.Values{
+					"logical_file_name": []string{TestData.Files[1]},
+				},
+				input:    fpByLumi,
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET fileparents after insertfileparentsbylumi",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"logical_file_name": []string{TestData.Files[1]},
+				},
+				output:   fpByLumiResp,
+				respCode: http.StatusOK,
+			},
+		},
+	}
+}
+
+// parentage audit endpoint tests
+func getParentageAuditTestTable(t *testing.T) EndpointTestCase {
+	childBlock := BulkBlocksData.ConcurrentChildData.Block.BlockName
+	childDataset := BulkBlocksData.ConcurrentChildData.Dataset.Dataset
+	parentDataset := BulkBlocksData.ConcurrentParentData.Dataset.Dataset
+
+	// files of concurrent child block are injected without file parents
+	// but they can be derived from their run/lumi pairs
+	findings := []dbs.ParentageFinding{}
+	for _, f := range BulkBlocksData.ConcurrentChildData.Files {
+		findings = append(findings, dbs.ParentageFinding{
+			Type: "missing_file_parents",
+			Name: f.LogicalFileName,
+		})
+	}
+	findings = append(findings, dbs.ParentageFinding{
+		Type:   "lumi_derivable_file_parents",
+		Name:   childBlock,
+		Parent: parentDataset,
+		Count:  int64(len(BulkBlocksData.ConcurrentChildData.Files)),
+	})
+	auditResp := dbs.ParentageAuditReport{
+		Dataset:        childDataset,
+		BlockName:      childBlock,
+		ParentDatasets: []string{parentDataset},
+		Findings:       findings,
+	}
+	auditFixedResp := dbs.ParentageAuditReport{
+		Dataset:        childDataset,
+		BlockName:      childBlock,
+		ParentDatasets: []string{parentDataset},
+		Findings:       []dbs.ParentageFinding{},
+	}
+	fpByLumi := dbs.FileParentsByLumiRecord{
+		BlockName:     childBlock,
+		ParentDataset: parentDataset,
+	}
+	auditFix := dbs.Record{
+		"block_name": childBlock,
+		"fix":        true,
+	}
+
+	return EndpointTestCase{
+		description:     "Test parentageaudit",
+		defaultHandler:  web.ParentageAuditHandler,
+		defaultEndpoint: "/dbs/parentageaudit",
+		testCases: []testCase{
+			{
+				description: "Test parentageaudit with no params",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params:      url.Values{},
+				output:      []Response{},
+				respCode:    http.StatusBadRequest,
+				verifyFunc:  func(*testing.T, []dbs.Record, []Response) {},
+			},
+			{
+				description: "Test parentageaudit of block without file parents",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"block_name": []string{childBlock},
+				},
+				output:   []Response{auditResp},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test parentageaudit does not accept fix in reader",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"block_name": []string{childBlock},
+					"fix":        []string{"true"},
+				},
+				output:     []Response{},
+				respCode:   http.StatusBadRequest,
+				verifyFunc: func(*testing.T, []dbs.Record, []Response) {},
+			},
+			{
+				description: "Test insertfileparentsbylumi for audited block",
+				method:      "POST",
+				serverType:  "DBSWriter",
+				endpoint:    "/dbs/insertfileparentsbylumi",
+				handler:     web.FileParentsHandler,
+				params: url.Values{
+					"block_name": []string{childBlock},
+				},
+				input:    fpByLumi,
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test parentageaudit with fix",
+				method:      "POST",
+				serverType:  "DBSWriter",
+				params: url.Values{
+					"block_name": []string{childBlock},
+				},
+				input:    auditFix,
+				output:   []Response{},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test parentageaudit after fix",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"block_name": []string{childBlock},
+				},
+				output:   []Response{auditFixedResp},
+				respCode: http.StatusOK,
+			},
 		},
 	}
 }
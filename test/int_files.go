@@ -23,6 +23,13 @@ type fileResponse struct {
 	LOGICAL_FILE_NAME string `json:"logical_file_name"`
 }
 
+// files API response with fields parameter
+type fileFieldsResponse struct {
+	LOGICAL_FILE_NAME string `json:"logical_file_name"`
+	IS_FILE_VALID     int64  `json:"is_file_valid"`
+	BLOCK_NAME        string `json:"block_name"`
+}
+
 // files API response with run_num
 type fileRunResponse struct {
 	LOGICAL_FILE_NAME string `json:"logical_file_name"`
@@ -500,6 +507,34 @@ func getFilesTestTable2(t *testing.T) EndpointTestCase {
 				},
 				respCode: http.StatusOK,
 			},
+			{
+				description: "Test GET with fields",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"logical_file_name": []string{lfn},
+					"fields":            []string{"logical_file_name,is_file_valid,block_name"},
+				},
+				output: []Response{
+					fileFieldsResponse{
+						LOGICAL_FILE_NAME: lfn,
+						IS_FILE_VALID:     0,
+						BLOCK_NAME:        TestData.Block,
+					},
+				},
+				respCode: http.StatusOK,
+			},
+			{
+				description: "Test GET with unknown field",
+				method:      "GET",
+				serverType:  "DBSReader",
+				params: url.Values{
+					"logical_file_name": []string{lfn},
+					"fields":            []string{"logical_file_name,bla"},
+				},
+				verifyFunc: func(t *testing.T, received []dbs.Record, expected []Response) {},
+				respCode:   http.StatusBadRequest,
+			},
 			{
 				description: "Test GET with dataset validFileOnly true", // DBSClientReader_t.test032a
 				method:      "GET",
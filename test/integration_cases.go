@@ -568,6 +568,7 @@ func LoadTestCases(t *testing.T, filepath string, bulkblockspath string, largeBu
 	filesReaderTestTable := getFilesLumiListRangeTestTable(t)
 	fileArrayTestTable := getFileArrayTestTable(t)
 	fileParentsTestTable := getFileParentsTestTable(t)
+	parentageAuditTestTable := getParentageAuditTestTable(t)
 	largeFileLumiInsertTestTable := getBulkBlocksLargeFileLumiInsertTestTable(t)
 	filesReaderAfterChunkTestTable := getFileLumiChunkTestTable(t)
 	theSameBlockWithBulkblocks := bulkblocksTheSameBlockInsertTestTable(t)
@@ -598,6 +599,7 @@ func LoadTestCases(t *testing.T, filepath string, bulkblockspath string, largeBu
 	endpointTestCases = append(
 		endpointTestCases,
 		fileParentsTestTable,
+		parentageAuditTestTable,
 		largeFileLumiInsertTestTable,
 		filesReaderAfterChunkTestTable,
 		theSameBlockWithBulkblocks,
@@ -12,6 +12,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/dmwm/dbs2go/client"
 	"github.com/dmwm/dbs2go/dbs"
 	"github.com/dmwm/dbs2go/utils"
 	"github.com/dmwm/dbs2go/web"
@@ -34,6 +35,72 @@ func initTestLimiter(t *testing.T, period string) {
 	web.LimiterMiddleware = stdlib.NewMiddleware(instance)
 }
 
+// helper function to start DBS server of given type on test DB along with
+// DBS client of this server, web.Config options which affect server routes
+// should be set before the call; the server, DB and web.Config server type
+// are reset when the test finishes
+func newTestClient(t *testing.T, serverType string) *client.Client {
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	t.Cleanup(func() { db.Close() })
+	lexPatterns, err := dbs.LoadPatterns(os.Getenv("DBS_LEXICON_FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.LexiconPatterns = lexPatterns
+	initTestLimiter(t, "100-S")
+	web.Config.ServerType = serverType
+	ts := httptest.NewServer(web.Handlers())
+	t.Cleanup(func() {
+		ts.Close()
+		web.Config.ServerType = ""
+	})
+
+	c := client.New(ts.URL)
+	c.HTTPClient = ts.Client()
+	return c
+}
+
+// headerTransport adds given HTTP headers to client requests
+type headerTransport struct {
+	Header    http.Header
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (h *headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for k, vals := range h.Header {
+		for _, v := range vals {
+			r.Header.Add(k, v)
+		}
+	}
+	return h.Transport.RoundTrip(r)
+}
+
+// helper function to start DBS writer server with admin APIs along with DBS
+// client which has CMS admin role, web.Config admin options are reset when
+// the test finishes
+func newAdminClient(t *testing.T) *client.Client {
+	web.Config.AdminAPIs = true
+	web.Config.AdminRole = []string{"admin"}
+	web.Config.AdminGroup = []string{"dbs"}
+	t.Cleanup(func() {
+		web.Config.AdminAPIs = false
+		web.Config.AdminRole = nil
+		web.Config.AdminGroup = nil
+	})
+	c := newTestClient(t, "DBSWriter")
+	c.HTTPClient.Transport = &headerTransport{
+		Header:    http.Header{"Cms-Authz-Admin": []string{"group:dbs"}},
+		Transport: c.HTTPClient.Transport,
+	}
+	return c
+}
+
 // helper function to initialize DB for tests
 func initDB(dryRun bool, dburi string) *sql.DB {
 	log.SetFlags(0)
@@ -61,6 +128,12 @@ func initDB(dryRun bool, dburi string) *sql.DB {
 	if dryRun {
 		dbs.DRYRUN = true
 	}
+	// remote DBS servers are accessed via DBS client without retries
+	dbs.RemoteClient = func(rurl string) dbs.RemoteDBS {
+		c := client.New(rurl)
+		c.Retries = 0
+		return c
+	}
 	// init validator
 	dbs.RecordValidator = validator.New()
 	dbs.FileLumiChunkSize = 1000
@@ -2,11 +2,22 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dmwm/dbs2go/dbs"
 	"github.com/dmwm/dbs2go/utils"
@@ -385,6 +396,719 @@ func TestMigrateRemove(t *testing.T) {
 	}
 }
 
+// TestMigrateLease tests lease based claiming of migration requests
+func TestMigrateLease(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// insert migration request
+	tstamp := time.Now().Unix()
+	rec := dbs.MigrationRequest{
+		MIGRATION_URL:          "http://localhost:8989/dbs2go",
+		MIGRATION_INPUT:        "/lease/test/RAW#123",
+		MIGRATION_STATUS:       dbs.PENDING,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rec.Insert(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	mid := rec.MIGRATION_REQUEST_ID
+
+	// first claim should succeed and second one should fail
+	lease, err := dbs.ClaimMigrationRequest(mid)
+	if err != nil {
+		t.Fatal("unable to claim migration request", err)
+	}
+	if _, err := dbs.ClaimMigrationRequest(mid); err == nil {
+		t.Fatal("migration request is claimed twice")
+	}
+	if err := lease.Renew(); err != nil {
+		t.Fatal("unable to renew migration lease", err)
+	}
+	records, err := dbs.MigrationRequests(mid)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("unable to fetch migration request %d, error %v", mid, err)
+	}
+	if records[0].MIGRATION_SERVER != dbs.MigrationOwner() || records[0].LEASE_EXPIRATION_DATE != lease.Expiration {
+		t.Fatalf("wrong lease of migration request %+v, expect %+v", records[0], lease)
+	}
+	// leased requests should not be picked up by migration server
+	records, err = dbs.MigrationRequests(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if r.MIGRATION_REQUEST_ID == mid {
+			t.Fatalf("leased migration request %d is selected for processing", mid)
+		}
+	}
+
+	// once lease is released the request can be claimed again
+	if err := lease.Release(); err != nil {
+		t.Fatal("unable to release migration lease", err)
+	}
+	// released request should be reported with its queue position
+	rr, err := respRecorder("GET", fmt.Sprintf("/dbs2go/status?migration_request_id=%d", mid), nil, web.MigrationStatusHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status []MigrationStatus
+	if err := json.Unmarshal(rr.Body.Bytes(), &status); err != nil {
+		t.Fatalf("unable to unmarshal status data '%s', error %v", rr.Body.String(), err)
+	}
+	if len(status) != 1 || status[0].QueuePosition != 1 {
+		t.Fatalf("wrong queue position of migration request %+v", status)
+	}
+	lease, err = dbs.ClaimMigrationRequest(mid)
+	if err != nil {
+		t.Fatal("unable to claim released migration request", err)
+	}
+
+	// expired lease should be reclaimed, but renewal by old owner should fail
+	_, err = db.Exec("UPDATE MIGRATION_REQUESTS SET LEASE_EXPIRATION_DATE=?, MIGRATION_SERVER=? WHERE MIGRATION_REQUEST_ID=?", tstamp-1, "dead-server:1", mid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Renew(); err == nil {
+		t.Fatal("lease of another migration server is renewed")
+	}
+	lease, err = dbs.ClaimMigrationRequest(mid)
+	if err != nil {
+		t.Fatal("unable to reclaim expired migration lease", err)
+	}
+	lease.Release()
+}
+
+// TestMigrateProgress tests per-block progress report of migration requests
+func TestMigrateProgress(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// insert migration request for a dataset and its two blocks, the source
+	// DBS is not accessible such that block migration will fail
+	tstamp := time.Now().Unix()
+	var mids []int64
+	for idx, input := range []string{"/progress/test/RAW", "/progress/test/RAW#1", "/progress/test/RAW#2"} {
+		rec := dbs.MigrationRequest{
+			MIGRATION_URL:          "http://localhost:1/dbs2go",
+			MIGRATION_INPUT:        input,
+			MIGRATION_STATUS:       dbs.PENDING,
+			CREATE_BY:              "tester",
+			CREATION_DATE:          tstamp,
+			LAST_MODIFIED_BY:       "tester",
+			LAST_MODIFICATION_DATE: tstamp,
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Insert(tx); err != nil {
+			t.Fatal(err)
+		}
+		mids = append(mids, rec.MIGRATION_REQUEST_ID)
+		blk := dbs.MigrationBlocks{
+			MIGRATION_REQUEST_ID:   rec.MIGRATION_REQUEST_ID,
+			MIGRATION_BLOCK_NAME:   input,
+			MIGRATION_ORDER:        int64(2 - idx),
+			MIGRATION_STATUS:       dbs.PENDING,
+			CREATE_BY:              "tester",
+			CREATION_DATE:          tstamp,
+			LAST_MODIFIED_BY:       "tester",
+			LAST_MODIFICATION_DATE: tstamp,
+			ORIGIN_REQUEST_ID:      mids[0],
+		}
+		if err := blk.Insert(tx); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// first block is completed
+	_, err := db.Exec("UPDATE MIGRATION_REQUESTS SET MIGRATION_STATUS=? WHERE MIGRATION_REQUEST_ID=?", dbs.COMPLETED, mids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// process second block which should fail
+	api := dbs.API{Api: "ProcessMigration"}
+	api.Params = dbs.Record{"migration_request_id": []string{fmt.Sprintf("%d", mids[2])}}
+	api.ProcessMigration()
+
+	rr, err := respRecorder("GET", fmt.Sprintf("/dbs2go/status?migration_request_id=%d&detail=true", mids[0]), nil, web.MigrationStatusHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []struct {
+		MigrationBlocks []dbs.MigrationBlockReport `json:"migration_blocks"`
+		Progress        dbs.MigrationProgress      `json:"progress"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+		t.Fatalf("unable to unmarshal status data '%s', error %v", rr.Body.String(), err)
+	}
+	if len(records) != 1 || len(records[0].MigrationBlocks) != 3 {
+		t.Fatalf("wrong migration status records %s", rr.Body.String())
+	}
+	progress := records[0].Progress
+	if progress.TotalBlocks != 3 || progress.CompletedBlocks != 1 || int(progress.Percentage) != 33 {
+		t.Errorf("wrong migration progress %+v", progress)
+	}
+	// blocks are ordered by migration order
+	blk := records[0].MigrationBlocks[0]
+	if blk.BlockName != "/progress/test/RAW#2" || blk.AttemptCount != 1 || blk.LastError == "" || blk.StartDate == 0 {
+		t.Errorf("wrong migration block report %+v", blk)
+	}
+
+	// remote DBS failure is transient, i.e. request will be retried later
+	if !strings.HasPrefix(blk.LastError, "transient failure") || blk.MigrationStatus != dbs.FAILED {
+		t.Errorf("wrong failure classification of migration block %+v", blk)
+	}
+	recs, err := dbs.MigrationRequests(mids[2])
+	if err != nil || len(recs) != 1 {
+		t.Fatal("unable to get migration request", err)
+	}
+	if recs[0].RETRY_COUNT != 1 || recs[0].NEXT_RETRY_DATE <= tstamp {
+		t.Errorf("wrong retry of migration request %+v", recs[0])
+	}
+}
+
+// TestMigrateRetry tests migration failure classification and retry backoff
+func TestMigrateRetry(t *testing.T) {
+	err := dbs.Error(errors.New("no parent"), dbs.FileParentDoesNotExist, "", "test")
+	if !dbs.IsPermanentMigrationError(err) {
+		t.Errorf("missing parent should be permanent failure %v", err)
+	}
+	// nested DBS error is classified by its error code
+	err = fmt.Errorf("migration failed: %v", err)
+	if !dbs.IsPermanentMigrationError(err) {
+		t.Errorf("nested missing parent should be permanent failure %v", err)
+	}
+	err = dbs.Error(errors.New("timeout"), dbs.HttpRequestErrorCode, "", "test")
+	if dbs.IsPermanentMigrationError(err) {
+		t.Errorf("HTTP error should be transient failure %v", err)
+	}
+
+	dbs.MigrationRetryBase = 10
+	dbs.MigrationRetryMax = 100
+	dbs.MigrationRetryJitter = 0
+	defer func() {
+		dbs.MigrationRetryBase = 0
+		dbs.MigrationRetryMax = 0
+	}()
+	for retry, expect := range []int64{10, 20, 40, 80, 100, 100} {
+		if delay := dbs.MigrationRetryDelay(int64(retry)); delay != expect {
+			t.Errorf("wrong delay %d of retry %d, expect %d", delay, retry, expect)
+		}
+	}
+	dbs.MigrationRetryJitter = 0.5
+	defer func() { dbs.MigrationRetryJitter = 0 }()
+	if delay := dbs.MigrationRetryDelay(1); delay < 20 || delay > 30 {
+		t.Errorf("wrong delay %d with jitter", delay)
+	}
+}
+
+// helper function to create gzipped migration bundle with given blocks and
+// block dump records
+func migrationBundle(t *testing.T, blocks []string, records ...[]byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	header := dbs.MigrationBundleHeader{
+		Version:        dbs.MigrationBundleVersion,
+		MigrationURL:   "http://localhost:1/dbs2go",
+		MigrationInput: blocks[len(blocks)-1],
+		Blocks:         blocks,
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.Write(append(data, '\n'))
+	for _, rec := range records {
+		var out bytes.Buffer
+		if err := json.Compact(&out, rec); err != nil {
+			t.Fatal(err)
+		}
+		gw.Write(append(out.Bytes(), '\n'))
+	}
+	gw.Close()
+	return buf.Bytes()
+}
+
+// TestMigrateBundle tests import of offline migration bundle
+func TestMigrateBundle(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	data, err := os.ReadFile("data/bulkblocks1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := "/unittest_web_primary_ds_name_207/acq_era_207-v207/GEN-SIM-RAW#207"
+
+	// bundle records should follow order of bundle header
+	api := dbs.API{Writer: utils.StdoutWriter(""), CreateBy: "tester"}
+	api.Reader = bytes.NewReader(migrationBundle(t, []string{"/a/b/RAW#1", block}, data))
+	if err := api.ImportMigrationBundle(); err == nil {
+		t.Error("bundle with wrong order of blocks should fail")
+	}
+
+	// first import inserts the block and second one skips it
+	for _, status := range []string{"COMPLETED", "EXIST_IN_DB"} {
+		rr := httptest.NewRecorder()
+		api := dbs.API{Writer: rr, CreateBy: "tester", Reader: bytes.NewReader(migrationBundle(t, []string{block}, data))}
+		if err := api.ImportMigrationBundle(); err != nil {
+			t.Fatal("unable to import migration bundle", err)
+		}
+		var reports []dbs.MigrationBundleReport
+		if err := json.Unmarshal(rr.Body.Bytes(), &reports); err != nil {
+			t.Fatalf("unable to unmarshal bundle report '%s', error %v", rr.Body.String(), err)
+		}
+		if len(reports) != 1 || reports[0].Block != block || reports[0].Status != status {
+			t.Errorf("wrong bundle report %+v, expect status %s", reports, status)
+		}
+	}
+}
+
+// TestMigrateExportBundle tests export of migration bundle from remote DBS server
+func TestMigrateExportBundle(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	block := bulk.Block.BlockName
+	remote := dbs.RemoteClient
+	defer func() { dbs.RemoteClient = remote }()
+
+	// remote DBS server which may fail blockdump requests
+	var fail bool
+	target, err := url.Parse(c.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail && strings.HasSuffix(r.URL.Path, "/blockdump") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+
+	// bundle holds header and block dump of exported block
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "bundle.gz")
+	if err := web.ExportBundle(upstream.URL, block, fname); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := json.NewDecoder(gr)
+	var header dbs.MigrationBundleHeader
+	if err := decoder.Decode(&header); err != nil {
+		t.Fatal(err)
+	}
+	if len(header.Blocks) != 1 || header.Blocks[0] != block {
+		t.Errorf("wrong bundle header %+v", header)
+	}
+	var brec dbs.BulkBlocks
+	if err := decoder.Decode(&brec); err != nil {
+		t.Fatal(err)
+	}
+	if brec.Block.BlockName != block || len(brec.Files) != len(bulk.Files) {
+		t.Errorf("wrong bundle block dump %+v", brec.Block)
+	}
+
+	// failed export keeps existing bundle and leaves no temporary files
+	fail = true
+	if err := web.ExportBundle(upstream.URL, block, fname); err == nil {
+		t.Fatal("no error for failed blockdump request")
+	}
+	out, err := os.ReadFile(fname)
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("existing bundle is modified by failed export, error %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("wrong files %v after failed export, error %v", entries, err)
+	}
+}
+
+// TestMigrateEvents tests notifications about migration status changes
+func TestMigrateEvents(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// webhook which verifies signature of migration events and callback
+	// which should receive unsigned migration events
+	secret := "secret"
+	events := make(chan dbs.MigrationEvent, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		sig := r.Header.Get(dbs.MigrationSignatureHeader)
+		if r.URL.Path == "/webhook" && sig != "sha256="+dbs.MigrationEventSignature(data, secret) {
+			t.Errorf("wrong signature %s of migration event %s", sig, string(data))
+		} else if r.URL.Path != "/webhook" && sig != "" {
+			t.Errorf("callback %s receives signed migration event", r.URL.Path)
+		}
+		var event dbs.MigrationEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Error(err)
+		}
+		events <- event
+	}))
+	defer server.Close()
+
+	fname := fmt.Sprintf("%s/events.ndjson", t.TempDir())
+	dbs.MigrationEventsFile = fname
+	dbs.MigrationWebhooks = []string{server.URL + "/webhook"}
+	dbs.MigrationWebhookSecret = secret
+	dbs.MigrationWebhookRetries = 0
+	dbs.MigrationCallbackURLs = []string{server.URL + "/callbacks"}
+	defer func() {
+		dbs.MigrationEventsFile = ""
+		dbs.MigrationWebhooks = nil
+		dbs.MigrationWebhookSecret = ""
+		dbs.MigrationCallbackURLs = nil
+	}()
+
+	// callback urls which do not match allowed callback urls are rejected at
+	// submission time
+	for _, callback := range []string{
+		"ftp://host/path",
+		"http://localhost:1/callbacks",
+		server.URL + "/webhook",
+		server.URL + "/callbacksfoo",
+		strings.Replace(server.URL, "http://", "http://user@", 1) + "/callbacks",
+	} {
+		api := dbs.API{Writer: utils.StdoutWriter(""), CreateBy: "tester"}
+		data := fmt.Sprintf(`{"migration_url":"http://localhost:1/dbs2go","migration_input":"/a/b/RAW#1","callback_url":"%s"}`, callback)
+		api.Reader = bytes.NewReader([]byte(data))
+		if err := api.SubmitMigration(); err == nil || !strings.Contains(err.Error(), "invalid callback url") {
+			t.Errorf("migration request with callback url %s should fail, error %v", callback, err)
+		}
+	}
+
+	// insert migration request with callback url, the source DBS is not
+	// accessible such that migration will fail
+	tstamp := time.Now().Unix()
+	input := "/events/test/RAW#1"
+	rec := dbs.MigrationRequest{
+		MIGRATION_URL:          "http://localhost:1/dbs2go",
+		MIGRATION_INPUT:        input,
+		MIGRATION_STATUS:       dbs.PENDING,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+		CALLBACK_URL:           server.URL + "/callbacks/1",
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	blk := dbs.MigrationBlocks{
+		MIGRATION_REQUEST_ID:   rec.MIGRATION_REQUEST_ID,
+		MIGRATION_BLOCK_NAME:   input,
+		MIGRATION_STATUS:       dbs.PENDING,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+	}
+	if err := blk.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	api := dbs.API{Api: "ProcessMigration"}
+	api.Params = dbs.Record{"migration_request_id": []string{fmt.Sprintf("%d", rec.MIGRATION_REQUEST_ID)}}
+	api.ProcessMigration()
+
+	// both file sink and webhook receive the same sequence of events
+	expect := []string{"PENDING->IN_PROGRESS", "IN_PROGRESS->FAILED"}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event dbs.MigrationEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.MigrationRequestID != rec.MIGRATION_REQUEST_ID {
+			t.Errorf("wrong migration event %+v", event)
+		}
+		changes = append(changes, event.PreviousStatus+"->"+event.Status)
+	}
+	if strings.Join(changes, ",") != strings.Join(expect, ",") {
+		t.Errorf("wrong migration events %v, expect %v", changes, expect)
+	}
+	received := make(map[string]int)
+	for i := 0; i < 2*len(expect); i++ {
+		select {
+		case event := <-events:
+			received[event.PreviousStatus+"->"+event.Status]++
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook did not receive migration event")
+		}
+	}
+	for _, change := range expect {
+		if received[change] != 2 {
+			t.Errorf("webhook and callback did not receive %s event", change)
+		}
+	}
+}
+
+// TestMigratePlan tests migration plan of migration request
+func TestMigratePlan(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+	if fname := os.Getenv("DBS_LEXICON_FILE"); fname != "" {
+		lexPatterns, err := dbs.LoadPatterns(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dbs.LexiconPatterns = lexPatterns
+	}
+	// local blocks are looked up in DB
+	localhost := utils.Localhost
+	utils.Localhost = "http://localhost:9898"
+	defer func() { utils.Localhost = localhost }()
+
+	// remote DBS with a block and its parent which violates lexicon
+	block := "/prim/proc-v1/AOD#1"
+	parent := "/prim/proc-v1/raw#2"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blockparents":
+			if r.URL.Query().Get("block_name") == block {
+				w.Write([]byte(fmt.Sprintf(`[{"parent_block_name":"%s"}]`, parent)))
+				return
+			}
+			w.Write([]byte("[]"))
+		case "/filesummaries":
+			w.Write([]byte(`[{"num_file":2,"num_lumi":3,"file_size":100}]`))
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer server.Close()
+
+	data := fmt.Sprintf(`{"migration_url":"%s","migration_input":"%s"}`, server.URL, block)
+	rr := httptest.NewRecorder()
+	api := dbs.API{Writer: rr, Reader: bytes.NewReader([]byte(data)), CreateBy: "tester"}
+	if err := api.PlanMigration(); err != nil {
+		t.Fatal("unable to plan migration", err)
+	}
+	var plans []dbs.MigrationPlan
+	if err := json.Unmarshal(rr.Body.Bytes(), &plans); err != nil {
+		t.Fatalf("unable to unmarshal migration plan '%s', error %v", rr.Body.String(), err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("wrong migration plan %s", rr.Body.String())
+	}
+	plan := plans[0]
+	// parents are migrated first
+	var blocks []string
+	for _, b := range plan.Blocks {
+		blocks = append(blocks, b.BlockName)
+	}
+	if strings.Join(blocks, ",") != parent+","+block || len(plan.ExistingBlocks) != 0 {
+		t.Errorf("wrong blocks of migration plan %+v", plan)
+	}
+	if plan.NumBlock != 2 || plan.NumFile != 4 || plan.NumLumi != 6 || plan.FileSize != 200 {
+		t.Errorf("wrong estimates of migration plan %+v", plan)
+	}
+	if len(plan.LexiconConflicts) != 1 || !strings.HasPrefix(plan.LexiconConflicts[0], parent) {
+		t.Errorf("wrong lexicon conflicts of migration plan %+v", plan.LexiconConflicts)
+	}
+
+	// migration plan does not create migration requests, other tests may
+	// leave their requests in shared DB, therefore we only look at ours
+	records, err := dbs.MigrationRequests(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if r.MIGRATION_URL == server.URL && r.MIGRATION_INPUT == block {
+			t.Errorf("migration plan should not create migration request %+v", r)
+		}
+	}
+}
+
+// TestMigratePlanExisting tests that parent blocks which exist in local DBS
+// are reported by migration plan and skipped by live migration
+func TestMigratePlanExisting(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	localhost := utils.Localhost
+	utils.Localhost = "http://localhost:9898"
+	defer func() { utils.Localhost = localhost }()
+	timeout := dbs.MigrationAsyncTimeout
+	dbs.MigrationAsyncTimeout = 10
+	defer func() { dbs.MigrationAsyncTimeout = timeout }()
+
+	// remote DBS with a block whose parent exists in local DBS
+	block := "/prim/proc-v1/AOD#1"
+	parent := bulk.Block.BlockName
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blockparents":
+			if r.URL.Query().Get("block_name") == block {
+				w.Write([]byte(fmt.Sprintf(`[{"parent_block_name":"%s"}]`, parent)))
+				return
+			}
+			w.Write([]byte("[]"))
+		case "/filesummaries":
+			w.Write([]byte(`[{"num_file":2,"num_lumi":3,"file_size":100}]`))
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer server.Close()
+
+	// migration plan reports existing parent block
+	data := fmt.Sprintf(`{"migration_url":"%s","migration_input":"%s"}`, server.URL, block)
+	rr := httptest.NewRecorder()
+	api := dbs.API{Writer: rr, Reader: bytes.NewReader([]byte(data)), CreateBy: "tester"}
+	if err := api.PlanMigration(); err != nil {
+		t.Fatal("unable to plan migration", err)
+	}
+	var plans []dbs.MigrationPlan
+	if err := json.Unmarshal(rr.Body.Bytes(), &plans); err != nil || len(plans) != 1 {
+		t.Fatalf("wrong migration plan '%s', error %v", rr.Body.String(), err)
+	}
+	plan := plans[0]
+	if len(plan.Blocks) != 1 || plan.Blocks[0].BlockName != block {
+		t.Errorf("wrong blocks of migration plan %+v", plan.Blocks)
+	}
+	if len(plan.ExistingBlocks) != 1 || plan.ExistingBlocks[0] != parent {
+		t.Errorf("wrong existing blocks of migration plan %+v", plan.ExistingBlocks)
+	}
+
+	// live migration request does not migrate existing parent block
+	tstamp := time.Now().Unix()
+	rec := dbs.MigrationRequest{
+		MIGRATION_URL:          server.URL,
+		MIGRATION_INPUT:        block,
+		MIGRATION_STATUS:       dbs.QUEUED,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+	}
+	tx, err := dbs.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	dbs.StartMigrationRequest(rec)
+	rows, err := dbs.DB.Query("SELECT MIGRATION_BLOCK_NAME FROM MIGRATION_BLOCKS WHERE ORIGIN_REQUEST_ID=?", rec.MIGRATION_REQUEST_ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var blocks []string
+	for rows.Next() {
+		var blk string
+		if err := rows.Scan(&blk); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, blk)
+	}
+	if len(blocks) != 1 || blocks[0] != block {
+		t.Errorf("wrong migration blocks %v, existing parent %s should be skipped", blocks, parent)
+	}
+}
+
+// TestMigrateSchedule tests fair-share ordering of migration requests
+func TestMigrateSchedule(t *testing.T) {
+	var records []dbs.MigrationRequest
+	// user1 submits many requests before user2 and user3
+	for i := 1; i <= 4; i++ {
+		rec := dbs.MigrationRequest{MIGRATION_REQUEST_ID: int64(i), CREATE_BY: "user1", CREATION_DATE: int64(i)}
+		records = append(records, rec)
+	}
+	records = append(records, dbs.MigrationRequest{MIGRATION_REQUEST_ID: 5, CREATE_BY: "user2", CREATION_DATE: 5})
+	records = append(records, dbs.MigrationRequest{MIGRATION_REQUEST_ID: 6, CREATE_BY: "user3", CREATION_DATE: 6, PRIORITY: 5})
+	records = append(records, dbs.MigrationRequest{MIGRATION_REQUEST_ID: 7, CREATE_BY: "user1", CREATION_DATE: 7, PRIORITY: 1})
+
+	var ids []int64
+	for _, r := range dbs.ScheduleMigrationRequests(records, nil, 0) {
+		ids = append(ids, r.MIGRATION_REQUEST_ID)
+	}
+	expect := []int64{6, 7, 5, 1, 2, 3, 4}
+	if fmt.Sprintf("%v", ids) != fmt.Sprintf("%v", expect) {
+		t.Errorf("wrong order of migration requests %v, expect %v", ids, expect)
+	}
+
+	// user1 has one active request and its concurrency is limited to 2
+	active := map[string]int{"user1": 1}
+	ids = []int64{}
+	for _, r := range dbs.ScheduleMigrationRequests(records, active, 2) {
+		ids = append(ids, r.MIGRATION_REQUEST_ID)
+	}
+	expect = []int64{6, 7, 5}
+	if fmt.Sprintf("%v", ids) != fmt.Sprintf("%v", expect) {
+		t.Errorf("wrong order of migration requests %v, expect %v", ids, expect)
+	}
+}
+
 // MigrationRequest is the struct for migration request POST body
 type MigrationRequest struct {
 	MigrationURL   string `json:"migration_url"`
@@ -402,4 +1126,228 @@ type MigrationStatus struct {
 	MigrationStatus      int    `json:"migration_status"`
 	MigrationURL         string `json:"migration_url"`
 	RetryCount           int    `json:"retry_count"`
+	Priority             int    `json:"priority"`
+	QueuePosition        int    `json:"queue_position"`
+}
+
+// TestMigrateDrain tests server drain mode and interruption of in-flight
+// migration requests
+func TestMigrateDrain(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// insert migration request which is in progress along with its block
+	tstamp := time.Now().Unix()
+	rec := dbs.MigrationRequest{
+		MIGRATION_URL:          "http://localhost:1/dbs2go",
+		MIGRATION_INPUT:        "/drain/test/RAW#1",
+		MIGRATION_STATUS:       dbs.IN_PROGRESS,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+		RETRY_COUNT:            1,
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	mid := rec.MIGRATION_REQUEST_ID
+	blk := dbs.MigrationBlocks{
+		MIGRATION_REQUEST_ID:   mid,
+		MIGRATION_BLOCK_NAME:   rec.MIGRATION_INPUT,
+		MIGRATION_ORDER:        0,
+		MIGRATION_STATUS:       dbs.IN_PROGRESS,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+		ORIGIN_REQUEST_ID:      mid,
+	}
+	if err := blk.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbs.ClaimMigrationRequest(mid); err != nil {
+		t.Fatal("unable to claim migration request", err)
+	}
+
+	// register migration request as in-flight work and start drain
+	done, err := dbs.TrackMigration(rec)
+	if err != nil {
+		t.Fatal("unable to track migration request", err)
+	}
+	defer done()
+	dbs.StartDrain()
+	defer dbs.StopDrain()
+	if _, err := dbs.TrackWork(); err == nil {
+		t.Fatal("new work is accepted while server is draining")
+	}
+
+	// new writes should be rejected while server is draining
+	data := []byte(`{"migration_url":"http://localhost:1/dbs2go","migration_input":"/drain/test/RAW#2"}`)
+	_, err = respRecorder("POST", "/dbs2go/submit", bytes.NewBuffer(data), web.MigrationSubmitHandler)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%d", http.StatusServiceUnavailable)) {
+		t.Fatalf("migration request is accepted while server is draining, error %v", err)
+	}
+
+	// read-only POST APIs are served while server is draining
+	data = []byte(`{"dataset":["/drain/test/RAW"]}`)
+	if _, err := respRecorder("POST", "/dbs2go/datasetlist", bytes.NewBuffer(data), web.DatasetListHandler); err != nil {
+		t.Fatalf("read-only request is rejected while server is draining, error %v", err)
+	}
+
+	// drain state should be reported by health status
+	rr, err := respRecorder("GET", "/dbs2go/healthz", nil, web.StatusHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health []struct {
+		Draining bool  `json:"draining"`
+		InFlight int64 `json:"in_flight"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if len(health) != 1 || !health[0].Draining || health[0].InFlight != 1 {
+		t.Fatalf("wrong health status %+v", health)
+	}
+
+	// in-flight migration request does not finish within drain timeout
+	if dbs.WaitForDrain(100 * time.Millisecond) {
+		t.Fatal("drain is finished while migration request is in progress")
+	}
+	if err := dbs.InterruptMigrationRequests(); err != nil {
+		t.Fatal("unable to interrupt migration requests", err)
+	}
+	var status, retries int64
+	var leaseDate sql.NullInt64
+	err = db.QueryRow("SELECT MIGRATION_STATUS, RETRY_COUNT, LEASE_EXPIRATION_DATE FROM MIGRATION_REQUESTS WHERE MIGRATION_REQUEST_ID=?", mid).Scan(&status, &retries, &leaseDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != dbs.PENDING || retries != 1 || leaseDate.Valid {
+		t.Fatalf("wrong interrupted migration request status=%d retries=%d lease=%v", status, retries, leaseDate)
+	}
+	var lastError string
+	err = db.QueryRow("SELECT MIGRATION_STATUS, LAST_ERROR FROM MIGRATION_BLOCKS WHERE MIGRATION_REQUEST_ID=?", mid).Scan(&status, &lastError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != dbs.PENDING || !strings.Contains(lastError, "interrupted") {
+		t.Fatalf("wrong interrupted migration block status=%d error=%s", status, lastError)
+	}
+
+	// drain is finished once in-flight work is done
+	done()
+	if !dbs.WaitForDrain(time.Second) || dbs.InFlight() != 0 {
+		t.Fatalf("drain is not finished, %d in-flight requests", dbs.InFlight())
+	}
+}
+
+// TestMigrateDrainDaemon tests drain of migration request processed by
+// migration daemon
+func TestMigrateDrainDaemon(t *testing.T) {
+	// initialize DB for testing
+	dburi := os.Getenv("DBS_DB_FILE")
+	if dburi == "" {
+		log.Fatal("DBS_DB_FILE not defined")
+	}
+	db := initDB(false, dburi)
+	defer db.Close()
+
+	// source DBS server holds blockdump request until it is released
+	hit := make(chan bool, 1)
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case hit <- true:
+		default:
+		}
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// insert pending migration request along with its block
+	tstamp := time.Now().Unix()
+	rec := dbs.MigrationRequest{
+		MIGRATION_URL:          server.URL,
+		MIGRATION_INPUT:        "/daemon/test/RAW#1",
+		MIGRATION_STATUS:       dbs.PENDING,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	mid := rec.MIGRATION_REQUEST_ID
+	blk := dbs.MigrationBlocks{
+		MIGRATION_REQUEST_ID:   mid,
+		MIGRATION_BLOCK_NAME:   rec.MIGRATION_INPUT,
+		MIGRATION_ORDER:        0,
+		MIGRATION_STATUS:       dbs.PENDING,
+		CREATE_BY:              "tester",
+		CREATION_DATE:          tstamp,
+		LAST_MODIFIED_BY:       "tester",
+		LAST_MODIFICATION_DATE: tstamp,
+		ORIGIN_REQUEST_ID:      mid,
+	}
+	if err := blk.Insert(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// start migration daemon and wait until it processes our request
+	stop := make(chan bool, 1)
+	go dbs.MigrationServer(0, 10, stop)
+	select {
+	case <-hit:
+	case <-time.After(10 * time.Second):
+		close(release)
+		t.Fatal("migration daemon does not process migration request")
+	}
+
+	// stop notification does not wait for migration to finish while drain
+	// sees migration request as in-flight work
+	dbs.StartDrain()
+	defer dbs.StopDrain()
+	stop <- true
+	if dbs.InFlight() != 1 || dbs.WaitForDrain(100*time.Millisecond) {
+		close(release)
+		t.Fatalf("daemon migration is not tracked, %d in-flight requests", dbs.InFlight())
+	}
+	if err := dbs.InterruptMigrationRequests(); err != nil {
+		close(release)
+		t.Fatal("unable to interrupt migration requests", err)
+	}
+	var status int64
+	err = db.QueryRow("SELECT MIGRATION_STATUS FROM MIGRATION_REQUESTS WHERE MIGRATION_REQUEST_ID=?", mid).Scan(&status)
+	if err != nil || status != dbs.PENDING {
+		t.Errorf("wrong interrupted migration request status=%d error=%v", status, err)
+	}
+
+	// drain is finished once daemon migration is done
+	close(release)
+	if !dbs.WaitForDrain(10 * time.Second) {
+		t.Fatalf("drain is not finished, %d in-flight requests", dbs.InFlight())
+	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestDBSParentageAudit tests that parentage audit of dataset does not
+// pick up block parents of datasets with similar names
+func TestDBSParentageAudit(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+
+	// inject parent dataset and its child dataset with block parents
+	parent := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := loadBulkBlocks(t, "data/bulkblocks0.json")
+	child.DatasetParentList = []string{parent.Dataset.Dataset}
+	child.FileParentList = []dbs.FileParentRecord{{
+		ThisLogicalFileName:   child.Files[0].LogicalFileName,
+		ParentLogicalFileName: parent.Files[0].LogicalFileName,
+	}}
+	if err := c.InsertBulkBlocks(child); err != nil {
+		t.Fatal(err)
+	}
+
+	// inject dataset whose name matches child dataset name as LIKE pattern,
+	// i.e. its underscore matches dash of child dataset name
+	other := loadBulkBlocks(t, "data/bulkblocks0.json")
+	other.FileParentList = nil
+	other.Dataset.ProcessedDSName = "Summer2011_pstr-v10"
+	other.Dataset.Dataset = strings.Replace(child.Dataset.Dataset, "2011-pstr", "2011_pstr", 1)
+	other.Block.BlockName = other.Dataset.Dataset + "#141445"
+	for i := range other.Files {
+		other.Files[i].LogicalFileName = strings.Replace(other.Files[i].LogicalFileName, "/a/1/", "/a/2/", 1)
+	}
+	for i := range other.FileConfigList {
+		other.FileConfigList[i].LFN = strings.Replace(other.FileConfigList[i].LFN, "/a/1/", "/a/2/", 1)
+	}
+	if err := c.InsertBulkBlocks(other); err != nil {
+		t.Fatal(err)
+	}
+
+	// child dataset has recorded block parents
+	records, err := c.ParentageAudit(url.Values{"dataset": []string{child.Dataset.Dataset}})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("wrong parentage audit %v, error %v", records, err)
+	}
+
+	// the other dataset has no block parents
+	records, err = c.ParentageAudit(url.Values{"dataset": []string{other.Dataset.Dataset}})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("wrong parentage audit %v, error %v", records, err)
+	}
+	if findings, ok := records[0]["findings"].([]interface{}); !ok || len(findings) != 0 {
+		t.Errorf("wrong parentage audit findings of %s: %v", other.Dataset.Dataset, records[0])
+	}
+}
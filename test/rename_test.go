@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestRenameDataset tests dataset rename and resolution of its old name
+func TestRenameDataset(t *testing.T) {
+	c := newAdminClient(t)
+
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	oldDataset := bulk.Dataset.Dataset
+	oldBlock := bulk.Block.BlockName
+	newDataset := "/unittest_web_primary_ds_name_207/acq_era_208-v208/GEN-SIM-RAW"
+	newBlock := newDataset + "#207"
+
+	// dataset can only change its processed dataset name
+	rec := dbs.RenameRecord{Dataset: oldDataset, NewDataset: "/unittest_web_primary_ds_name_207/acq_era_208-v208/RAW"}
+	if _, err := c.RenameDataset(rec); err == nil {
+		t.Error("no error for rename of data tier")
+	}
+
+	rec = dbs.RenameRecord{Dataset: oldDataset, NewDataset: newDataset, Reason: "wrong processing version"}
+	report, err := c.RenameDataset(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Blocks) != 1 || report.Blocks[oldBlock] != newBlock {
+		t.Errorf("wrong rename report %+v", report)
+	}
+
+	// renamed dataset has new processed dataset, acquisition and processing eras
+	var datasets []dbs.Record
+	params := url.Values{"dataset": []string{newDataset}, "dataset_access_type": []string{"*"}, "detail": []string{"true"}}
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 {
+		t.Fatalf("renamed dataset is not found %v, error %v", datasets, err)
+	}
+	ds := datasets[0]
+	if ds["processed_ds_name"] != "acq_era_208-v208" || ds["acquisition_era_name"] != "acq_era_208" || ds["processing_version"] != float64(208) {
+		t.Errorf("wrong renamed dataset %+v", ds)
+	}
+
+	// old block name is resolved with redirect hint
+	resp, err := c.HTTPClient.Get(c.URL + "/blocks?block_name=" + url.QueryEscape(oldBlock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks []dbs.Record
+	err = json.NewDecoder(resp.Body).Decode(&blocks)
+	resp.Body.Close()
+	if err != nil || len(blocks) != 1 || blocks[0]["block_name"] != newBlock {
+		t.Errorf("old block name is not resolved %v, error %v", blocks, err)
+	}
+	if hint := resp.Header.Get("X-Dbs-Renamed"); hint != oldBlock+" -> "+newBlock {
+		t.Errorf("wrong redirect hint '%s'", hint)
+	}
+	var files []dbs.Record
+	if err := c.Get("files", url.Values{"dataset": []string{oldDataset}}, &files); err != nil || len(files) != 12 {
+		t.Errorf("wrong files of old dataset name %d, error %v", len(files), err)
+	}
+
+	// rename to existing dataset is not allowed
+	if _, err := c.RenameDataset(dbs.RenameRecord{Dataset: newDataset, NewDataset: newDataset}); err == nil {
+		t.Error("no error for rename to existing dataset")
+	}
+
+	// dataset can be renamed back to its old name
+	if _, err := c.RenameDataset(dbs.RenameRecord{Dataset: newDataset, NewDataset: oldDataset}); err != nil {
+		t.Fatal(err)
+	}
+	renames, err := c.DatasetRenames(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renames) != 1 || renames[0].OLD_DATASET != newDataset || renames[0].NEW_DATASET != oldDataset {
+		t.Errorf("wrong dataset renames %+v", renames)
+	}
+	blocks = nil
+	if err := c.Get("blocks", url.Values{"block_name": []string{newBlock}}, &blocks); err != nil || len(blocks) != 1 || blocks[0]["block_name"] != oldBlock {
+		t.Errorf("wrong blocks of renamed back dataset %v, error %v", blocks, err)
+	}
+}
+
+// TestRenameReinject tests injection of new dataset with old name of renamed
+// dataset and hard deletion of renamed dataset
+func TestRenameReinject(t *testing.T) {
+	c := newAdminClient(t)
+
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	oldDataset := bulk.Dataset.Dataset
+	newDataset := "/unittest_web_primary_ds_name_207/acq_era_208-v208/GEN-SIM-RAW"
+	rec := dbs.RenameRecord{Dataset: oldDataset, NewDataset: newDataset}
+	if _, err := c.RenameDataset(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// old name still resolves to renamed dataset
+	var datasets []dbs.Record
+	params := url.Values{"dataset": []string{oldDataset}, "dataset_access_type": []string{"*"}}
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 || datasets[0]["dataset"] != newDataset {
+		t.Fatalf("old dataset name is not resolved %v, error %v", datasets, err)
+	}
+
+	// re-inject dataset under its old name with new block and files
+	bulk = loadBulkBlocks(t, "data/bulkblocks1.json")
+	bulk.Block.BlockName = oldDataset + "#307"
+	for i := range bulk.Files {
+		bulk.Files[i].LogicalFileName = strings.Replace(bulk.Files[i].LogicalFileName, "/207/", "/307/", 1)
+	}
+	for i := range bulk.FileConfigList {
+		bulk.FileConfigList[i].LFN = strings.Replace(bulk.FileConfigList[i].LFN, "/207/", "/307/", 1)
+	}
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+
+	// re-injected dataset is reachable by its name without redirect
+	resp, err := c.HTTPClient.Get(c.URL + "/blocks?dataset=" + url.QueryEscape(oldDataset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks []dbs.Record
+	err = json.NewDecoder(resp.Body).Decode(&blocks)
+	resp.Body.Close()
+	if err != nil || len(blocks) != 1 || blocks[0]["block_name"] != oldDataset+"#307" {
+		t.Errorf("wrong blocks of re-injected dataset %v, error %v", blocks, err)
+	}
+	if hint := resp.Header.Get("X-Dbs-Renamed"); hint != "" {
+		t.Errorf("re-injected dataset is redirected '%s'", hint)
+	}
+	datasets = nil
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 || datasets[0]["dataset"] != oldDataset {
+		t.Errorf("re-injected dataset is not found %v, error %v", datasets, err)
+	}
+
+	// hard deletion of renamed dataset removes its renames, such that old
+	// name is no longer resolved once re-injected dataset is deleted as well
+	report, err := c.Delete(dbs.DeleteRecord{Dataset: newDataset, Mode: dbs.HardDelete})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows["DATASET_RENAMES"] != 1 {
+		t.Errorf("dataset renames are not deleted %+v", report)
+	}
+	renames, err := c.DatasetRenames(nil)
+	if err != nil || len(renames) != 0 {
+		t.Errorf("wrong dataset renames after deletion %+v, error %v", renames, err)
+	}
+	if _, err := c.Delete(dbs.DeleteRecord{Dataset: oldDataset, Mode: dbs.HardDelete}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = c.HTTPClient.Get(c.URL + "/datasets?dataset_access_type=*&dataset=" + url.QueryEscape(oldDataset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasets = nil
+	err = json.NewDecoder(resp.Body).Decode(&datasets)
+	resp.Body.Close()
+	if err != nil || len(datasets) != 0 {
+		t.Errorf("deleted dataset is found %v, error %v", datasets, err)
+	}
+	if hint := resp.Header.Get("X-Dbs-Renamed"); hint != "" {
+		t.Errorf("old name of deleted dataset is redirected '%s'", hint)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// TestTags tests tags of datasets and blocks
+func TestTags(t *testing.T) {
+	c := newTestClient(t, "DBSWriter")
+
+	bulk := loadBulkBlocks(t, "data/bulkblocks1.json")
+	if err := c.InsertBulkBlocks(bulk); err != nil {
+		t.Fatal(err)
+	}
+	dataset := bulk.Dataset.Dataset
+	block := bulk.Block.BlockName
+
+	// set tags of dataset and block
+	rec := dbs.TagRecord{Dataset: dataset, Tags: map[string]string{"campaign": "Run3Summer22", "do_not_delete": "true"}}
+	out, err := c.UpdateTags(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Tags) != 2 || out.Tags["campaign"] != "Run3Summer22" {
+		t.Errorf("wrong dataset tags %+v", out)
+	}
+	rec = dbs.TagRecord{BlockName: block, Tags: map[string]string{"campaign": "Run3Summer22"}}
+	if _, err := c.UpdateTags(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid tag records are rejected
+	for _, rec := range []dbs.TagRecord{
+		{Tags: map[string]string{"campaign": "bla"}},
+		{Dataset: dataset},
+		{Dataset: dataset, Tags: map[string]string{"1campaign": "bla"}},
+		{Dataset: dataset, Tags: map[string]string{"campaign": "bla"}, Remove: []string{"campaign"}},
+		{Dataset: "/a/b/RAW", Tags: map[string]string{"campaign": "bla"}},
+	} {
+		if _, err := c.UpdateTags(rec); err == nil {
+			t.Errorf("no error for tag record %+v", rec)
+		}
+	}
+
+	// datasets and blocks are selected by tags
+	tests := []struct {
+		api    string
+		tags   []string
+		expect int
+	}{
+		{"datasets", []string{"campaign"}, 1},
+		{"datasets", []string{"campaign=Run3*"}, 1},
+		{"datasets", []string{"campaign=Run2*"}, 0},
+		{"datasets", []string{"campaign=Run3Summer22", "do_not_delete=true"}, 1},
+		{"datasets", []string{"campaign", "bla"}, 0},
+		{"blocks", []string{"campaign=Run3Summer22"}, 1},
+		{"blocks", []string{"do_not_delete"}, 0},
+	}
+	for _, tt := range tests {
+		params := url.Values{"tag": tt.tags, "dataset_access_type": []string{"*"}}
+		if tt.api == "blocks" {
+			params = url.Values{"tag": tt.tags}
+		}
+		var records []dbs.Record
+		if err := c.Get(tt.api, params, &records); err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != tt.expect {
+			t.Errorf("wrong number of %s for tags %v, %d instead of %d", tt.api, tt.tags, len(records), tt.expect)
+		}
+	}
+
+	// detailed output contains tags
+	var datasets []dbs.Record
+	params := url.Values{"dataset": []string{dataset}, "dataset_access_type": []string{"*"}, "detail": []string{"true"}}
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 {
+		t.Fatalf("wrong datasets %v, error %v", datasets, err)
+	}
+	tags, ok := datasets[0]["tags"].(map[string]interface{})
+	if !ok || len(tags) != 2 || tags["do_not_delete"] != "true" {
+		t.Errorf("wrong tags of detailed dataset %+v", datasets[0])
+	}
+	var blocks []dbs.Record
+	params = url.Values{"block_name": []string{block}, "detail": []string{"true"}}
+	if err := c.Get("blocks", params, &blocks); err != nil || len(blocks) != 1 {
+		t.Fatalf("wrong blocks %v, error %v", blocks, err)
+	}
+	tags, ok = blocks[0]["tags"].(map[string]interface{})
+	if !ok || len(tags) != 1 || tags["campaign"] != "Run3Summer22" {
+		t.Errorf("wrong tags of detailed block %+v", blocks[0])
+	}
+
+	// tags can be requested via fields parameter
+	params = url.Values{"dataset": []string{dataset}, "dataset_access_type": []string{"*"}, "fields": []string{"dataset,tags"}}
+	datasets = nil
+	if err := c.Get("datasets", params, &datasets); err != nil || len(datasets) != 1 {
+		t.Fatalf("wrong datasets %v, error %v", datasets, err)
+	}
+	tags, ok = datasets[0]["tags"].(map[string]interface{})
+	if !ok || len(datasets[0]) != 2 || datasets[0]["dataset"] != dataset || len(tags) != 2 {
+		t.Errorf("wrong dataset with tags field %+v", datasets[0])
+	}
+	params = url.Values{"block_name": []string{block}, "fields": []string{"tags"}}
+	blocks = nil
+	if err := c.Get("blocks", params, &blocks); err != nil || len(blocks) != 1 {
+		t.Fatalf("wrong blocks %v, error %v", blocks, err)
+	}
+	tags, ok = blocks[0]["tags"].(map[string]interface{})
+	if !ok || len(blocks[0]) != 1 || tags["campaign"] != "Run3Summer22" {
+		t.Errorf("wrong block with tags field %+v", blocks[0])
+	}
+
+	// tags are replaced and removed
+	rec = dbs.TagRecord{Dataset: dataset, Tags: map[string]string{"campaign": "Run3Winter23"}, Remove: []string{"do_not_delete"}}
+	out, err = c.UpdateTags(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Tags) != 1 || out.Tags["campaign"] != "Run3Winter23" {
+		t.Errorf("wrong updated dataset tags %+v", out)
+	}
+	records, err := c.Tags(url.Values{"dataset": []string{dataset}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0]["tag_key"] != "campaign" || records[0]["tag_value"] != "Run3Winter23" {
+		t.Errorf("wrong dataset tags %+v", records)
+	}
+	records, err = c.Tags(url.Values{"block_name": []string{block}, "tag_key": []string{"camp*"}})
+	if err != nil || len(records) != 1 || records[0]["block_name"] != block {
+		t.Errorf("wrong block tags %+v, error %v", records, err)
+	}
+}
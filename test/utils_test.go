@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dmwm/dbs2go/utils"
@@ -143,3 +145,51 @@ func TestUtilsPipe(t *testing.T) {
 		t.Errorf("written data %s, read data %s", msg, string(data))
 	}
 }
+
+// TestUtilsStaticFS
+func TestUtilsStaticFS(t *testing.T) {
+	// embedded SQL templates should be identical to static area
+	fnames, err := utils.StaticDiff("../static", "sql", ".sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fnames) != 0 {
+		t.Fatalf("embedded SQL templates differ from static area: %v", fnames)
+	}
+	for _, fname := range []string{"lexicon_writer.json", "lexicon_reader.json", "parameters.json"} {
+		if _, err := utils.ReadStaticFile(fname); err != nil {
+			t.Fatalf("unable to read embedded %s, error %v", fname, err)
+		}
+	}
+
+	// override directory takes precedence over embedded SQL templates
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sql"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	stm := "SELECT DT.DATA_TIER_NAME FROM {{.Owner}}.DATA_TIERS DT"
+	if err := os.WriteFile(filepath.Join(dir, "sql", "tiers.sql"), []byte(stm), 0644); err != nil {
+		t.Fatal(err)
+	}
+	utils.StaticOverride = dir
+	defer func() { utils.StaticOverride = "" }()
+	out, err := utils.ParseStaticTmpl("sql", "tiers.sql", map[string]string{"Owner": "sqlite"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "SELECT DT.DATA_TIER_NAME FROM sqlite.DATA_TIERS DT" {
+		t.Fatalf("override SQL template is not used: %s", out)
+	}
+	// other templates are still taken from embedded files
+	files := utils.StaticFiles("sql")
+	if !utils.InList("tiers.sql", files) || !utils.InList("datasets.sql", files) {
+		t.Fatalf("wrong list of SQL templates %v", files)
+	}
+	fnames, err = utils.StaticDiff(dir, "sql", ".sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fnames) != 1 || fnames[0] != "tiers.sql" {
+		t.Fatalf("wrong list of SQL templates which differ from embedded ones %v", fnames)
+	}
+}
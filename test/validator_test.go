@@ -206,6 +206,86 @@ func TestValidatePostPayload(t *testing.T) {
 	}
 }
 
+// TestValidatorPayload
+func TestValidatorPayload(t *testing.T) {
+	lexPatterns, err := dbs.LoadPatterns(os.Getenv("DBS_LEXICON_FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.LexiconPatterns = lexPatterns
+
+	valid := `{
+		"dataset": {"dataset": "/prim/proc-v1/RAW", "processed_ds_name": "proc-v1", "creation_date": 1635177605},
+		"block": {"block_name": "/prim/proc-v1/RAW#123"},
+		"processing_era": {"processing_version": 1},
+		"files": [{"logical_file_name": "/store/data/Era/prim/RAW/v1/000/001/002/file.root",
+			"file_lumi_list": [{"run_num": 1, "lumi_section_num": 2}],
+			"file_parent_list": [{"file_parent_lfn": "/store/data/Era/prim/GEN/v1/000/001/002/file.root"}]}],
+		"dataset_parent_list": ["/prim/proc-v1/GEN"],
+		"tags": {"campaign": {"nested": "value"}}
+	}`
+	if violations := dbs.ValidatePayload([]byte(valid)); len(violations) != 0 {
+		t.Fatalf("unexpected violations %+v", violations)
+	}
+
+	invalid := `{
+		"dataset": {"dataset": "bla", "creation_date": 123},
+		"block": {"block_name": "/prim/proc-v1/RAW"},
+		"files": [{"logical_file_name": "/store/data/Era/prim/RAW/v1/000/001/002/file.root",
+			"file_parent_list": [{"file_parent_lfn": "bla"}]},
+			{"logical_file_name": "file.root",
+			"file_lumi_list": [{"run_num": -1, "lumi_section_num": 2}]}],
+		"dataset_parent_list": ["/prim/proc-v1/GEN", "bla"]
+	}`
+	violations := dbs.ValidatePayload([]byte(invalid))
+	expect := []string{
+		"$.dataset.dataset",
+		"$.dataset.creation_date",
+		"$.block.block_name",
+		"$.files[0].file_parent_list[0].file_parent_lfn",
+		"$.files[1].logical_file_name",
+		"$.files[1].file_lumi_list[0].run_num",
+		"$.dataset_parent_list[1]",
+	}
+	if len(violations) != len(expect) {
+		t.Fatalf("wrong number of violations %+v", violations)
+	}
+	for idx, v := range violations {
+		if v.Path != expect[idx] {
+			t.Errorf("wrong violation path %s, expect %s", v.Path, expect[idx])
+		}
+	}
+	// malformed payload is reported by API decoding it
+	if violations := dbs.ValidatePayload([]byte(`{"dataset": "bla", "block": {`)); len(violations) != 0 {
+		t.Errorf("unexpected violations of malformed payload %+v", violations)
+	}
+	err = dbs.PayloadValidationError(violations, "test")
+	data, err := json.Marshal(err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rec["violations"]; !ok {
+		t.Errorf("no violations in payload error %s", string(data))
+	}
+	if rec["code"] != float64(dbs.ValidateErrorCode) {
+		t.Errorf("wrong error code in payload error %s", string(data))
+	}
+
+	// PUT parameters are validated as flat record
+	params := dbs.Record{"dataset": "/prim/proc-v1/RAW", "physics_group_name": "Tracker"}
+	if violations := dbs.ValidatePayloadRecord(params); len(violations) != 0 {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+	params["dataset"] = "/a/b/c/d"
+	if violations := dbs.ValidatePayloadRecord(params); len(violations) != 1 {
+		t.Errorf("wrong violations %+v", violations)
+	}
+}
+
 // helper function to test validation success
 func validationSuccess(t *testing.T, rec dbs.DBRecord) {
 	log.Printf("Validate %+v", rec)
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dmwm/dbs2go/static"
+)
+
+// StaticOverride holds location of optional directory with static files
+// which take precedence over static files embedded into DBS server
+var StaticOverride string
+
+// overlayFS represents file system of embedded static files overlaid by
+// files from override directory
+type overlayFS struct {
+	dir  string
+	base fs.FS
+}
+
+// Open implements fs.FS interface
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if o.dir != "" {
+		if f, err := os.DirFS(o.dir).Open(name); err == nil {
+			// directories are always served from embedded files, their
+			// listing is provided by ReadDir
+			if st, err := f.Stat(); err == nil && !st.IsDir() {
+				return f, nil
+			}
+			f.Close()
+		}
+	}
+	return o.base.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS interface, it merges entries of override
+// and embedded directories
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(o.base, name)
+	if o.dir == "" {
+		return entries, err
+	}
+	oentries, oerr := fs.ReadDir(os.DirFS(o.dir), name)
+	if err != nil && oerr != nil {
+		return entries, err
+	}
+	emap := make(map[string]fs.DirEntry)
+	for _, e := range entries {
+		emap[e.Name()] = e
+	}
+	for _, e := range oentries {
+		emap[e.Name()] = e
+	}
+	var out []fs.DirEntry
+	for _, e := range emap {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+// StaticFS returns file system of DBS static files
+func StaticFS() fs.FS {
+	return overlayFS{dir: StaticOverride, base: static.Files}
+}
+
+// ReadStaticFile reads given static file. The file name without directory
+// is looked up in DBS static files, while file path is read from disk.
+func ReadStaticFile(fname string) ([]byte, error) {
+	if filepath.Base(fname) == fname {
+		return fs.ReadFile(StaticFS(), fname)
+	}
+	return os.ReadFile(fname)
+}
+
+// StaticFiles lists files of given static directory
+func StaticFiles(dir string) []string {
+	var out []string
+	entries, err := fs.ReadDir(StaticFS(), dir)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			out = append(out, e.Name())
+		}
+	}
+	return out
+}
+
+// ParseStaticTmpl parses template from given static directory with given data
+func ParseStaticTmpl(dir, tmpl string, data interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	t, err := template.ParseFS(StaticFS(), path.Join(dir, tmpl))
+	if err != nil {
+		return "", err
+	}
+	err = t.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), err
+}
+
+// StaticDiff compares files with given extension of static sub-directory
+// in provided directory with embedded ones and returns names of files which
+// differ from their embedded versions or do not exist in embedded files
+func StaticDiff(dir, sub, ext string) ([]string, error) {
+	var out []string
+	entries, err := os.ReadDir(filepath.Join(dir, sub))
+	if err != nil {
+		return out, err
+	}
+	for _, e := range entries {
+		fname := e.Name()
+		if e.IsDir() || !strings.HasSuffix(fname, ext) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, sub, fname))
+		if err != nil {
+			return out, err
+		}
+		edata, err := fs.ReadFile(static.Files, path.Join(sub, fname))
+		if err != nil || !bytes.Equal(data, edata) {
+			out = append(out, fname)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dmwm/dbs2go/client"
+	"github.com/dmwm/dbs2go/dbs"
+	"github.com/dmwm/dbs2go/utils"
+	validator "github.com/go-playground/validator/v10"
+)
+
+// ExportBundle writes migration bundle of given dataset or block from
+// remote DBS url into provided file. The bundle is written into temporary
+// file which is renamed to provided file only if export succeeds.
+func ExportBundle(rurl, input, fname string) error {
+	file, err := os.CreateTemp(filepath.Dir(fname), filepath.Base(fname)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	defer os.Remove(tmp)
+	dbs.RemoteClient = func(rurl string) dbs.RemoteDBS { return client.New(rurl) }
+	header, err := dbs.ExportMigrationBundle(strings.TrimSuffix(rurl, "/"), input, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	// temporary files are created with owner only permissions
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		return err
+	}
+	log.Printf("exported %d blocks of %s into %s", len(header.Blocks), input, fname)
+	return nil
+}
+
+// ImportBundle inserts migration bundle from provided file into DBS
+// database defined in given configuration file
+func ImportBundle(configFile, fname string) error {
+	err := ParseConfig(configFile)
+	if err != nil {
+		return err
+	}
+	utils.VERBOSE = Config.Verbose
+	utils.STATICDIR = Config.StaticDir
+	utils.StaticOverride = Config.StaticOverride
+	utils.BASE = Config.Base
+
+	// initialize record validator and insertion settings
+	dbs.RecordValidator = validator.New()
+	dbs.FileChunkSize = Config.FileChunkSize
+	dbs.FileLumiChunkSize = Config.FileLumiChunkSize
+	dbs.FileLumiMaxSize = Config.FileLumiMaxSize
+	dbs.FileLumiInsertMethod = Config.FileLumiInsertMethod
+	dbs.ConcurrentBulkBlocks = Config.ConcurrentBulkBlocks
+	dbs.ConcurrentHashSize = Config.ConcurrentHashSize
+
+	// set database connection
+	dbtype, dburi, dbowner := dbs.ParseDBFile(Config.DBFile)
+	if strings.HasPrefix(dbtype, "oci") {
+		utils.ORACLE = true
+	}
+	db, err := dbInit(dbtype, dburi)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	dbs.DB = db
+	dbs.DBTYPE = dbtype
+	dbs.DBSQL = dbs.LoadSQL(dbowner)
+	dbs.DBOWNER = dbowner
+	lexPatterns, err := dbs.LoadPatterns(Config.LexiconFile)
+	if err != nil {
+		return err
+	}
+	dbs.LexiconPatterns = lexPatterns
+
+	file, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	api := &dbs.API{
+		Reader:    file,
+		Writer:    utils.StdoutWriter(""),
+		Separator: ",",
+		CreateBy:  "DBS-workflow",
+		Api:       "import",
+	}
+	return api.ImportMigrationBundle()
+}
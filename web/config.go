@@ -7,7 +7,6 @@ package web
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 )
@@ -15,7 +14,8 @@ import (
 // Configuration stores dbs configuration parameters
 type Configuration struct {
 	Port            int      `json:"port"`              // dbs port number
-	StaticDir       string   `json:"staticdir"`         // location of static directory
+	StaticDir       string   `json:"staticdir"`         // location of static directory, used to report SQL templates which differ from embedded ones
+	StaticOverride  string   `json:"static_override"`   // location of directory with static files which take precedence over embedded ones
 	Base            string   `json:"base"`              // dbs base path
 	Verbose         int      `json:"verbose"`           // verbosity level
 	LogFile         string   `json:"log_file"`          // server log file (should ends with .log) or log area
@@ -32,23 +32,46 @@ type Configuration struct {
 	CacheControl    string   `json:"cache_control"`     // Cache-Control value, e.g. max-age=300
 	CMSRole         []string `json:"cms_role"`          // cms role for write access
 	CMSGroup        []string `json:"cms_group"`         // cms group for write access
+	AdminAPIs       bool     `json:"admin_apis"`        // enable admin APIs, e.g. delete, on DBSWriter server
+	AdminRole       []string `json:"admin_role"`        // cms role for access to admin APIs
+	AdminGroup      []string `json:"admin_group"`       // cms group for access to admin APIs
+	DrainTimeout    int      `json:"drain_timeout"`     // time in seconds to wait for in-flight writes and migrations on shutdown
+	HealthTimeout   int      `json:"health_timeout"`    // timeout in seconds of database checks of /readyz
 
 	// Migration server settings
-	MigrationDBFile          string `json:"migration_dbfile"`           // dbfile with secrets
-	MigrationServerInterval  int    `json:"migration_server_interval"`  // migration process interval
-	MigrationProcessTimeout  int    `json:"migration_process_timeout"`  // migration process timeout
-	MigrationCleanupInterval int    `json:"migration_cleanup_interval"` // migration cleanup interval
-	MigrationCleanupOffset   int64  `json:"migration_cleanup_offset"`   // migration cleanup offset
-	MigrationRetries         int64  `json:"migration_retries"`          // migration retries
-	MigrationAsyncTimeout    int    `json:"migration_async_timeout"`    // timeout for aysnc migration request
+	MigrationDBFile          string  `json:"migration_dbfile"`           // dbfile with secrets
+	MigrationServerInterval  int     `json:"migration_server_interval"`  // migration process interval
+	MigrationProcessTimeout  int     `json:"migration_process_timeout"`  // migration process timeout
+	MigrationCleanupInterval int     `json:"migration_cleanup_interval"` // migration cleanup interval
+	MigrationCleanupOffset   int64   `json:"migration_cleanup_offset"`   // migration cleanup offset
+	MigrationRetries         int64   `json:"migration_retries"`          // migration retries
+	MigrationAsyncTimeout    int     `json:"migration_async_timeout"`    // timeout for aysnc migration request
+	MigrationLeaseDuration   int     `json:"migration_lease_duration"`   // migration request lease duration in seconds
+	MigrationUserConcurrency int     `json:"migration_user_concurrency"` // number of concurrent migration requests per user, 0 means no limit
+	MigrationRetryBase       int64   `json:"migration_retry_base"`       // base delay of migration retries in seconds
+	MigrationRetryMax        int64   `json:"migration_retry_max"`        // maximum delay of migration retries in seconds
+	MigrationRetryJitter     float64 `json:"migration_retry_jitter"`     // fraction of migration retry delay used as jitter, negative value disables it
+
+	// Migration notification settings
+	MigrationWebhooks       []string `json:"migration_webhooks"`        // webhook URLs which receive all migration events
+	MigrationWebhookSecret  string   `json:"migration_webhook_secret"`  // file with secret used to sign webhook payloads (HMAC-SHA256)
+	MigrationWebhookRetries int      `json:"migration_webhook_retries"` // number of retries of webhook delivery
+	MigrationCallbackURLs   []string `json:"migration_callback_urls"`   // URL prefixes allowed as callback URLs of migration requests
+	MigrationEventsFile     string   `json:"migration_events_file"`     // ndjson file to write migration events
+
+	// Block lifecycle settings
+	BlockCloseInterval int   `json:"block_close_interval"` // block close daemon interval in seconds, 0 disables it
+	BlockIdleHours     int   `json:"block_idle_hours"`     // close open blocks idle for given number of hours
+	BlockMaxSize       int64 `json:"block_max_size"`       // close open blocks which reached given size
+	BlockMaxFiles      int64 `json:"block_max_files"`      // close open blocks which reached given number of files
 
 	// db related configuration
 	DBFile               string `json:"dbfile"`                  // dbs db file with secrets
 	MaxDBConnections     int    `json:"max_db_connections"`      // maximum number of DB connections
 	MaxIdleConnections   int    `json:"max_idle_connections"`    // maximum number of idle connections
 	DBMonitoringInterval int    `json:"db_monitoring_interval"`  // db mon interval in seconds
-	ApiParametersFile    string `json:"api_parameters_file"`     // api parameters json file
-	LexiconFile          string `json:"lexicon_file"`            // lexicon json file
+	ApiParametersFile    string `json:"api_parameters_file"`     // api parameters json file, file name without directory is taken from embedded static files
+	LexiconFile          string `json:"lexicon_file"`            // lexicon json file, file name without directory is taken from embedded static files
 	FileChunkSize        int    `json:"file_chunk_size"`         // chunk size for []File insertion
 	FileLumiChunkSize    int    `json:"file_lumi_chunk_size"`    // chunk size for []FileLumi insertion
 	FileLumiMaxSize      int    `json:"file_lumi_max_size"`      // max size for []FileLumi insertion
@@ -57,7 +80,7 @@ type Configuration struct {
 	ConcurrentHashSize   int    `json:"concurrent_hash_size"`    // size of hash to use to encode concurrent request
 
 	// server static parts
-	Templates string `json:"templates"` // location of server templates
+	Templates string `json:"templates"` // location of server templates, by default embedded templates are used
 	Jscripts  string `json:"jscripts"`  // location of server JavaScript files
 	Images    string `json:"images"`    // location of server images
 	Styles    string `json:"styles"`    // location of server CSS styles
@@ -120,9 +143,33 @@ func ParseConfig(configFile string) error {
 	if Config.MigrationCleanupInterval == 0 {
 		Config.MigrationCleanupInterval = 600 // in seconds
 	}
+	if Config.MigrationLeaseDuration == 0 {
+		Config.MigrationLeaseDuration = 300 // in seconds
+	}
+	if Config.MigrationRetryBase == 0 {
+		Config.MigrationRetryBase = 600 // in seconds
+	}
+	if Config.MigrationRetryMax == 0 {
+		Config.MigrationRetryMax = 6 * 60 * 60 // in seconds
+	}
+	if Config.MigrationRetryJitter == 0 {
+		Config.MigrationRetryJitter = 0.1
+	}
+	if Config.MigrationWebhookRetries == 0 {
+		Config.MigrationWebhookRetries = 3
+	}
 	if Config.MigrationCleanupOffset == 0 {
 		Config.MigrationCleanupOffset = 3 * 30 * 24 * 60 * 60 // 3 months in seconds
 	}
+	if Config.BlockIdleHours == 0 {
+		Config.BlockIdleHours = 7 * 24 // one week
+	}
+	if Config.DrainTimeout == 0 {
+		Config.DrainTimeout = 60 // in seconds
+	}
+	if Config.HealthTimeout == 0 {
+		Config.HealthTimeout = 5 // in seconds
+	}
 	if Config.MetricsPrefix == "" {
 		Config.MetricsPrefix = "dbs2go"
 	}
@@ -142,8 +189,14 @@ func ParseConfig(configFile string) error {
 		// possible values are: temptable, chunks, linear
 		Config.FileLumiInsertMethod = "chunks"
 	}
-	if Config.Templates == "" {
-		Config.Templates = fmt.Sprintf("%s/templates", Config.StaticDir)
+	if Config.LexiconFile == "" {
+		Config.LexiconFile = "lexicon_writer.json"
+		if Config.ServerType == "DBSReader" {
+			Config.LexiconFile = "lexicon_reader.json"
+		}
+	}
+	if Config.ApiParametersFile == "" {
+		Config.ApiParametersFile = "parameters.json"
 	}
 	if Config.MigrationRetries == 0 {
 		Config.MigrationRetries = 3
@@ -3,12 +3,16 @@ package web
 // handlers.go - provides handlers examples for dbs2go server
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"runtime"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -324,6 +328,8 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 		rec["status"] = http.StatusInternalServerError
 		w.WriteHeader(http.StatusInternalServerError)
 	}
+	rec["draining"] = dbs.Draining()
+	rec["in_flight"] = dbs.InFlight()
 	records = append(records, rec)
 	data, err := json.Marshal(records)
 	if err != nil {
@@ -332,6 +338,75 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// LivezHandler provides liveness status of DBS server. It only reports that
+// server process is able to serve HTTP requests and does not depend on
+// database or other external services.
+func LivezHandler(w http.ResponseWriter, r *http.Request) {
+	rec := make(dbs.Record)
+	rec["status"] = http.StatusOK
+	rec["server_type"] = Config.ServerType
+	rec["uptime"] = int64(time.Since(StartTime).Seconds())
+	rec["goroutines"] = runtime.NumGoroutine()
+	data, err := json.Marshal([]dbs.Record{rec})
+	if err != nil {
+		log.Fatalf("Fail to marshal records, %v", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ReadyzHandler provides readiness status of DBS server along with status
+// of its dependencies: database connectivity and latency, migration
+// database, SQL templates and lexicon patterns, X509 proxy freshness,
+// migration daemon heartbeat and server drain state.
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := []dbs.HealthCheck{
+		dbs.CheckDB("database", dbs.DB),
+		dbs.CheckTemplates(),
+	}
+	if Config.ServerType == "DBSMigrate" || Config.ServerType == "DBSMigration" {
+		checks = append(checks, dbs.CheckDB("migration_database", dbs.MigrationDB))
+		checks = append(checks, dbs.CheckTLSProxy())
+	}
+	if Config.ServerType == "DBSMigration" {
+		checks = append(checks, dbs.CheckMigrationDaemon())
+	}
+	checks = append(checks, dbs.CheckDrain())
+
+	rec := make(dbs.Record)
+	status := http.StatusOK
+	if err := dbs.HealthStatus(checks); err != nil {
+		log.Println("/readyz ReadyzHandler error", err)
+		status = http.StatusServiceUnavailable
+	}
+	rec["status"] = status
+	rec["checks"] = checks
+	data, err := json.Marshal([]dbs.Record{rec})
+	if err != nil {
+		log.Fatalf("Fail to marshal records, %v", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// helper function to track write request of DBSWriter and DBSMigrate
+// servers. It rejects request with service unavailable status if server is
+// draining.
+func trackWrite(w http.ResponseWriter, r *http.Request) (func(), bool) {
+	if Config.ServerType == "DBSReader" {
+		return func() {}, true
+	}
+	done, err := dbs.TrackWork()
+	if err != nil {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", Config.DrainTimeout))
+		responseMsg(w, r, err, http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return done, true
+}
+
 // ServerInfoHandler provides basic functionality of status response
 func ServerInfoHandler(w http.ResponseWriter, r *http.Request) {
 	var records []dbs.Record
@@ -362,6 +437,43 @@ func ApisHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// SchemaHandler provides accepted parameters, their types, lexicon patterns
+// and POST body JSON Schema of DBS server APIs. The output can be narrowed
+// down by api and method query parameters.
+func SchemaHandler(w http.ResponseWriter, r *http.Request) {
+	if err := loadApiParameters(); err != nil {
+		responseMsg(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	api := r.URL.Query().Get("api")
+	method := strings.ToUpper(r.URL.Query().Get("method"))
+	var paths []string
+	for path := range webRoutes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	records := []dbs.ApiSchema{}
+	for _, path := range paths {
+		name := path[strings.LastIndex(path, "/")+1:]
+		if name == "" || (api != "" && name != api) {
+			continue
+		}
+		for _, m := range webRoutes[path] {
+			if method != "" && m != method {
+				continue
+			}
+			records = append(records, dbs.NewApiSchema(name, m))
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		e := dbs.Error(err, dbs.MarshalErrorCode, "unable to marshal API schema", "web.SchemaHandler")
+		responseMsg(w, r, e, http.StatusInternalServerError)
+		return
+	}
+	w.Write(data)
+}
+
 // helper function to parse POST HTTP request payload
 func parseParams(r *http.Request) (dbs.Record, error) {
 	params := make(dbs.Record)
@@ -431,6 +543,12 @@ func DBSPutHandler(w http.ResponseWriter, r *http.Request, a string) {
 	time0 := time.Now()
 	defer updatePutRequestTime(time0)
 
+	done, ok := trackWrite(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
 	// all outputs will be added to output list
 	sep := ","
 	if r.Header.Get("Accept") == "application/ndjson" {
@@ -461,6 +579,11 @@ func DBSPutHandler(w http.ResponseWriter, r *http.Request, a string) {
 		dn, _ := r.Header["Cms-Authn-Dn"]
 		log.Printf("DBSPutHandler: API=%s, dn=%s, uri=%s, params: %+v", a, dn, requestURI(r), params)
 	}
+	if violations := dbs.ValidatePayloadRecord(params); len(violations) > 0 {
+		e := dbs.PayloadValidationError(violations, "web.DBSPutHandler")
+		responseMsg(w, r, e, http.StatusBadRequest)
+		return
+	}
 	cby := createBy(r)
 	params["create_by"] = cby
 	api := &dbs.API{
@@ -541,6 +664,32 @@ func DBSPostHandler(w http.ResponseWriter, r *http.Request, a string) {
 		}
 		body = utils.GzipReader{reader, r.Body}
 	}
+	readerApi := a == "fileArray" || a == "datasetlist" || a == "fileparentsbylumi" || a == "filelumis" || a == "blockparents" || a == "process" || a == "parentageaudit"
+	// read-only APIs are served while server is draining, the process and
+	// parentageaudit APIs are not validated as writer payloads but they
+	// modify DB and therefore are tracked as writes
+	if !readerApi || a == "process" || a == "parentageaudit" {
+		done, ok := trackWrite(w, r)
+		if !ok {
+			return
+		}
+		defer done()
+	}
+	if Config.ServerType == "DBSWriter" && !readerApi {
+		// validate entire payload against writer lexicon before insertion
+		data, err := io.ReadAll(body)
+		if err != nil {
+			e := dbs.Error(err, dbs.ReaderErrorCode, "unable to read payload", "web.DBSPostHandler")
+			responseMsg(w, r, e, http.StatusBadRequest)
+			return
+		}
+		if violations := dbs.ValidatePayload(data); len(violations) > 0 {
+			e := dbs.PayloadValidationError(violations, "web.DBSPostHandler")
+			responseMsg(w, r, e, http.StatusBadRequest)
+			return
+		}
+		body = io.NopCloser(bytes.NewReader(data))
+	}
 	api := &dbs.API{
 		Reader:    body,
 		Writer:    w,
@@ -555,7 +704,7 @@ func DBSPostHandler(w http.ResponseWriter, r *http.Request, a string) {
 		defer gw.Close()
 		api.Writer = utils.GzipWriter{GzipWriter: gw, Writer: w}
 	}
-	if a == "fileArray" || a == "datasetlist" || a == "fileparentsbylumi" || a == "filelumis" || a == "blockparents" || a == "process" {
+	if readerApi {
 		params, err = parsePayload(r)
 		if err != nil {
 			responseMsg(w, r, err, http.StatusInternalServerError)
@@ -600,6 +749,10 @@ func DBSPostHandler(w http.ResponseWriter, r *http.Request, a string) {
 		err = api.FileArray()
 	} else if a == "fileparentsbylumi" {
 		err = api.FileParentsByLumi()
+	} else if a == "insertfileparentsbylumi" {
+		err = api.InsertFileParentsByLumi()
+	} else if a == "parentageaudit" {
+		err = api.ParentageAudit()
 	} else if a == "filelumis" {
 		err = api.FileLumis()
 	} else if a == "blockparents" {
@@ -612,6 +765,16 @@ func DBSPostHandler(w http.ResponseWriter, r *http.Request, a string) {
 		err = api.ProcessMigrationCtx(dbs.MigrationProcessTimeout)
 	} else if a == "remove" {
 		err = api.RemoveMigration()
+	} else if a == "import" {
+		err = api.ImportMigrationBundle()
+	} else if a == "plan" {
+		err = api.PlanMigration()
+	} else if a == "delete" {
+		err = api.Delete()
+	} else if a == "renamedataset" {
+		err = api.RenameDataset()
+	} else if a == "tags" {
+		err = api.UpdateTags()
 	}
 	if err != nil {
 		responseMsg(w, r, err, http.StatusBadRequest)
@@ -649,6 +812,10 @@ func DBSGetHandler(w http.ResponseWriter, r *http.Request, a string) {
 		responseMsg(w, r, err, http.StatusBadRequest)
 		return
 	}
+	// old names of renamed datasets are resolved to their current names
+	for _, hint := range dbs.ResolveRenames(params) {
+		w.Header().Add("X-Dbs-Renamed", hint)
+	}
 	if utils.VERBOSE > 0 {
 		dn, _ := r.Header["Cms-Authn-Dn"]
 		log.Printf("DBSGetHandler: API=%s, dn=%s, uri=%+v, params: %+v", a, dn, requestURI(r), params)
@@ -718,6 +885,12 @@ func DBSGetHandler(w http.ResponseWriter, r *http.Request, a string) {
 		err = api.BlockParents()
 	} else if a == "blocksummaries" {
 		err = api.BlockSummaries()
+	} else if a == "datasetstats" {
+		err = api.DatasetStats()
+	} else if a == "parentageaudit" {
+		err = api.ParentageAudit()
+	} else if a == "staleblocks" {
+		err = api.StaleBlocks()
 	} else if a == "blockorigin" {
 		err = api.BlockOrigin()
 	} else if a == "blockTrio" {
@@ -730,6 +903,12 @@ func DBSGetHandler(w http.ResponseWriter, r *http.Request, a string) {
 		err = api.StatusMigration()
 	} else if a == "total" {
 		err = api.TotalMigration()
+	} else if a == "deletions" {
+		err = api.Deletions()
+	} else if a == "datasetrenames" {
+		err = api.DatasetRenames()
+	} else if a == "tags" {
+		err = api.Tags()
 	} else {
 		err = dbs.NotImplementedApiErr
 	}
@@ -809,6 +988,29 @@ func BlockSummariesHandler(w http.ResponseWriter, r *http.Request) {
 	DBSGetHandler(w, r, "blocksummaries")
 }
 
+// DatasetStatsHandler provides access to DatasetStats DBS API.
+// Takes the following arguments: dataset, dataset_access_type, group_by
+func DatasetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	DBSGetHandler(w, r, "datasetstats")
+}
+
+// ParentageAuditHandler provides access to ParentageAudit DBS API.
+// Takes the following arguments: dataset, block_name
+// POST API takes JSON payload with dataset, block_name and fix
+func ParentageAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		DBSPostHandler(w, r, "parentageaudit")
+	} else {
+		DBSGetHandler(w, r, "parentageaudit")
+	}
+}
+
+// StaleBlocksHandler provides access to StaleBlocks DBS API.
+// Takes the following arguments: dataset, block_name, idle_hours
+func StaleBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	DBSGetHandler(w, r, "staleblocks")
+}
+
 // BlockOriginHandler provides access to BlockOrigin DBS API.
 // Takes the following arguments: origin_site_name, dataset, block_name
 func BlockOriginHandler(w http.ResponseWriter, r *http.Request) {
@@ -992,6 +1194,47 @@ func DatasetListHandler(w http.ResponseWriter, r *http.Request) {
 	DBSPostHandler(w, r, "datasetlist")
 }
 
+// DeleteHandler provides access to Delete DBS admin API
+// POST API takes JSON payload with dataset or block_name, mode, dry_run and reason
+func DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	DBSPostHandler(w, r, "delete")
+}
+
+// DeletionsHandler provides access to Deletions DBS admin API
+// GET API takes the following parameters: entity_name, entity_type, deleted_by
+func DeletionsHandler(w http.ResponseWriter, r *http.Request) {
+	DBSGetHandler(w, r, "deletions")
+}
+
+// RenameDatasetHandler provides access to RenameDataset DBS admin API
+// POST API takes JSON payload with dataset, new_dataset and reason
+func RenameDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	DBSPostHandler(w, r, "renamedataset")
+}
+
+// DatasetRenamesHandler provides access to DatasetRenames DBS API
+// GET API takes the following parameters: old_dataset, new_dataset
+func DatasetRenamesHandler(w http.ResponseWriter, r *http.Request) {
+	DBSGetHandler(w, r, "datasetrenames")
+}
+
+// TagsHandler provides access to Tags and UpdateTags DBS APIs
+// GET API takes the following parameters: dataset, block_name, tag_key
+// POST API takes JSON payload with dataset or block_name, tags and remove
+func TagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		DBSPostHandler(w, r, "tags")
+	} else {
+		DBSGetHandler(w, r, "tags")
+	}
+}
+
+// InsertFileParentsByLumiHandler provides access to InsertFileParentsByLumi DBS API
+// POST API takes JSON payload with block_name, parent_dataset and dry_run
+func InsertFileParentsByLumiHandler(w http.ResponseWriter, r *http.Request) {
+	DBSPostHandler(w, r, "insertfileparentsbylumi")
+}
+
 // FileParentsByLumiHandler provides access to FileParentsByLumi DBS API
 // POST API takes no argument, the payload should be supplied as JSON
 func FileParentsByLumiHandler(w http.ResponseWriter, r *http.Request) {
@@ -1034,6 +1277,18 @@ func MigrationCancelHandler(w http.ResponseWriter, r *http.Request) {
 	DBSPostHandler(w, r, "cancel")
 }
 
+// MigrationImportHandler provides access to ImportMigrationBundle DBS API
+// POST API takes no argument, the payload should be migration bundle
+func MigrationImportHandler(w http.ResponseWriter, r *http.Request) {
+	DBSPostHandler(w, r, "import")
+}
+
+// MigrationPlanHandler provides access to PlanMigration DBS API
+// POST API takes no argument, the payload should be supplied as JSON
+func MigrationPlanHandler(w http.ResponseWriter, r *http.Request) {
+	DBSPostHandler(w, r, "plan")
+}
+
 // MigrationStatusHandler provides access to StatusMigration DBS API
 func MigrationStatusHandler(w http.ResponseWriter, r *http.Request) {
 	DBSGetHandler(w, r, "status")
@@ -80,6 +80,37 @@ func authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// helper to authorize access to admin APIs, the user should have at least
+// one of admin role/group pairs, the access is denied if admin roles and
+// groups are not configured
+func adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(Config.AdminRole) == 0 || len(Config.AdminGroup) == 0 {
+			log.Println("ERROR: admin APIs require admin_role and admin_group attributes")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if len(Config.AdminRole) != len(Config.AdminGroup) {
+			log.Println("not equal length of admin_role and admin_group attributes")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		status := false
+		for i, role := range Config.AdminRole {
+			if CMSAuth.CheckCMSAuthz(r.Header, role, Config.AdminGroup[i], "") {
+				status = true
+				break
+			}
+		}
+		if !status {
+			log.Printf("ERROR: fail to authorize admin with role=%v and group=%v, HTTP headers %+v\n", Config.AdminRole, Config.AdminGroup, r.Header)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // helper to validate incoming requests' parameters
 func validateMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,193 @@
+package web
+
+// openapi.go - provides OpenAPI 3 specification of DBS server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/dbs2go/dbs"
+)
+
+// OpenAPIVersion defines version of OpenAPI specification
+const OpenAPIVersion = "3.0.3"
+
+// openAPISpec holds OpenAPI specification generated from HTTP routes
+var openAPISpec []byte
+
+// helper function to load API parameters if they are not loaded yet
+func loadApiParameters() error {
+	if dbs.ApiParamMap != nil {
+		return nil
+	}
+	pmap, err := dbs.LoadApiParameters(dbs.ApiParametersFile)
+	if err != nil {
+		return err
+	}
+	dbs.ApiParamMap = pmap
+	return nil
+}
+
+// helper function to convert DBS parameter schema into OpenAPI schema
+func openAPIParameterSchema(p dbs.ParameterSchema) dbs.Record {
+	var schema dbs.Record
+	switch p.Type {
+	case "integer":
+		schema = dbs.Record{"type": "integer"}
+	case "string|integer":
+		schema = dbs.Record{"oneOf": []dbs.Record{{"type": "string"}, {"type": "integer"}}}
+	default:
+		schema = dbs.LexiconSchema(p.Patterns, p.Length)
+	}
+	return schema
+}
+
+// helper function to convert JSON Schema into OpenAPI schema object
+func openAPISchema(schema dbs.Record) dbs.Record {
+	// JSON Schema keywords which are not part of OpenAPI schema object
+	delete(schema, "$schema")
+	delete(schema, "title")
+	return schema
+}
+
+// helper function to build OpenAPI operation for given API and method
+func openAPIOperation(api, method string) dbs.Record {
+	rec := dbs.NewApiSchema(api, method)
+	op := dbs.Record{
+		"operationId": fmt.Sprintf("%s%s", strings.ToLower(method), api),
+		"summary":     fmt.Sprintf("%s %s DBS API", method, api),
+		"tags":        []string{Config.ServerType},
+		"responses": dbs.Record{
+			"200": dbs.Record{
+				"description": "successful response",
+				"content": dbs.Record{
+					"application/json": dbs.Record{
+						"schema": dbs.Record{"type": "array", "items": dbs.Record{}},
+					},
+					"application/ndjson": dbs.Record{
+						"schema": dbs.Record{"type": "string"},
+					},
+				},
+			},
+			"default": dbs.Record{"$ref": "#/components/responses/Error"},
+		},
+	}
+	if rec.Body != nil {
+		op["requestBody"] = dbs.Record{
+			"required": true,
+			"content": dbs.Record{
+				"application/json": dbs.Record{"schema": openAPISchema(rec.Body)},
+			},
+		}
+		return op
+	}
+	if method == "POST" {
+		// reader POST APIs accept their parameters as JSON payload
+		props := make(dbs.Record)
+		for _, p := range rec.Parameters {
+			props[p.Name] = openAPIParameterSchema(p)
+		}
+		op["requestBody"] = dbs.Record{
+			"content": dbs.Record{
+				"application/json": dbs.Record{
+					"schema": dbs.Record{"type": "object", "properties": props},
+				},
+			},
+		}
+		return op
+	}
+	var params []dbs.Record
+	for _, p := range rec.Parameters {
+		params = append(params, dbs.Record{
+			"name":   p.Name,
+			"in":     "query",
+			"schema": openAPIParameterSchema(p),
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+// OpenAPI generates OpenAPI specification of DBS server from its HTTP routes,
+// API parameters, lexicon patterns and DBS record structures
+func OpenAPI() dbs.Record {
+	if err := loadApiParameters(); err != nil {
+		log.Println("unable to load API parameters", err)
+	}
+	var routes []string
+	for path := range webRoutes {
+		routes = append(routes, path)
+	}
+	sort.Strings(routes)
+	paths := make(dbs.Record)
+	for _, path := range routes {
+		api := path[strings.LastIndex(path, "/")+1:]
+		item := make(dbs.Record)
+		for _, method := range webRoutes[path] {
+			item[strings.ToLower(method)] = openAPIOperation(api, method)
+		}
+		paths[path] = item
+	}
+	return dbs.Record{
+		"openapi": OpenAPIVersion,
+		"info": dbs.Record{
+			"title":       fmt.Sprintf("%s server", Config.ServerType),
+			"description": "Data Bookkeeping Service (DBS) APIs",
+			"version":     GitVersion,
+		},
+		"paths": paths,
+		"components": dbs.Record{
+			"schemas": dbs.Record{
+				"DBSError":  openAPISchema(dbs.JSONSchema(dbs.DBSError{})),
+				"HTTPError": openAPISchema(dbs.JSONSchema(HTTPError{})),
+				"ServerError": dbs.Record{
+					"type": "object",
+					"properties": dbs.Record{
+						"error":     dbs.Record{"$ref": "#/components/schemas/DBSError"},
+						"http":      dbs.Record{"$ref": "#/components/schemas/HTTPError"},
+						"exception": dbs.Record{"type": "integer"},
+						"type":      dbs.Record{"type": "string"},
+						"message":   dbs.Record{"type": "string"},
+					},
+				},
+			},
+			"responses": dbs.Record{
+				"Error": dbs.Record{
+					"description": "DBS error",
+					"content": dbs.Record{
+						"application/json": dbs.Record{
+							"schema": dbs.Record{
+								"type":  "array",
+								"items": dbs.Record{"$ref": "#/components/schemas/ServerError"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// helper function to generate OpenAPI specification of HTTP routes
+func initOpenAPI() {
+	data, err := json.Marshal(OpenAPI())
+	if err != nil {
+		log.Println("unable to generate OpenAPI specification", err)
+		return
+	}
+	openAPISpec = data
+}
+
+// OpenAPIHandler provides OpenAPI specification of DBS server
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if openAPISpec == nil {
+		initOpenAPI()
+	}
+	w.Write(openAPISpec)
+}
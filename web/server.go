@@ -32,6 +32,7 @@ import (
 	"crypto/x509"
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -45,6 +46,7 @@ import (
 	_ "net/http/pprof"
 
 	"github.com/dmwm/cmsauth"
+	"github.com/dmwm/dbs2go/client"
 	"github.com/dmwm/dbs2go/dbs"
 	dbsGraphQL "github.com/dmwm/dbs2go/graphql"
 	"github.com/dmwm/dbs2go/utils"
@@ -106,6 +108,8 @@ func Handlers() *mux.Router {
 		router.HandleFunc(basePath("/remove"), MigrationRemoveHandler).Methods("POST")
 		router.HandleFunc(basePath("/status"), MigrationStatusHandler).Methods("GET")
 		router.HandleFunc(basePath("/total"), MigrationTotalHandler).Methods("GET")
+		router.HandleFunc(basePath("/import"), MigrationImportHandler).Methods("POST")
+		router.HandleFunc(basePath("/plan"), MigrationPlanHandler).Methods("POST")
 		router.HandleFunc(basePath("/blocks"), BlocksHandler).Methods("GET")
 		router.HandleFunc(basePath("/bulkblocks"), BulkBlocksHandler).Methods("POST")
 		router.HandleFunc(basePath("/blockparents"), BlocksHandler).Methods("GET")
@@ -137,6 +141,9 @@ func Handlers() *mux.Router {
 		router.HandleFunc(basePath("/blockchildren"), BlockChildrenHandler).Methods("GET")
 		router.HandleFunc(basePath("/blockparents"), BlockParentsHandler).Methods("GET")
 		router.HandleFunc(basePath("/blocksummaries"), BlockSummariesHandler).Methods("GET")
+		router.HandleFunc(basePath("/datasetstats"), DatasetStatsHandler).Methods("GET")
+		router.HandleFunc(basePath("/parentageaudit"), ParentageAuditHandler).Methods("GET")
+		router.HandleFunc(basePath("/staleblocks"), StaleBlocksHandler).Methods("GET")
 		router.HandleFunc(basePath("/filechildren"), FileChildrenHandler).Methods("GET")
 		router.HandleFunc(basePath("/fileparents"), FileParentsHandler).Methods("GET")
 		router.HandleFunc(basePath("/filesummaries"), FileSummariesHandler).Methods("GET")
@@ -144,6 +151,8 @@ func Handlers() *mux.Router {
 		router.HandleFunc(basePath("/datasetchildren"), DatasetChildrenHandler).Methods("GET")
 		router.HandleFunc(basePath("/datasetparents"), DatasetParentsHandler).Methods("GET")
 		router.HandleFunc(basePath("/acquisitioneras_ci"), AcquisitionErasCiHandler).Methods("GET")
+		router.HandleFunc(basePath("/datasetrenames"), DatasetRenamesHandler).Methods("GET")
+		router.HandleFunc(basePath("/tags"), TagsHandler).Methods("GET")
 
 		router.HandleFunc(basePath("/blockparents"), BlockParentsHandler).Methods("POST")
 		router.HandleFunc(basePath("/fileArray"), FileArrayHandler).Methods("POST")
@@ -186,16 +195,29 @@ func Handlers() *mux.Router {
 		router.HandleFunc(basePath("/outputconfigs"), OutputConfigsHandler).Methods("POST", "GET")
 		router.HandleFunc(basePath("/fileparents"), FileParentsHandler).Methods("POST", "GET")
 		router.HandleFunc(basePath("/fileparentsbylumi"), FileParentsByLumiHandler).Methods("POST", "GET")
+		router.HandleFunc(basePath("/insertfileparentsbylumi"), InsertFileParentsByLumiHandler).Methods("POST")
+		router.HandleFunc(basePath("/parentageaudit"), ParentageAuditHandler).Methods("POST", "GET")
 		router.HandleFunc(basePath("/blockparents"), BlockParentsHandler).Methods("GET")
 		router.HandleFunc(basePath("/datasetparents"), DatasetParentsHandler).Methods("GET")
+		router.HandleFunc(basePath("/datasetrenames"), DatasetRenamesHandler).Methods("GET")
+		router.HandleFunc(basePath("/tags"), TagsHandler).Methods("POST", "GET")
+		if Config.AdminAPIs {
+			router.Handle(basePath("/delete"), adminMiddleware(http.HandlerFunc(DeleteHandler))).Methods("POST")
+			router.HandleFunc(basePath("/deletions"), DeletionsHandler).Methods("GET")
+			router.Handle(basePath("/renamedataset"), adminMiddleware(http.HandlerFunc(RenameDatasetHandler))).Methods("POST")
+		}
 	}
 
 	// aux APIs used by all DBS servers
 	router.HandleFunc(basePath("/errors"), ErrorsHandler).Methods("GET")
 	router.HandleFunc(basePath("/healthz"), StatusHandler).Methods("GET")
+	router.HandleFunc(basePath("/livez"), LivezHandler).Methods("GET")
+	router.HandleFunc(basePath("/readyz"), ReadyzHandler).Methods("GET")
 	router.HandleFunc(basePath("/serverinfo"), ServerInfoHandler).Methods("GET")
 	router.HandleFunc(basePath("/metrics"), MetricsHandler).Methods("GET")
 	router.HandleFunc(basePath("/apis"), ApisHandler).Methods("GET")
+	router.HandleFunc(basePath("/schema"), SchemaHandler).Methods("GET")
+	router.HandleFunc(basePath("/openapi.json"), OpenAPIHandler).Methods("GET")
 	// backward compatible with Python server
 	router.HandleFunc(basePath("/help"), ApisHandler).Methods("GET")
 	router.HandleFunc(basePath("/dummy"), DummyHandler).Methods("GET", "POST")
@@ -216,8 +238,12 @@ func Handlers() *mux.Router {
 	router.Use(limitMiddleware)
 
 	// get list of defined routes
+	webRoutes = nil
 	router.Walk(walkFunction)
 
+	// generate OpenAPI specification of defined routes
+	initOpenAPI()
+
 	return router
 }
 
@@ -248,6 +274,29 @@ func walkFunction(route *mux.Route, router *mux.Router, ancestors []*mux.Route)
 	return err
 }
 
+// helper function to report SQL templates of static and override
+// directories which differ from SQL templates embedded into DBS server
+func checkStaticFiles() {
+	for _, dir := range []string{Config.StaticDir, Config.StaticOverride} {
+		if dir == "" {
+			continue
+		}
+		fnames, err := utils.StaticDiff(dir, "sql", ".sql")
+		if err != nil {
+			log.Printf("unable to compare SQL templates of %s, error %v", dir, err)
+			continue
+		}
+		if len(fnames) == 0 {
+			log.Printf("SQL templates of %s are identical to embedded ones", dir)
+			continue
+		}
+		log.Printf("WARNING: %d SQL templates of %s differ from embedded ones: %s", len(fnames), dir, strings.Join(fnames, ", "))
+		if dir == Config.StaticDir && dir != Config.StaticOverride {
+			log.Println("embedded SQL templates are used, please set static_override to use SQL templates from", dir)
+		}
+	}
+}
+
 // helper function to initialize DB access
 func dbInit(dbtype, dburi string) (*sql.DB, error) {
 	//     close existing DB connection if it exist
@@ -311,6 +360,7 @@ func Server(configFile string) {
 	}
 	utils.VERBOSE = Config.Verbose
 	utils.STATICDIR = Config.StaticDir
+	utils.StaticOverride = Config.StaticOverride
 	utils.BASE = Config.Base
 	utils.Localhost = fmt.Sprintf("http://localhost:%d", Config.Port)
 	log.SetFlags(0)
@@ -379,10 +429,16 @@ func Server(configFile string) {
 	// static handlers
 	for _, dir := range []string{"js", "css", "images"} {
 		m := fmt.Sprintf("%s/%s/", Config.Base, dir)
-		d := fmt.Sprintf("%s/%s", utils.STATICDIR, dir)
-		http.Handle(m, http.StripPrefix(m, http.FileServer(http.Dir(d))))
+		d, err := fs.Sub(utils.StaticFS(), dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		http.Handle(m, http.StripPrefix(m, http.FileServer(http.FS(d))))
 	}
 
+	// report SQL templates which differ from embedded ones
+	checkStaticFiles()
+
 	// set database connection once
 	log.Println("parse Config.DBFile:", Config.DBFile)
 	dbtype, dburi, dbowner := dbs.ParseDBFile(Config.DBFile)
@@ -424,12 +480,40 @@ func Server(configFile string) {
 	dbs.DBOWNER = dbowner
 
 	// migration settings
+	// remote DBS servers are accessed via DBS client
+	dbs.RemoteClient = func(rurl string) dbs.RemoteDBS { return client.New(rurl) }
 	dbs.MigrationAsyncTimeout = Config.MigrationAsyncTimeout
 	dbs.MigrationProcessTimeout = Config.MigrationProcessTimeout
 	dbs.MigrationServerInterval = Config.MigrationServerInterval
 	dbs.MigrationCleanupInterval = Config.MigrationCleanupInterval
 	dbs.MigrationCleanupOffset = Config.MigrationCleanupOffset
 	dbs.MigrationRetries = Config.MigrationRetries
+	dbs.MigrationLeaseDuration = Config.MigrationLeaseDuration
+	dbs.MigrationUserConcurrency = Config.MigrationUserConcurrency
+	dbs.MigrationRetryBase = Config.MigrationRetryBase
+	dbs.MigrationRetryMax = Config.MigrationRetryMax
+	dbs.MigrationRetryJitter = Config.MigrationRetryJitter
+	dbs.MigrationWebhooks = Config.MigrationWebhooks
+	if Config.MigrationWebhookSecret != "" {
+		secret, err := os.ReadFile(Config.MigrationWebhookSecret)
+		if err != nil {
+			log.Fatal("unable to read migration webhook secret", err)
+		}
+		dbs.MigrationWebhookSecret = strings.TrimSpace(string(secret))
+	}
+	dbs.MigrationWebhookRetries = Config.MigrationWebhookRetries
+	dbs.MigrationCallbackURLs = Config.MigrationCallbackURLs
+	dbs.MigrationEventsFile = Config.MigrationEventsFile
+
+	// server drain and health settings
+	dbs.DrainTimeout = Config.DrainTimeout
+	dbs.HealthCheckTimeout = Config.HealthTimeout
+
+	// block lifecycle settings
+	dbs.BlockCloseInterval = Config.BlockCloseInterval
+	dbs.BlockIdleHours = Config.BlockIdleHours
+	dbs.BlockMaxSize = Config.BlockMaxSize
+	dbs.BlockMaxFiles = Config.BlockMaxFiles
 
 	// DBS bulkblocks API
 	dbs.ConcurrentBulkBlocks = Config.ConcurrentBulkBlocks
@@ -511,36 +595,51 @@ func Server(configFile string) {
 		go dbMonitor(dbtype, dburi, Config.DBMonitoringInterval)
 	}
 
-	migDone := make(chan bool)
+	// stop notifications are buffered such that shutdown does not wait for
+	// daemons to finish their current work, it is handled by drain timeout
+	migDone := make(chan bool, 1)
+	blkDone := make(chan bool, 1)
 	//     clpDone := make(chan bool)
 	if Config.ServerType == "DBSMigration" {
 		go dbs.MigrationServer(dbs.MigrationServerInterval, dbs.MigrationProcessTimeout, migDone)
 		//go dbs.MigrationCleanupServer(dbs.MigrationCleanupInterval, dbs.MigrationCleanupOffset, clpDone)
 	}
+	if Config.ServerType == "DBSWriter" && dbs.BlockCloseInterval > 0 {
+		go dbs.BlockCloseServer(dbs.BlockCloseInterval, blkDone)
+	}
 
 	// properly stop our HTTP and Migration Servers
 	<-httpDone
-	log.Print("HTTP server stopped")
-
-	// close database connection pointer
-	if dbs.DB != nil {
-		dbs.DB.Close()
-	}
-
-	// close database connection pointer
-	if dbs.MigrationDB != nil {
-		dbs.MigrationDB.Close()
-	}
+	log.Print("HTTP server received shutdown signal")
 
+	// switch server into drain mode, i.e. stop accepting new writes and
+	// migrations, and stop our daemons from picking up new work
+	dbs.StartDrain()
 	// send notification to stop migration server
 	if Config.ServerType == "DBSMigration" {
 		migDone <- true
 	}
+	// send notification to stop block close server
+	if Config.ServerType == "DBSWriter" && dbs.BlockCloseInterval > 0 {
+		blkDone <- true
+	}
 	// send notification to stop cleanup migration server
 	//     if Config.ServerType == "DBSMigration" {
 	//         clpDone <- true
 	//     }
 
+	// wait for in-flight work and reset interrupted migration requests
+	// such that they will be cleanly retried
+	timeout := time.Duration(dbs.DrainTimeout) * time.Second
+	if dbs.WaitForDrain(timeout) {
+		log.Print("all in-flight requests are finished")
+	} else {
+		log.Printf("drain timeout %v is reached with %d in-flight requests", timeout, dbs.InFlight())
+		if err := dbs.InterruptMigrationRequests(); err != nil {
+			log.Println("unable to interrupt migration requests", err)
+		}
+	}
+
 	// add extra timeout for shutdown service stuff
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer func() {
@@ -550,5 +649,15 @@ func Server(configFile string) {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server Shutdown Failed:%+v", err)
 	}
+
+	// close database connection pointer
+	if dbs.DB != nil {
+		dbs.DB.Close()
+	}
+
+	// close database connection pointer
+	if dbs.MigrationDB != nil {
+		dbs.MigrationDB.Close()
+	}
 	log.Print("HTTP server exited properly")
 }
@@ -8,7 +8,10 @@ package web
 import (
 	"bytes"
 	"html/template"
+	"path"
 	"path/filepath"
+
+	"github.com/dmwm/dbs2go/utils"
 )
 
 // TmplRecord represent template record
@@ -49,7 +52,13 @@ func parseTmpl(tdir, tmpl string, data interface{}) string {
 			return false
 		},
 	}
-	t := template.Must(template.New(tmpl).Funcs(funcMap).ParseFiles(filenames...))
+	// templates are read from configured templates area or from DBS static files
+	var t *template.Template
+	if tdir != "" {
+		t = template.Must(template.New(tmpl).Funcs(funcMap).ParseFiles(filenames...))
+	} else {
+		t = template.Must(template.New(tmpl).Funcs(funcMap).ParseFS(utils.StaticFS(), path.Join("templates", tmpl)))
+	}
 	err := t.Execute(buf, data)
 	if err != nil {
 		panic(err)